@@ -0,0 +1,43 @@
+package gas
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff determines how long to wait before each retry. Use WithBackoff to configure a Client with a Backoff other
+// than the ExponentialBackoff of WithRetry. Implementations must be safe for concurrent use.
+type Backoff interface {
+	// NextDelay returns the delay before the retry that follows attempt, where attempt is 0 for the initial attempt.
+	NextDelay(attempt int) time.Duration
+}
+
+// BackoffFunc is an adapter to allow the use of ordinary functions as a Backoff.
+type BackoffFunc func(attempt int) time.Duration
+
+// NextDelay calls f(attempt).
+func (f BackoffFunc) NextDelay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// ExponentialBackoff returns a Backoff that waits initial before the first retry, doubling the wait for each
+// subsequent retry. It is the backoff used by WithRetry and the Retry middleware.
+func ExponentialBackoff(initial time.Duration) Backoff {
+	return BackoffFunc(func(attempt int) time.Duration {
+		if initial <= 0 {
+			return 0
+		}
+		// saturate rather than overflow for very late attempts
+		if initial > math.MaxInt64>>uint(attempt) {
+			return math.MaxInt64
+		}
+		return initial << uint(attempt)
+	})
+}
+
+// ConstantBackoff returns a Backoff that always waits delay between attempts.
+func ConstantBackoff(delay time.Duration) Backoff {
+	return BackoffFunc(func(int) time.Duration {
+		return delay
+	})
+}
@@ -0,0 +1,56 @@
+package gas
+
+import (
+	"math"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100 * time.Millisecond)
+
+	// 1. the delay doubles for each attempt
+	assert.Equal(t, 100*time.Millisecond, backoff.NextDelay(0))
+	assert.Equal(t, 200*time.Millisecond, backoff.NextDelay(1))
+	assert.Equal(t, 800*time.Millisecond, backoff.NextDelay(3))
+
+	// 2. late attempts saturate rather than overflow
+	assert.Equal(t, time.Duration(math.MaxInt64), backoff.NextDelay(40))
+	assert.Equal(t, time.Duration(math.MaxInt64), backoff.NextDelay(100))
+	assert.Zero(t, ExponentialBackoff(0).NextDelay(100))
+}
+
+func TestConstantBackoff(t *testing.T) {
+	backoff := ConstantBackoff(time.Second)
+	assert.Equal(t, time.Second, backoff.NextDelay(0))
+	assert.Equal(t, time.Second, backoff.NextDelay(10))
+}
+
+func TestWithBackoff(t *testing.T) {
+	// 1. the configured backoff determines the delay before each retry
+	var requests int32
+	var attempts []int
+	backoff := BackoffFunc(func(attempt int) time.Duration {
+		attempts = append(attempts, attempt)
+		return time.Millisecond
+	})
+	c, stop := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 3, &requests),
+		WithRetry(3, time.Hour),
+		WithBackoff(backoff),
+	)
+	defer stop()
+
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+	assert.Equal(t, []int{0, 1, 2}, attempts)
+
+	// 2. the backoff must be set
+	_, err = NewClient(WithBackoff(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"sync"
+)
+
+// blendWeightTolerance is how far the sum of the weights of NewBlendProvider may be from 1, to allow for the rounding
+// of weights such as 1/3
+const blendWeightTolerance = 1e-9
+
+// NewBlendProvider returns a Provider that blends the prices of providers, such as 60% of those of a trusted node and
+// 40% of those of a public API, where weights[i] is the weight of the i-th provider. Every provider is called
+// concurrently, and the price of each priority level is the average in wei of the prices reported for it, weighted by
+// the weights of the providers that reported one and rounded to the nearest wei.
+//
+// When some providers fail or don't report a level, the weights of the others are renormalized to sum to 1 over them,
+// so 60/40 weights with the second provider down yield the prices of the first. A level that no provider reports is
+// left without a price, and if every provider fails, the error of the first provider is returned. Only the prices of
+// the four priority levels are blended, other fields of the responses are left out.
+//
+// An error is returned if there are no providers, the weights don't match them, or they aren't positive and summing
+// to 1.
+func NewBlendProvider(providers []Provider, weights []float64) (Provider, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("eth: no providers to blend")
+	}
+	if len(weights) != len(providers) {
+		return nil, errors.New("eth: blend needs one weight per provider")
+	}
+	var sum float64
+	for i, weight := range weights {
+		if providers[i] == nil {
+			return nil, errors.New("eth: provider must not be nil")
+		}
+		if !(weight > 0) || math.IsInf(weight, 0) {
+			return nil, errors.New("eth: blend weights must be positive")
+		}
+		sum += weight
+	}
+	if math.Abs(sum-1) > blendWeightTolerance {
+		return nil, errors.New("eth: blend weights must sum to 1")
+	}
+
+	p := &blendProvider{providers: append([]Provider(nil), providers...), weights: make([]*big.Rat, len(weights))}
+	for i, weight := range weights {
+		p.weights[i] = new(big.Rat).SetFloat64(weight)
+	}
+	return p, nil
+}
+
+type blendProvider struct {
+	providers []Provider
+	weights   []*big.Rat
+}
+
+func (p *blendProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	responses := make([]GasPrices, len(p.providers))
+	errs := make([]error, len(p.providers))
+	var wg sync.WaitGroup
+	for i, provider := range p.providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			responses[i], errs[i] = provider.Fetch(ctx)
+		}(i, provider)
+	}
+	wg.Wait()
+	if !anySucceeded(errs) {
+		return GasPrices{}, errs[0]
+	}
+
+	var blended GasPrices
+	for _, priority := range priorityOrder {
+		sum, total := new(big.Rat), new(big.Rat)
+		for i, response := range responses {
+			price, err := response.price(priority)
+			if errs[i] != nil || err != nil {
+				continue
+			}
+			sum.Add(sum, new(big.Rat).Mul(new(big.Rat).SetInt(price), p.weights[i]))
+			total.Add(total, p.weights[i])
+		}
+		if total.Sign() == 0 {
+			continue
+		}
+		var err error
+		if blended, err = blended.withPrice(priority, roundRat(sum.Quo(sum, total))); err != nil {
+			return GasPrices{}, err
+		}
+	}
+	if blended.Fast == nil && blended.Fastest == nil && blended.SafeLow == nil && blended.Average == nil {
+		return GasPrices{}, errors.New("eth: no blended provider reported a gas price")
+	}
+	return blended, nil
+}
+
+func (p *blendProvider) CloseIdleConnections() {
+	for _, provider := range p.providers {
+		closeIdleConnections(provider)
+	}
+}
+
+// anySucceeded reports whether any of errs is nil
+func anySucceeded(errs []error) bool {
+	for _, err := range errs {
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// roundRat rounds a non-negative rational to the nearest integer, halves up
+func roundRat(x *big.Rat) *big.Int {
+	n := new(big.Int).Mul(x.Num(), big.NewInt(2))
+	n.Add(n, x.Denom())
+	return n.Quo(n, new(big.Int).Mul(x.Denom(), big.NewInt(2)))
+}
@@ -0,0 +1,60 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBlendProvider(t *testing.T) {
+	node := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(10e9), SafeLow: big.NewInt(5e9)}, nil
+	})
+	var fail bool
+	public := ProviderFunc(func(context.Context) (GasPrices, error) {
+		if fail {
+			return GasPrices{}, errors.New("unavailable")
+		}
+		return GasPrices{Fast: big.NewInt(20e9), Average: big.NewInt(15e9), SafeLow: big.NewInt(6e9)}, nil
+	})
+	provider, err := NewBlendProvider([]Provider{node, public}, []float64{0.6, 0.4})
+	require.NoError(t, err)
+
+	// 1. the price of each level is the weighted average of the reported prices
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "14000000000", prices.Fast.String())
+	assert.Equal(t, "5400000000", prices.SafeLow.String())
+
+	// 2. the weights are renormalized over the providers that reported a level
+	assert.Equal(t, "15000000000", prices.Average.String())
+	assert.Nil(t, prices.Fastest)
+	fail = true
+	prices, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "10000000000", prices.Fast.String())
+	assert.Nil(t, prices.Average)
+
+	// 3. the error of the first provider is returned if every provider fails
+	failing := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{}, ErrInjectedFault
+	})
+	provider, err = NewBlendProvider([]Provider{failing, public}, []float64{0.5, 0.5})
+	require.NoError(t, err)
+	_, err = provider.Fetch(context.Background())
+	assert.True(t, errors.Is(err, ErrInjectedFault))
+
+	// 4. the weights must be positive, one per provider, and sum to 1
+	for _, weights := range [][]float64{{0.6}, {0.6, 0.6}, {1.5, -0.5}, {1, 0}} {
+		_, err = NewBlendProvider([]Provider{node, public}, weights)
+		assert.Error(t, err, weights)
+	}
+	_, err = NewBlendProvider([]Provider{node, public, public}, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3})
+	assert.NoError(t, err)
+	_, err = NewBlendProvider(nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+)
+
+// BlocknativeURL is the Blocknative gas platform endpoint used by the BlocknativeProvider.
+const BlocknativeURL = "https://api.blocknative.com/gasprices/blockprices"
+
+// defaultBlocknativeConfidence maps each priority level to a Blocknative confidence level
+var defaultBlocknativeConfidence = map[GasPriority]int{
+	GasPrioritySafeLow: 70,
+	GasPriorityAverage: 80,
+	GasPriorityFast:    90,
+	GasPriorityFastest: 99,
+}
+
+// BlocknativeProvider is a Provider that loads prices from the Blocknative gas platform API, which requires an API
+// key. Each priority level is served from one of the confidence levels reported for the next block, and the EIP-1559
+// fees and base fee are populated along with the legacy gas prices.
+type BlocknativeProvider struct {
+	// APIKey is sent in the Authorization header of every request.
+	APIKey string
+
+	// Confidence maps priority levels to the Blocknative confidence level, in percent, they are served from. Priorities
+	// that are not included use the defaults of 70 for safeLow, 80 for average, 90 for fast and 99 for fastest.
+	Confidence map[GasPriority]int
+
+	// URL replaces BlocknativeURL, e.g. to use a proxy.
+	URL string
+
+	// HTTPClient is used to make requests, it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type blocknativeResponse struct {
+	BlockPrices []struct {
+		BaseFeePerGas   float64 `json:"baseFeePerGas"`
+		EstimatedPrices []struct {
+			Confidence           int     `json:"confidence"`
+			Price                float64 `json:"price"`
+			MaxPriorityFeePerGas float64 `json:"maxPriorityFeePerGas"`
+			MaxFeePerGas         float64 `json:"maxFeePerGas"`
+		} `json:"estimatedPrices"`
+	} `json:"blockPrices"`
+}
+
+// Fetch loads the latest prices from the Blocknative API.
+func (p *BlocknativeProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	endpoint := p.URL
+	if endpoint == "" {
+		endpoint = BlocknativeURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return GasPrices{}, err
+	}
+	req.Header.Set("Authorization", p.APIKey)
+
+	res, err := p.client().Do(req)
+	if err != nil {
+		return GasPrices{}, &FetchError{Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return GasPrices{}, &FetchError{StatusCode: res.StatusCode}
+	}
+
+	var response blocknativeResponse
+	if err := decodeResponse(res.Body, &response); err != nil {
+		return GasPrices{}, err
+	}
+	return p.newGasPrices(response)
+}
+
+// CloseIdleConnections closes idle connections of the underlying HTTP client.
+func (p *BlocknativeProvider) CloseIdleConnections() {
+	p.client().CloseIdleConnections()
+}
+
+func (p *BlocknativeProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
+
+// validateKey checks the key of the provider, which is required
+func (p *BlocknativeProvider) validateKey() error {
+	return ValidateKey(p.APIKey)
+}
+
+func (p *BlocknativeProvider) confidence(priority GasPriority) int {
+	if confidence, ok := p.Confidence[priority]; ok {
+		return confidence
+	}
+	return defaultBlocknativeConfidence[priority]
+}
+
+// newGasPrices converts the estimates for the next block, which is the first block in the response, to wei
+func (p *BlocknativeProvider) newGasPrices(response blocknativeResponse) (GasPrices, error) {
+	if len(response.BlockPrices) == 0 {
+		return GasPrices{}, errors.New("eth: no block prices in response")
+	}
+	block := response.BlockPrices[0]
+
+	baseFee, err := parseGweiToWei(block.BaseFeePerGas)
+	if err != nil {
+		return GasPrices{}, err
+	}
+	result := GasPrices{
+		BaseFee:    baseFee,
+		Confidence: make(map[GasPriority]float64, len(defaultBlocknativeConfidence)),
+		Fees:       make(map[GasPriority]FeeSuggestion, len(defaultBlocknativeConfidence)),
+	}
+
+	for priority := range defaultBlocknativeConfidence {
+		confidence := p.confidence(priority)
+
+		found := false
+		for _, estimate := range block.EstimatedPrices {
+			if estimate.Confidence != confidence {
+				continue
+			}
+			found = true
+
+			price, err := parseGweiToWei(estimate.Price)
+			if err != nil {
+				return GasPrices{}, err
+			}
+			maxFee, err := parseGweiToWei(estimate.MaxFeePerGas)
+			if err != nil {
+				return GasPrices{}, err
+			}
+			maxPriorityFee, err := parseGweiToWei(estimate.MaxPriorityFeePerGas)
+			if err != nil {
+				return GasPrices{}, err
+			}
+
+			switch priority {
+			case GasPriorityFast:
+				result.Fast = price
+			case GasPriorityFastest:
+				result.Fastest = price
+			case GasPrioritySafeLow:
+				result.SafeLow = price
+			case GasPriorityAverage:
+				result.Average = price
+			}
+			result.Confidence[priority] = float64(confidence) / 100
+			result.Fees[priority] = FeeSuggestion{MaxFeePerGas: maxFee, MaxPriorityFeePerGas: maxPriorityFee}
+			break
+		}
+		if !found {
+			return GasPrices{}, errors.New("eth: no estimate for confidence level in response")
+		}
+	}
+	return result, nil
+}
+
+// parseGweiToWei converts a price in gwei to wei, rounding to the nearest wei since prices may be reported with more
+// precision than a wei
+func parseGweiToWei(raw float64) (*big.Int, error) {
+	exact, err := parseExactGasPrice(raw)
+	if err != nil {
+		return nil, err
+	}
+	wei := exact.Mul(exact, gweiConversionFactor)
+	// add half a wei before truncating to round to nearest
+	wei.Add(wei, big.NewRat(1, 2))
+	return new(big.Int).Quo(wei.Num(), wei.Denom()), nil
+}
@@ -0,0 +1,114 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBlocknativeResponse = `{
+	"blockPrices": [{
+		"baseFeePerGas": 20.123456789123,
+		"estimatedPrices": [
+			{"confidence": 99, "price": 30, "maxPriorityFeePerGas": 3, "maxFeePerGas": 43.25},
+			{"confidence": 95, "price": 27, "maxPriorityFeePerGas": 2.5, "maxFeePerGas": 42.75},
+			{"confidence": 90, "price": 25, "maxPriorityFeePerGas": 2, "maxFeePerGas": 42.25},
+			{"confidence": 80, "price": 23, "maxPriorityFeePerGas": 1.5, "maxFeePerGas": 41.75},
+			{"confidence": 70, "price": 22, "maxPriorityFeePerGas": 1, "maxFeePerGas": 41.25}
+		]
+	}]
+}`
+
+// newTestBlocknativeProvider returns a provider that sends all requests to a test server using handler, and a
+// function that stops the server
+func newTestBlocknativeProvider(t *testing.T, handler http.HandlerFunc) (*BlocknativeProvider, func()) {
+	server := httptest.NewServer(handler)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	return &BlocknativeProvider{
+		APIKey:     "test-key",
+		HTTPClient: &http.Client{Transport: testTransport{server: serverURL}},
+	}, server.Close
+}
+
+func TestBlocknativeProvider(t *testing.T) {
+	provider, stop := newTestBlocknativeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(testBlocknativeResponse))
+	})
+	defer stop()
+
+	// 1. confidence levels are mapped onto priority levels
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "30000000000", prices.Fastest.String())
+	assert.Equal(t, "25000000000", prices.Fast.String())
+	assert.Equal(t, "23000000000", prices.Average.String())
+	assert.Equal(t, "22000000000", prices.SafeLow.String())
+	assert.Equal(t, 0.9, prices.PriceConfidence(GasPriorityFast))
+
+	// 2. EIP-1559 fees are populated, rounded to the nearest wei
+	assert.Equal(t, "20123456789", prices.BaseFee.String())
+	assert.Equal(t, "42250000000", prices.Fees[GasPriorityFast].MaxFeePerGas.String())
+	assert.Equal(t, "2000000000", prices.Fees[GasPriorityFast].MaxPriorityFeePerGas.String())
+
+	// 3. the mapping is configurable
+	provider.Confidence = map[GasPriority]int{GasPriorityFast: 95}
+	prices, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "27000000000", prices.Fast.String())
+
+	// 4. a missing confidence level fails the fetch
+	provider.Confidence = map[GasPriority]int{GasPriorityFast: 50}
+	_, err = provider.Fetch(context.Background())
+	assert.Error(t, err)
+
+	// 5. authentication failures are reported as fetch errors
+	provider.Confidence = nil
+	provider.APIKey = "wrong-key"
+	_, err = provider.Fetch(context.Background())
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusUnauthorized, fetchErr.StatusCode)
+
+	// 6. the URL can be replaced, such as by a proxy
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/proxy", r.URL.Path)
+		_, _ = w.Write([]byte(testBlocknativeResponse))
+	}))
+	defer server.Close()
+	prices, err = (&BlocknativeProvider{APIKey: "test-key", URL: server.URL + "/proxy"}).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "25000000000", prices.Fast.String())
+}
+
+func TestParseGweiToWei(t *testing.T) {
+	cases := []struct {
+		raw      float64
+		expected string
+	}{
+		{20, "20000000000"},
+		{0.5, "500000000"},
+		{1.0000000004, "1000000000"},
+		{1.0000000005, "1000000001"},
+	}
+	for _, c := range cases {
+		wei, err := parseGweiToWei(c.raw)
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, wei.String(), "%v gwei", c.raw)
+	}
+
+	_, err := parseGweiToWei(-1)
+	assert.Error(t, err)
+}
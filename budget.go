@@ -0,0 +1,65 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+// ErrOverBudget is returned by SuggestWithinBudget when even the cheapest priority level costs more than the budget.
+var ErrOverBudget = errors.New("eth: no gas price within budget")
+
+// SuggestWithinBudget returns the price of the preferred priority level if it costs at most maxWei, otherwise that of
+// the fastest cheaper level that does, for "pay as fast as I can afford" pricing. Levels without a price are skipped.
+// It returns ErrOverBudget if no level at or below the preferred one fits the budget.
+func (p GasPrices) SuggestWithinBudget(maxWei *big.Int, preferred GasPriority) (*big.Int, GasPriority, error) {
+	if maxWei == nil {
+		return nil, "", errors.New("eth: budget must be set")
+	}
+	levels, err := levelsAtOrBelow(preferred)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, priority := range levels {
+		price, err := p.price(priority)
+		if err != nil {
+			continue
+		}
+		if price.Cmp(maxWei) <= 0 {
+			return new(big.Int).Set(price), priority, nil
+		}
+	}
+	return nil, "", ErrOverBudget
+}
+
+// SuggestWithinBudget is like GasPrices.SuggestWithinBudget, using the prices of a single response. Unless the client
+// was configured with WithMaxResultAge, it always makes a new call to the provider.
+func (c *Client) SuggestWithinBudget(maxWei *big.Int, preferred GasPriority) (*big.Int, GasPriority, error) {
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return nil, "", err
+	}
+	return prices.SuggestWithinBudget(maxWei, preferred)
+}
+
+// levelsAtOrBelow returns preferred followed by the cheaper priority levels, from fastest to cheapest
+func levelsAtOrBelow(preferred GasPriority) ([]GasPriority, error) {
+	if preferred == GasPriorityTurbo {
+		return append([]GasPriority{GasPriorityTurbo}, levelsFrom(len(priorityOrder)-1)...), nil
+	}
+	for i, priority := range priorityOrder {
+		if priority == preferred {
+			return levelsFrom(i), nil
+		}
+	}
+	return nil, errors.New("eth: unknown/unsupported gas priority")
+}
+
+// levelsFrom returns the priority levels up to index in priorityOrder, from fastest to cheapest
+func levelsFrom(index int) []GasPriority {
+	levels := make([]GasPriority, 0, index+1)
+	for i := index; i >= 0; i-- {
+		levels = append(levels, priorityOrder[i])
+	}
+	return levels
+}
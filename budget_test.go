@@ -0,0 +1,56 @@
+package gas
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestWithinBudget(t *testing.T) {
+	prices := GasPrices{
+		SafeLow: big.NewInt(10),
+		Average: big.NewInt(15),
+		Fast:    big.NewInt(20),
+		Fastest: big.NewInt(25),
+	}
+
+	// 1. the preferred level is returned if it fits the budget
+	price, priority, err := prices.SuggestWithinBudget(big.NewInt(20), GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20", price.String())
+	assert.Equal(t, GasPriorityFast, priority)
+
+	// 2. otherwise the fastest cheaper level that fits is returned
+	price, priority, err = prices.SuggestWithinBudget(big.NewInt(19), GasPriorityTurbo)
+	require.NoError(t, err)
+	assert.Equal(t, "15", price.String())
+	assert.Equal(t, GasPriorityAverage, priority)
+
+	// 3. levels without a price are skipped
+	prices.Average = nil
+	_, priority, err = prices.SuggestWithinBudget(big.NewInt(19), GasPriorityFastest)
+	require.NoError(t, err)
+	assert.Equal(t, GasPrioritySafeLow, priority)
+
+	// 4. a budget below the cheapest level fails
+	_, _, err = prices.SuggestWithinBudget(big.NewInt(9), GasPriorityFastest)
+	assert.True(t, errors.Is(err, ErrOverBudget))
+
+	// 5. unknown priorities and missing budgets are rejected
+	_, _, err = prices.SuggestWithinBudget(big.NewInt(100), GasPriority("unknown"))
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrOverBudget))
+	_, _, err = prices.SuggestWithinBudget(nil, GasPriorityFast)
+	assert.Error(t, err)
+
+	// 6. the client applies the budget to a single response
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+	price, priority, err = c.SuggestWithinBudget(big.NewInt(18e9), GasPriorityFastest)
+	require.NoError(t, err)
+	assert.Equal(t, "15000000000", price.String())
+	assert.Equal(t, GasPriorityAverage, priority)
+}
@@ -0,0 +1,224 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a provider wrapped with CircuitBreaker while the circuit is open.
+var ErrCircuitOpen = errors.New("eth: circuit breaker is open")
+
+// Middleware wraps a Provider to add behavior such as retries or caching.
+type Middleware func(Provider) Provider
+
+// Chain wraps base with the given middleware. The first middleware is the outermost, so a call to the returned provider
+// passes through the middleware in the order given before reaching base. For example
+//
+//	Chain(base, Cache(time.Minute), Retry(3, time.Second, nil), CircuitBreaker(5, time.Minute))
+//
+// serves cached prices without reaching the other middleware, and otherwise retries calls that pass through the circuit
+// breaker.
+//
+// The returned provider closes idle connections of base when the client using it is closed.
+func Chain(base Provider, middleware ...Middleware) Provider {
+	provider := base
+	for i := len(middleware) - 1; i >= 0; i-- {
+		provider = middleware[i](provider)
+	}
+	return provider
+}
+
+// Retry returns middleware that retries transient failures up to retries times, doubling the backoff between attempts.
+// It is the middleware equivalent of the WithRetry and WithRetryableStatus options, and retryableStatus defaults to
+// retrying on 429 and 5xx status codes if nil.
+func Retry(retries int, backoff time.Duration, retryableStatus func(status int) bool) Middleware {
+	if retryableStatus == nil {
+		retryableStatus = defaultRetryableStatus
+	}
+	return func(next Provider) Provider {
+		return &retryProvider{
+			next:            next,
+			retries:         retries,
+			backoff:         ExponentialBackoff(backoff),
+			retryableStatus: retryableStatus,
+		}
+	}
+}
+
+type retryProvider struct {
+	next            Provider
+	retries         int
+	backoff         Backoff
+	retryableStatus func(int) bool
+}
+
+func (p *retryProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	return fetchWithRetries(ctx, p.next.Fetch, p.retries, p.backoff, p.retryableStatus)
+}
+
+func (p *retryProvider) CloseIdleConnections() {
+	closeIdleConnections(p.next)
+}
+
+// Validate returns middleware that rejects the prices of the wrapped provider for which validate returns an error,
+// such as ValidateGasPrices, returning the error in a *FetchError so it is retried by Retry and WithRetry, and falls
+// back on the next provider of NewFallbackProvider, like a failed request.
+func Validate(validate func(GasPrices) error) Middleware {
+	return func(next Provider) Provider {
+		return &validatingProvider{next: next, validate: validate}
+	}
+}
+
+type validatingProvider struct {
+	next     Provider
+	validate func(GasPrices) error
+}
+
+func (p *validatingProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	prices, err := p.next.Fetch(ctx)
+	if err != nil {
+		return prices, err
+	}
+	if err := p.validate(prices); err != nil {
+		return GasPrices{}, &FetchError{Err: err}
+	}
+	return prices, nil
+}
+
+func (p *validatingProvider) CloseIdleConnections() {
+	closeIdleConnections(p.next)
+}
+
+// CircuitBreaker returns middleware that stops calling the wrapped provider after failures consecutive failures, and
+// returns ErrCircuitOpen instead until cooldown has passed. The circuit is then half-open, and calls are passed through
+// as probes until one fails, which opens the circuit again, or enough succeed to close it. By default every call is a
+// probe and a single success closes the circuit, use WithProbeInterval and WithProbeSuccesses to change that.
+//
+// The circuit never opens if failures is not positive.
+func CircuitBreaker(failures int, cooldown time.Duration, opts ...CircuitBreakerOption) Middleware {
+	return func(next Provider) Provider {
+		p := &circuitBreakerProvider{
+			next:           next,
+			threshold:      failures,
+			cooldown:       cooldown,
+			probeSuccesses: 1,
+		}
+		for _, opt := range opts {
+			opt(p)
+		}
+		return p
+	}
+}
+
+// CircuitBreakerOption configures how a CircuitBreaker probes for recovery.
+type CircuitBreakerOption func(*circuitBreakerProvider)
+
+// WithProbeInterval limits a half-open circuit to one probe per interval, other calls return ErrCircuitOpen.
+func WithProbeInterval(interval time.Duration) CircuitBreakerOption {
+	return func(p *circuitBreakerProvider) {
+		p.probeInterval = interval
+	}
+}
+
+// WithProbeSuccesses sets the number of consecutive successful probes required to close a half-open circuit. Values
+// less than one are treated as one.
+func WithProbeSuccesses(successes int) CircuitBreakerOption {
+	return func(p *circuitBreakerProvider) {
+		if successes < 1 {
+			successes = 1
+		}
+		p.probeSuccesses = successes
+	}
+}
+
+type circuitBreakerProvider struct {
+	next           Provider
+	threshold      int
+	cooldown       time.Duration
+	probeInterval  time.Duration
+	probeSuccesses int
+
+	mu        sync.Mutex
+	failures  int
+	open      bool
+	openUntil time.Time
+	lastProbe time.Time
+	successes int
+}
+
+func (p *circuitBreakerProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	if !p.allow() {
+		return GasPrices{}, ErrCircuitOpen
+	}
+
+	prices, err := p.next.Fetch(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		p.failures = 0
+		if p.open {
+			p.successes++
+			if p.successes >= p.probeSuccesses {
+				p.open = false
+			}
+		}
+		return prices, nil
+	}
+
+	p.failures++
+	if p.open || (p.threshold > 0 && p.failures >= p.threshold) {
+		// a failed probe opens the circuit again for a full cooldown
+		p.open = true
+		p.openUntil = time.Now().Add(p.cooldown)
+		p.successes = 0
+	}
+	return prices, err
+}
+
+// allow reports whether a call may be passed through, which is always the case while the circuit is closed
+func (p *circuitBreakerProvider) allow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.open {
+		return true
+	}
+	now := time.Now()
+	if now.Before(p.openUntil) || now.Sub(p.lastProbe) < p.probeInterval {
+		return false
+	}
+	p.lastProbe = now
+	return true
+}
+
+func (p *circuitBreakerProvider) CloseIdleConnections() {
+	closeIdleConnections(p.next)
+}
+
+// Cache returns middleware that serves the prices loaded by the wrapped provider until they are older than maxAge. It
+// is the middleware equivalent of the WithMaxResultAge option. A maxAge that is not positive loads new prices on every
+// call.
+func Cache(maxAge time.Duration) Middleware {
+	return func(next Provider) Provider {
+		return &cacheProvider{
+			next:    next,
+			manager: &gasPriceManager{maxResultAge: maxAge, fetch: next.Fetch},
+		}
+	}
+}
+
+type cacheProvider struct {
+	next    Provider
+	manager *gasPriceManager
+}
+
+func (p *cacheProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	return p.manager.latest(ctx)
+}
+
+func (p *cacheProvider) CloseIdleConnections() {
+	closeIdleConnections(p.next)
+}
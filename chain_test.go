@@ -0,0 +1,181 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider returns an error for the first failures calls, and prices afterwards
+func countingProvider(failures int32, err error, calls *int32) Provider {
+	return ProviderFunc(func(context.Context) (GasPrices, error) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			return GasPrices{}, err
+		}
+		return GasPrices{Fast: big.NewInt(20000000000)}, nil
+	})
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next Provider) Provider {
+			return ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+				order = append(order, name)
+				return next.Fetch(ctx)
+			})
+		}
+	}
+	base := ProviderFunc(func(context.Context) (GasPrices, error) {
+		order = append(order, "base")
+		return GasPrices{}, nil
+	})
+
+	// 1. middleware is applied in the order given, outermost first
+	_, err := Chain(base, tag("first"), tag("second")).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "base"}, order)
+
+	// 2. without middleware the base provider is returned
+	assert.NotNil(t, Chain(base))
+}
+
+func TestRetry(t *testing.T) {
+	transient := &FetchError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("unavailable")}
+
+	// 1. transient failures are retried
+	var calls int32
+	provider := Chain(countingProvider(2, transient, &calls), Retry(2, time.Millisecond, nil))
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", prices.Fast.String())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// 2. the retryable status codes are configurable
+	calls = 0
+	provider = Chain(countingProvider(2, transient, &calls), Retry(2, time.Millisecond, RetryableStatusCodes(http.StatusTooManyRequests)))
+	_, err = provider.Fetch(context.Background())
+	assert.Equal(t, transient, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestValidate(t *testing.T) {
+	unordered := Chain(ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{SafeLow: big.NewInt(2), Fast: big.NewInt(1)}, nil
+	}), Validate(ValidateGasPrices))
+
+	// 1. rejected prices are returned as a fetch error
+	_, err := unordered.Fetch(context.Background())
+	var fetchErr *FetchError
+	assert.True(t, errors.As(err, &fetchErr))
+
+	// 2. so a fallback provider falls back on the next provider
+	var calls int32
+	prices, err := NewFallbackProvider([]Provider{unordered, countingProvider(0, nil, &calls)}).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", prices.Fast.String())
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	failure := errors.New("failure")
+	var calls int32
+	provider := Chain(countingProvider(2, failure, &calls), CircuitBreaker(2, 50*time.Millisecond))
+
+	// 1. the circuit opens after consecutive failures
+	for i := 0; i < 2; i++ {
+		_, err := provider.Fetch(context.Background())
+		assert.Equal(t, failure, err)
+	}
+	_, err := provider.Fetch(context.Background())
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 2. calls are passed through again after the cooldown
+	time.Sleep(60 * time.Millisecond)
+	_, err = provider.Fetch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestCache(t *testing.T) {
+	var calls int32
+	provider := Chain(countingProvider(0, nil, &calls), Cache(time.Minute))
+
+	// 1. prices are only fetched once within the max age
+	for i := 0; i < 3; i++ {
+		prices, err := provider.Fetch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "20000000000", prices.Fast.String())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 2. the chained provider can be used by a client
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCircuitBreakerProbes(t *testing.T) {
+	failure := errors.New("failure")
+	var calls int32
+	provider := Chain(
+		countingProvider(1, failure, &calls),
+		CircuitBreaker(1, 20*time.Millisecond, WithProbeInterval(20*time.Millisecond), WithProbeSuccesses(2)),
+	)
+
+	// 1. the circuit opens after the first failure
+	_, err := provider.Fetch(context.Background())
+	assert.Equal(t, failure, err)
+	_, err = provider.Fetch(context.Background())
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	// 2. once half-open, only one probe is made per interval
+	time.Sleep(25 * time.Millisecond)
+	_, err = provider.Fetch(context.Background())
+	assert.NoError(t, err)
+	_, err = provider.Fetch(context.Background())
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 3. the circuit closes after the configured number of successful probes
+	time.Sleep(25 * time.Millisecond)
+	_, err = provider.Fetch(context.Background())
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = provider.Fetch(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(6), atomic.LoadInt32(&calls))
+}
+
+func TestCircuitBreakerFailedProbe(t *testing.T) {
+	failure := errors.New("failure")
+	var calls int32
+	provider := Chain(countingProvider(2, failure, &calls), CircuitBreaker(1, 20*time.Millisecond))
+
+	// 1. a failed probe opens the circuit again
+	_, err := provider.Fetch(context.Background())
+	assert.Equal(t, failure, err)
+	time.Sleep(25 * time.Millisecond)
+	_, err = provider.Fetch(context.Background())
+	assert.Equal(t, failure, err)
+	_, err = provider.Fetch(context.Background())
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	// 2. and a successful probe after the cooldown closes it
+	time.Sleep(25 * time.Millisecond)
+	_, err = provider.Fetch(context.Background())
+	assert.NoError(t, err)
+	_, err = provider.Fetch(context.Background())
+	assert.NoError(t, err)
+}
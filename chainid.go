@@ -0,0 +1,160 @@
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Chain IDs of the chains that have a provider registered by default.
+const (
+	ChainIDMainnet uint64 = 1
+	ChainIDPolygon uint64 = 137
+)
+
+// ChainIDBSC is the chain ID of BNB Smart Chain, whose native token is registered by default.
+const ChainIDBSC uint64 = 56
+
+// ErrUnknownChain is returned when no provider, or no native token, is registered for a chain ID.
+var ErrUnknownChain = errors.New("eth: no provider registered for chain")
+
+var (
+	chainProvidersMu sync.RWMutex
+
+	// mainnetProvider is registered for mainnet by default, a client configured for mainnet uses its own default
+	// provider instead so the options that configure it apply
+	mainnetProvider = &ETHGasStationProvider{}
+
+	// chainProviders maps chain IDs to the provider used for the chain
+	chainProviders = map[uint64]Provider{
+		ChainIDMainnet: mainnetProvider,
+		ChainIDPolygon: &PolygonGasStationProvider{},
+	}
+
+	// nativeTokens maps chain IDs to the symbol of the native token gas is paid in
+	nativeTokens = map[uint64]string{
+		ChainIDMainnet: "ETH",
+		ChainIDPolygon: "MATIC",
+		ChainIDBSC:     "BNB",
+	}
+
+	// nativeTokenDecimals maps chain IDs to the decimals of their native token, if it doesn't have 18
+	nativeTokenDecimals = map[uint64]int{}
+)
+
+// DefaultNativeTokenDecimals is the number of decimals of ether, and of the native token of a chain that has no other
+// decimals registered.
+const DefaultNativeTokenDecimals = 18
+
+// maxNativeTokenDecimals bounds the decimals of a native token, well beyond any real token
+const maxNativeTokenDecimals = 36
+
+// RegisterChainProvider sets the provider used for chainID by ChainProvider and SuggestGasPriceForChain, replacing the
+// provider registered for the chain if there is one. By default, mainnet uses the ETHGasStationProvider and Polygon
+// uses the PolygonGasStationProvider.
+//
+// It is safe to call concurrently with other functions of the package.
+func RegisterChainProvider(chainID uint64, provider Provider) error {
+	if provider == nil {
+		return errors.New("eth: provider must not be nil")
+	}
+
+	chainProvidersMu.Lock()
+	defer chainProvidersMu.Unlock()
+	chainProviders[chainID] = provider
+	return nil
+}
+
+// ChainProvider returns the provider registered for chainID, to configure a Client for the chain with WithProvider. It
+// returns an error matching ErrUnknownChain if no provider is registered for the chain.
+func ChainProvider(chainID uint64) (Provider, error) {
+	chainProvidersMu.RLock()
+	defer chainProvidersMu.RUnlock()
+
+	provider, ok := chainProviders[chainID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownChain, chainID)
+	}
+	return provider, nil
+}
+
+// SuggestGasPriceForChain returns a suggested gas price in wei for the chain with chainID, loaded from the provider
+// registered for the chain. It always makes a new call to the provider. Use ChainProvider to configure a Client for the
+// chain that caches results.
+func SuggestGasPriceForChain(chainID uint64, priority GasPriority) (*big.Int, error) {
+	provider, err := ChainProvider(chainID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(WithProvider(provider))
+	if err != nil {
+		return nil, err
+	}
+	return c.SuggestGasPrice(priority)
+}
+
+// RegisterNativeToken sets the symbol of the native token gas is paid in on chainID, as returned by NativeToken, such
+// as "ETH" for mainnet. By default, mainnet, Polygon and BNB Smart Chain are registered.
+//
+// It is safe to call concurrently with other functions of the package.
+func RegisterNativeToken(chainID uint64, symbol string) error {
+	if symbol == "" {
+		return errors.New("eth: native token symbol must not be empty")
+	}
+
+	chainProvidersMu.Lock()
+	defer chainProvidersMu.Unlock()
+	nativeTokens[chainID] = symbol
+	return nil
+}
+
+// RegisterNativeTokenDecimals sets the number of decimals of the native token of chainID, as returned by
+// NativeTokenDecimals, for chains whose native token doesn't have the 18 decimals of ether. Prices on the chain are
+// then taken to be in the smallest unit of the token, and a client configured for it with WithChainID converts them to
+// gwei and whole tokens accordingly. decimals must be between 0 and 36.
+//
+// It is safe to call concurrently with other functions of the package.
+func RegisterNativeTokenDecimals(chainID uint64, decimals int) error {
+	if err := validateTokenDecimals(decimals); err != nil {
+		return err
+	}
+
+	chainProvidersMu.Lock()
+	defer chainProvidersMu.Unlock()
+	nativeTokenDecimals[chainID] = decimals
+	return nil
+}
+
+// NativeTokenDecimals returns the number of decimals of the native token of chainID, DefaultNativeTokenDecimals unless
+// other decimals were registered for the chain with RegisterNativeTokenDecimals.
+func NativeTokenDecimals(chainID uint64) int {
+	chainProvidersMu.RLock()
+	defer chainProvidersMu.RUnlock()
+
+	decimals, ok := nativeTokenDecimals[chainID]
+	if !ok {
+		return DefaultNativeTokenDecimals
+	}
+	return decimals
+}
+
+func validateTokenDecimals(decimals int) error {
+	if decimals < 0 || decimals > maxNativeTokenDecimals {
+		return fmt.Errorf("eth: native token decimals must be between 0 and %d", maxNativeTokenDecimals)
+	}
+	return nil
+}
+
+// NativeToken returns the symbol of the native token gas is paid in on chainID. It returns an error matching
+// ErrUnknownChain if no native token is registered for the chain.
+func NativeToken(chainID uint64) (string, error) {
+	chainProvidersMu.RLock()
+	defer chainProvidersMu.RUnlock()
+
+	symbol, ok := nativeTokens[chainID]
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrUnknownChain, chainID)
+	}
+	return symbol, nil
+}
@@ -0,0 +1,115 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainProvider(t *testing.T) {
+	// 1. mainnet and Polygon have default providers
+	provider, err := ChainProvider(ChainIDMainnet)
+	require.NoError(t, err)
+	assert.IsType(t, &ETHGasStationProvider{}, provider)
+	provider, err = ChainProvider(ChainIDPolygon)
+	require.NoError(t, err)
+	assert.IsType(t, &PolygonGasStationProvider{}, provider)
+
+	// 2. unknown chains are an error
+	_, err = ChainProvider(5)
+	assert.True(t, errors.Is(err, ErrUnknownChain))
+	_, err = SuggestGasPriceForChain(5, GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrUnknownChain))
+}
+
+func TestRegisterChainProvider(t *testing.T) {
+	const chainID = 31337
+	defer func() {
+		chainProvidersMu.Lock()
+		delete(chainProviders, chainID)
+		chainProvidersMu.Unlock()
+	}()
+
+	// 1. a registered provider is used for its chain
+	var calls int32
+	require.NoError(t, RegisterChainProvider(chainID, countingProvider(0, nil, &calls)))
+	price, err := SuggestGasPriceForChain(chainID, GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, int32(1), calls)
+
+	// 2. registering again replaces the provider
+	failing := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{}, errors.New("unavailable")
+	})
+	require.NoError(t, RegisterChainProvider(chainID, failing))
+	_, err = SuggestGasPriceForChain(chainID, GasPriorityFast)
+	assert.Error(t, err)
+
+	// 3. the provider must be set
+	assert.Error(t, RegisterChainProvider(chainID, nil))
+}
+
+func TestNativeTokenDecimals(t *testing.T) {
+	const chainID = 31338
+	defer func() {
+		chainProvidersMu.Lock()
+		delete(chainProviders, chainID)
+		delete(nativeTokens, chainID)
+		delete(nativeTokenDecimals, chainID)
+		chainProvidersMu.Unlock()
+	}()
+	var calls int32
+	require.NoError(t, RegisterChainProvider(chainID, countingProvider(0, nil, &calls)))
+	require.NoError(t, RegisterNativeToken(chainID, "TKN"))
+
+	// 1. native tokens have 18 decimals unless registered otherwise
+	assert.Equal(t, 18, NativeTokenDecimals(ChainIDPolygon))
+	assert.Equal(t, 18, NativeTokenDecimals(chainID))
+	require.NoError(t, RegisterNativeTokenDecimals(chainID, 6))
+	assert.Equal(t, 6, NativeTokenDecimals(chainID))
+
+	// 2. a client for the chain converts prices in the smallest unit with the decimals of the chain
+	c, err := NewClient(WithChainID(chainID))
+	require.NoError(t, err)
+	assert.Equal(t, 6, c.NativeTokenDecimals())
+	gwei, err := c.SuggestGasPriceGweiString(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000000", gwei)
+	tokens, err := c.SuggestGasPriceEtherString(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000", tokens)
+	rat, err := c.SuggestGasPriceRat(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000000/1", rat.String())
+	cost, err := c.EstimateCostUSD(21000, GasPriorityFast, TokenRate{Symbol: "TKN", USD: big.NewRat(1, 1000)})
+	require.NoError(t, err)
+	assert.Equal(t, "420000/1", cost.String())
+
+	// 3. the decimals of the client take precedence over those of the chain
+	c, err = NewClient(WithChainID(chainID), WithNativeTokenDecimals(12))
+	require.NoError(t, err)
+	gwei, err = c.SuggestGasPriceGweiString(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000", gwei)
+	tokens, err = c.SuggestGasPriceEtherString(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "0.02", tokens)
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(20e9), SafeLow: big.NewInt(10e9)}, nil
+	})
+	c, err = NewClient(WithProvider(provider), WithNativeTokenDecimals(12))
+	require.NoError(t, err)
+	savings, err := c.EstimateSavings(GasPriorityFast, GasPrioritySafeLow, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "20/1", savings.Fiat(big.NewRat(2, 1)).String())
+
+	// 4. decimals must be within range
+	assert.Error(t, RegisterNativeTokenDecimals(chainID, -1))
+	_, err = NewClient(WithNativeTokenDecimals(37))
+	assert.Error(t, err)
+}
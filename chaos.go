@@ -0,0 +1,119 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is the error of the failures injected by FaultInjection, unless configured otherwise with
+// WithInjectedError. It is returned wrapped in a *FetchError, so it is retried like a transient failure.
+var ErrInjectedFault = errors.New("eth: injected fault")
+
+// FaultInjection returns middleware that adds latency and fails a fraction of calls to the wrapped provider, for
+// exercising retries, fallbacks and stale price handling in integration tests and chaos experiments without a flaky
+// provider. By default it passes calls through unchanged, use WithInjectedLatency, WithErrorRate and WithInjectedError
+// to configure the faults.
+//
+// The latency is added before every call, and a call whose context is done first fails with its error. A failing call
+// doesn't reach the wrapped provider.
+func FaultInjection(opts ...FaultOption) Middleware {
+	return func(next Provider) Provider {
+		p := &faultProvider{next: next, err: &FetchError{Err: ErrInjectedFault}}
+		for _, opt := range opts {
+			opt(p)
+		}
+		if p.rand == nil {
+			p.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		return p
+	}
+}
+
+// FaultOption configures the faults injected by FaultInjection.
+type FaultOption func(*faultProvider)
+
+// WithInjectedLatency delays each call by delay plus a random duration of up to jitter.
+func WithInjectedLatency(delay, jitter time.Duration) FaultOption {
+	return func(p *faultProvider) {
+		p.delay = delay
+		p.jitter = jitter
+	}
+}
+
+// WithErrorRate fails each call with probability rate, between 0 and 1. Values outside the range are clamped to it.
+func WithErrorRate(rate float64) FaultOption {
+	return func(p *faultProvider) {
+		switch {
+		case !(rate > 0):
+			rate = 0
+		case rate > 1:
+			rate = 1
+		}
+		p.errorRate = rate
+	}
+}
+
+// WithInjectedError replaces the error returned by failing calls, such as a *FetchError with a 429 status code to
+// exercise rate limit handling. A nil error is ignored.
+func WithInjectedError(err error) FaultOption {
+	return func(p *faultProvider) {
+		if err != nil {
+			p.err = err
+		}
+	}
+}
+
+// WithFaultRand replaces the source of the latency jitter and failures, for a reproducible sequence of faults.
+func WithFaultRand(r *rand.Rand) FaultOption {
+	return func(p *faultProvider) {
+		p.rand = r
+	}
+}
+
+type faultProvider struct {
+	next      Provider
+	delay     time.Duration
+	jitter    time.Duration
+	errorRate float64
+	err       error
+
+	// mu guards rand, which isn't safe for concurrent use
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func (p *faultProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	delay, fail := p.draw()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return GasPrices{}, &FetchError{Err: ctx.Err()}
+		}
+	}
+	if fail {
+		return GasPrices{}, p.err
+	}
+	return p.next.Fetch(ctx)
+}
+
+// draw returns the latency of a call and whether it fails
+func (p *faultProvider) draw() (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delay := p.delay
+	if p.jitter > 0 {
+		delay += time.Duration(p.rand.Int63n(int64(p.jitter)))
+	}
+	return delay, p.errorRate > 0 && p.rand.Float64() < p.errorRate
+}
+
+func (p *faultProvider) CloseIdleConnections() {
+	closeIdleConnections(p.next)
+}
@@ -0,0 +1,66 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultInjection(t *testing.T) {
+	var calls int32
+	base := countingProvider(0, nil, &calls)
+
+	// 1. calls are passed through unchanged by default
+	prices, err := Chain(base, FaultInjection()).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", prices.Fast.String())
+
+	// 2. failing calls return a retryable injected fault without reaching the provider
+	calls = 0
+	_, err = Chain(base, FaultInjection(WithErrorRate(2))).Fetch(context.Background())
+	assert.True(t, errors.Is(err, ErrInjectedFault))
+	var fetchErr *FetchError
+	assert.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, int32(0), calls)
+
+	// 3. a fraction of calls fail, reproducibly with a seeded source
+	faulty := Chain(base, FaultInjection(WithErrorRate(0.5), WithFaultRand(rand.New(rand.NewSource(1)))))
+	failures := 0
+	for i := 0; i < 200; i++ {
+		if _, err := faulty.Fetch(context.Background()); err != nil {
+			failures++
+		}
+	}
+	assert.Greater(t, failures, 60)
+	assert.Less(t, failures, 140)
+
+	// 4. the retry middleware retries injected faults
+	retried := Chain(base, Retry(10, time.Millisecond, nil), FaultInjection(WithErrorRate(0.5),
+		WithFaultRand(rand.New(rand.NewSource(1)))))
+	for i := 0; i < 10; i++ {
+		_, err = retried.Fetch(context.Background())
+		assert.NoError(t, err)
+	}
+
+	// 5. latency is added to calls, and bounded by their context
+	slow := Chain(base, FaultInjection(WithInjectedLatency(20*time.Millisecond, 10*time.Millisecond)))
+	start := time.Now()
+	_, err = slow.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = slow.Fetch(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// 6. the injected error can be replaced
+	rateLimited := &FetchError{StatusCode: http.StatusTooManyRequests}
+	_, err = Chain(base, FaultInjection(WithErrorRate(1), WithInjectedError(rateLimited))).Fetch(context.Background())
+	assert.Equal(t, rateLimited, err)
+}
@@ -0,0 +1,1149 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClientClosed is returned by calls on a Client that has been closed, including calls that were in flight when
+// Close was called.
+var ErrClientClosed = errors.New("eth: client is closed")
+
+// ErrValueTooOld is returned by reads of a client configured with WithMaxServeAge that would otherwise serve prices
+// older than the max serve age.
+var ErrValueTooOld = errors.New("eth: prices are older than the max serve age")
+
+// Client is a configurable gas price client, which loads prices from the ETH Gas Station API unless configured with
+// another Provider.
+//
+// The package-level functions use a Client with the default configuration. Use NewClient with one or more options to
+// customize how prices are loaded and converted. A Client is safe for concurrent use.
+type Client struct {
+	// name identifies the client in errors, it is empty unless configured with WithName
+	name string
+
+	// provider defaults to an ETHGasStationProvider configured with the client's options, it is guarded by configMu
+	// once the client is in use since it can be replaced with SetProvider
+	provider   Provider
+	inputScale InputScale
+	transform  func(GasPrices) GasPrices
+
+	// chainID is the chain the client was configured for with WithChainID, nativeToken the symbol of its native token
+	chainID     uint64
+	nativeToken string
+
+	// tokenDecimals is the number of decimals of the native token set with WithNativeTokenDecimals, or registered for
+	// the chain if it is nil
+	tokenDecimals *int
+
+	// roundTo is the grid in wei that prices are rounded to, prices are not rounded if it is nil
+	roundTo      *big.Int
+	roundingMode RoundingMode
+
+	// rejectZero rejects responses with a zero price for any priority level
+	rejectZero bool
+
+	// rejectInvertedWaits rejects responses whose wait times aren't ordered by priority
+	rejectInvertedWaits bool
+
+	// retryInvalid validates each attempt, so rejected responses are retried like failed requests
+	retryInvalid bool
+
+	// plausibleMin and plausibleMax are the plausible range of prices in wei, they are only set if the client was
+	// configured with WithUnitCheck or WithRejectImplausiblePrices
+	plausibleMin      *big.Int
+	plausibleMax      *big.Int
+	plausibleRange    []float64
+	unitWarning       func(error)
+	rejectImplausible bool
+
+	// freeFallback retries requests whose key is rejected against the free endpoint
+	freeFallback bool
+
+	// warn logs warnings about the configuration of the client, it is only set if the client was configured with
+	// WithWarningLogger
+	warn func(msg string)
+
+	// confirmationModel replaces the wait times reported by the provider, if set
+	confirmationModel ConfirmationModel
+
+	// maxPriceChange is the percentage a price may move between refreshes, baseline holds the last accepted prices
+	maxPriceChange *big.Rat
+	baselineMu     sync.Mutex
+	baseline       GasPrices
+	hasBaseline    bool
+
+	// percentiles overrides the canonical percentile of priority levels
+	percentiles map[GasPriority]float64
+
+	// defaultPriority is the priority used by Suggest, it defaults to GasPriorityFast
+	defaultPriority GasPriority
+
+	// dedup is only set if the client was configured with WithFetchDedupWindow
+	dedup *fetchGroup
+
+	// errCache is only set if the client was configured with WithErrorCache
+	errCache *errorCache
+
+	// processCacheAge is the max age of the prices the client shares in the process cache, it is only set if the
+	// client was configured with WithProcessCache
+	processCacheAge time.Duration
+
+	// cache is only set if the client was configured with WithMaxResultAge
+	cache        *gasPriceManager
+	maxStaleness time.Duration
+	asyncRefresh bool
+	maxServeAge  time.Duration
+	ttlJitter    float64
+	blockTimeCap bool
+
+	// stateSerializer encodes the state of ExportState and ImportState, it defaults to JSONSerializer
+	stateSerializer Serializer
+
+	// resultComparator replaces the comparison of refreshed prices with the cached prices
+	resultComparator func(previous, next GasPrices) bool
+
+	// lastResort computes prices when they can't be loaded otherwise, if set
+	lastResort func(ctx context.Context) (GasPrices, error)
+
+	// now is the clock of the caches, it defaults to time.Now
+	now func() time.Time
+
+	// priorityCache is only set if the client was configured with WithPerPriorityCache
+	priorityCache *priorityCache
+	perPriority   bool
+
+	// percentileCache caches SuggestGasPriceAtPercentile, it is set if the client was configured with WithMaxResultAge
+	percentileCache *priorityCache
+
+	// httpClient, url, charsetReader, envelopePath, fieldMapping and apiKey configure the default provider, the HTTP
+	// client defaults to http.DefaultClient
+	httpClient    *http.Client
+	url           string
+	charsetReader func(string, io.Reader) (io.Reader, error)
+	envelopePath  string
+	fieldMapping  map[GasPriority]string
+
+	// schemaDrift, strictSchema and allowedFields are set if the client was configured with WithSchemaDriftHandler,
+	// WithStrictSchema and WithAllowedFields
+	schemaDrift   func(SchemaDrift)
+	strictSchema  bool
+	allowedFields []string
+
+	// requireTLS is set with WithRequireTLS, if it is nil TLS is required once the default provider sends a key
+	requireTLS *bool
+
+	// keys is only set if the client was configured with WithKeys, it replaces apiKey
+	keys        *keyPool
+	keyCooldown time.Duration
+
+	// localAddr and ipv4Only configure the dialer of the HTTP client built by NewClient
+	localAddr net.IP
+	ipv4Only  bool
+
+	// systemProxy and disableKeepAlives are set if the client was configured with WithSystemProxy and
+	// WithDisableKeepAlives
+	systemProxy       bool
+	disableKeepAlives bool
+
+	// requestLog is only set if the client was configured with WithRequestLogger, it wraps the transport of httpClient
+	requestLog func(ctx context.Context, method, redactedURL string)
+
+	// responseHook is only set if the client was configured with WithResponseHook, it wraps the transport of httpClient
+	responseHook func(*http.Response)
+
+	// requestIDs generates the request ID of each fetch, it defaults to a counter
+	requestIDs func() string
+
+	// configMu guards the fields below, which can be changed while the client is in use
+	configMu sync.RWMutex
+	apiKey   string
+	timeout  time.Duration
+
+	// swapGrace is set if the client was configured with WithProviderSwapGrace, and swap describes the last call to
+	// SetProvider within its grace period
+	swapGrace time.Duration
+	swap      providerSwap
+
+	// refreshTimeout replaces timeout for background refreshes, if set
+	refreshTimeout time.Duration
+
+	// startupCheck is set if the client was configured with WithStartupHealthCheck
+	startupCheck   bool
+	startupTimeout time.Duration
+
+	retries         int
+	retryBackoff    time.Duration
+	backoff         Backoff
+	maxRetryElapsed time.Duration
+	quota           *QuotaTracker
+	trace           *httptrace.ClientTrace
+	retryableStatus func(int) bool
+	failFast        bool
+	errorTiming     bool
+
+	// fetchSlots is a semaphore of the requests that may be in flight at once, it is only set if the client was
+	// configured with WithMaxConcurrentFetches
+	fetchSlots chan struct{}
+
+	// lastFetch describes the most recent successful fetch, it is guarded by lastFetchMu
+	lastFetchMu sync.Mutex
+	lastFetch   FetchInfo
+
+	// events is only set if the client was configured with WithEvents, eventsMu guards sending on it and closing it
+	events       chan Event
+	eventsMu     sync.Mutex
+	eventsClosed bool
+
+	// mu guards the lifecycle fields below, done is closed when the client is closed
+	mu       sync.Mutex
+	closed   bool
+	done     chan struct{}
+	inFlight sync.WaitGroup
+}
+
+// NewClient returns a new Client configured with the provided options.
+//
+// An error is returned if any of the options are invalid.
+func NewClient(opts ...Option) (*Client, error) {
+	c := new(Client)
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.requestIDs == nil {
+		c.requestIDs = newRequestIDCounter()
+	}
+	if c.chainID != 0 {
+		if err := c.configureChain(); err != nil {
+			return nil, err
+		}
+	}
+	if c.asyncRefresh {
+		if c.maxStaleness != 0 {
+			return nil, errors.New("eth: async refresh can't be combined with a max staleness")
+		}
+		// stale prices are served regardless of their age
+		c.maxStaleness = math.MaxInt64
+	}
+	if c.maxStaleness != 0 {
+		if c.cache == nil {
+			return nil, errors.New("eth: max staleness requires caching")
+		}
+		if c.maxStaleness < c.cache.maxResultAge {
+			return nil, errors.New("eth: max staleness must not be less than the max result age")
+		}
+	}
+	if c.maxServeAge != 0 && c.cache != nil && c.maxServeAge < c.cache.maxResultAge {
+		return nil, errors.New("eth: max serve age must not be less than the max result age")
+	}
+	if c.ttlJitter != 0 {
+		if c.cache == nil {
+			return nil, errors.New("eth: result ttl jitter requires caching")
+		}
+		// each client draws its own jitter, so clients created at the same moment still expire at different times
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		c.cache.maxResultAge = jitter(c.cache.maxResultAge, c.ttlJitter, rng)
+		if c.maxStaleness != 0 && c.cache.maxResultAge > c.maxStaleness {
+			c.cache.maxResultAge = c.maxStaleness
+		}
+		if c.maxServeAge != 0 && c.cache.maxResultAge > c.maxServeAge {
+			c.cache.maxResultAge = c.maxServeAge
+		}
+	}
+	if c.resultComparator != nil && c.cache == nil {
+		return nil, errors.New("eth: result comparator requires caching")
+	}
+	if c.blockTimeCap {
+		if c.cache == nil {
+			return nil, errors.New("eth: block time cap requires caching")
+		}
+		c.cache.capToBlockTime = true
+	}
+	if c.unitWarning != nil || c.rejectImplausible {
+		var minGwei, maxGwei float64 = DefaultPlausibleMinGwei, DefaultPlausibleMaxGwei
+		if c.plausibleRange != nil {
+			minGwei, maxGwei = c.plausibleRange[0], c.plausibleRange[1]
+		}
+		c.plausibleMin, _ = parseGweiToWei(minGwei)
+		c.plausibleMax, _ = parseGweiToWei(maxGwei)
+	} else if c.plausibleRange != nil {
+		return nil, errors.New("eth: plausible range requires a unit check or rejecting implausible prices")
+	}
+	if c.perPriority {
+		if c.cache == nil {
+			return nil, errors.New("eth: per-priority caching requires caching")
+		}
+		c.priorityCache = &priorityCache{maxResultAge: c.cache.maxResultAge, now: c.now}
+	}
+	if c.keyCooldown != 0 {
+		if c.keys == nil {
+			return nil, errors.New("eth: key cooldown requires a key pool")
+		}
+		c.keys.cooldown = c.keyCooldown
+	}
+	if err := c.checkTLS(c.apiKey != "" || c.keys != nil); err != nil {
+		return nil, err
+	}
+	if c.keys != nil && c.apiKey != "" {
+		return nil, errors.New("eth: a key pool can't be combined with an api key")
+	}
+	if c.freeFallback && c.provider != nil {
+		return nil, errors.New("eth: free endpoint fallback requires the default provider")
+	}
+	if c.localAddr != nil || c.ipv4Only {
+		if c.httpClient != nil {
+			return nil, errors.New("eth: local address and ipv4 options can't be combined with a custom http client")
+		}
+		c.httpClient = &http.Client{Transport: dialTransport(c.localAddr, c.ipv4Only)}
+	}
+	if c.systemProxy || c.disableKeepAlives {
+		httpClient, err := withTransport(c.httpClient, func(transport *http.Transport) {
+			if c.systemProxy {
+				transport.Proxy = http.ProxyFromEnvironment
+			}
+			if c.disableKeepAlives {
+				transport.DisableKeepAlives = true
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.httpClient = httpClient
+	}
+	if c.requestLog != nil {
+		var httpClient http.Client
+		if c.httpClient != nil {
+			httpClient = *c.httpClient
+		}
+		httpClient.Transport = LogRequestsContext(httpClient.Transport, c.requestLog)
+		c.httpClient = &httpClient
+	}
+	if c.responseHook != nil {
+		var httpClient http.Client
+		if c.httpClient != nil {
+			httpClient = *c.httpClient
+		}
+		httpClient.Transport = HookResponses(httpClient.Transport, c.responseHook)
+		c.httpClient = &httpClient
+	}
+	if validator, ok := c.source().(keyValidator); ok {
+		// report a malformed key now, rather than as a rejected request on first use
+		if err := validator.validateKey(); err != nil {
+			return nil, err
+		}
+	}
+	if validator, ok := c.source().(configValidator); ok {
+		if err := validator.validateConfig(); err != nil {
+			return nil, err
+		}
+	}
+	if c.cache != nil {
+		c.cache.fetch = c.fetch
+		c.cache.maxStaleness = c.maxStaleness
+		c.cache.maxServeAge = c.maxServeAge
+		c.cache.now = c.now
+		c.cache.equal = c.resultComparator
+		if c.events != nil {
+			c.cache.emit = c.emit
+		}
+		c.percentileCache = &priorityCache{maxResultAge: c.cache.maxResultAge, now: c.now}
+	}
+	if c.warn != nil && c.usesFreeEndpoint() && atomic.CompareAndSwapInt32(&freeEndpointWarned, 0, 1) {
+		c.warn(freeEndpointWarning)
+	}
+	if c.startupCheck {
+		if err := c.startupHealthCheck(); err != nil {
+			closeIdleConnections(c.source())
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// freeEndpointWarning is logged by the first client configured with WithWarningLogger that uses the free endpoint,
+// freeEndpointWarned is set once it has been logged
+const freeEndpointWarning = "eth: the free ETH Gas Station endpoint is deprecated and unreliable, configure a " +
+	"maintained provider with WithProvider or an API key with WithAPIKey"
+
+var freeEndpointWarned int32
+
+// usesFreeEndpoint reports whether the client loads prices from the free ETH Gas Station endpoint, because it has
+// neither a provider, a URL nor a key
+func (c *Client) usesFreeEndpoint() bool {
+	if c.provider != nil || c.url != "" || c.apiKey != "" || c.keys != nil {
+		return false
+	}
+	_, keyed := globalKey()
+	return !keyed
+}
+
+// dialTransport returns a transport with the settings of http.DefaultTransport, whose connections originate from
+// localAddr if it is set and only use IPv4 if ipv4Only is set
+func dialTransport(localAddr net.IP, ipv4Only bool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if localAddr != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: localAddr}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if ipv4Only {
+			network = "tcp4"
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return transport
+}
+
+// withTransport returns a copy of httpClient whose transport is a clone of its transport, or of http.DefaultTransport
+// if it has none, changed by configure
+func withTransport(httpClient *http.Client, configure func(*http.Transport)) (*http.Client, error) {
+	var configured http.Client
+	if httpClient != nil {
+		configured = *httpClient
+	}
+	transport := configured.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	httpTransport, ok := transport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("eth: transport options require the http client to use an *http.Transport")
+	}
+	httpTransport = httpTransport.Clone()
+	configure(httpTransport)
+	configured.Transport = httpTransport
+	return &configured, nil
+}
+
+// jitter scales d by a random factor between 1-fraction and 1+fraction
+func jitter(d time.Duration, fraction float64, rng *rand.Rand) time.Duration {
+	return time.Duration(float64(d) * (1 + fraction*(2*rng.Float64()-1)))
+}
+
+// Name returns the name the client was configured with using WithName, or an empty string.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// SuggestGasPrice returns a suggested gas price value in wei (base units) for timely transaction execution. Unless the
+// client was configured with WithMaxResultAge, it always makes a new call to the ETH Gas Station API.
+//
+// The returned price depends on the priority specified, and supports all priorities supported by the ETH Gas Station API.
+func (c *Client) SuggestGasPrice(priority GasPriority) (*big.Int, error) {
+	return c.SuggestGasPriceContext(context.Background(), priority)
+}
+
+// SuggestGasPriceInto is like SuggestGasPrice, but writes the price to dst instead of allocating a new value, so hot
+// paths can reuse a scratch value. dst is overwritten, and left unchanged if an error is returned. When the price is
+// served from the cache of a client configured with WithMaxResultAge, it doesn't allocate.
+func (c *Client) SuggestGasPriceInto(dst *big.Int, priority GasPriority) error {
+	if dst == nil {
+		return errors.New("eth: destination must not be nil")
+	}
+	if c.priorityCache != nil {
+		// levels cached individually are copied like by any other call
+		price, err := c.SuggestGasPrice(priority)
+		if err != nil {
+			return err
+		}
+		dst.Set(price)
+		return nil
+	}
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return err
+	}
+	return prices.priceInto(dst, priority)
+}
+
+// Suggest returns a suggested gas price in wei for the priority configured with WithDefaultPriority, or for
+// GasPriorityFast if none was configured.
+func (c *Client) Suggest() (*big.Int, error) {
+	return c.SuggestGasPrice(c.DefaultPriority())
+}
+
+// DefaultPriority returns the priority used by Suggest.
+func (c *Client) DefaultPriority() GasPriority {
+	if c.defaultPriority == "" {
+		return GasPriorityFast
+	}
+	return c.defaultPriority
+}
+
+// SuggestGasPriceContext is like SuggestGasPrice, but any request made to the API is bound to ctx. If the client was
+// configured with WithTimeout, the timeout only applies if it ends before the deadline of ctx.
+func (c *Client) SuggestGasPriceContext(ctx context.Context, priority GasPriority) (*big.Int, error) {
+	if c.priorityCache != nil && priority != GasPriorityTurbo {
+		// the derived turbo level needs both fast and fastest, so it is served from the whole response
+		if provider, ok := c.source().(PriorityProvider); ok {
+			price, err := c.suggestPriority(ctx, provider, priority)
+			if err != nil {
+				prices, err := c.lastResortOr(ctx, err)
+				if err != nil {
+					return nil, err
+				}
+				return prices.Price(priority)
+			}
+			return price, nil
+		}
+	}
+	prices, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return prices.Price(priority)
+}
+
+// SuggestGasPriceOrStale is like SuggestGasPriceContext, but gives the caller control over the tradeoff between
+// freshness and latency. If the cached prices have expired, new prices are loaded with ctx, and if that fails or ctx is
+// done first, the cached price is returned with stale set instead of an error, whatever its age, unless it is older than
+// the limit set with WithMaxServeAge.
+//
+// An error is only returned if no prices are cached yet or the client is closed. A client that doesn't cache always
+// loads new prices and never returns a stale price.
+func (c *Client) SuggestGasPriceOrStale(
+	ctx context.Context,
+	priority GasPriority,
+) (price *big.Int, stale bool, err error) {
+	var prices GasPrices
+	if c.cache == nil {
+		prices, err = c.fetch(ctx)
+	} else {
+		prices, stale, err = c.cache.latestOrStale(ctx)
+	}
+	if err != nil {
+		if prices, err = c.lastResortOr(ctx, err); err != nil {
+			return nil, false, err
+		}
+	}
+	price, err = prices.Price(priority)
+	if err != nil {
+		return nil, false, err
+	}
+	return price, stale, nil
+}
+
+// SuggestGasPriceWithRefresh returns the current price of priority immediately, even if it is stale, along with a
+// channel that receives all the prices once a background refresh of stale prices is done, so event-driven callers can
+// use the stale price now and update it when new prices are loaded, without polling. The channel is closed once the
+// refresh is done, without receiving prices if it fails, and is already closed if the price is not stale. The received
+// prices are a copy and may be modified freely.
+//
+// Stale prices are served by a client configured with WithMaxStaleness or WithAsyncRefresh, other clients return a
+// closed channel. A client configured with WithPerPriorityCache serves it from the whole-response cache.
+func (c *Client) SuggestGasPriceWithRefresh(priority GasPriority) (*big.Int, <-chan GasPrices, error) {
+	var prices GasPrices
+	var refreshed <-chan GasPrices
+	var err error
+	if c.cache != nil {
+		var read cacheRead
+		read, refreshed, err = c.cache.readNotify(context.Background(), true)
+		prices = read.prices
+	} else {
+		prices, err = c.fetch(context.Background())
+	}
+	if err != nil {
+		if prices, err = c.lastResortOr(context.Background(), err); err != nil {
+			return nil, nil, err
+		}
+	}
+	price, err := prices.Price(priority)
+	if err != nil {
+		return nil, nil, err
+	}
+	if refreshed == nil {
+		done := make(chan GasPrices)
+		close(done)
+		refreshed = done
+	}
+	return price, refreshed, nil
+}
+
+// SuggestGasPriceByDeadline is like SuggestGasPriceContext, for callers that track an absolute deadline rather than a
+// context. It returns context.DeadlineExceeded without loading prices if the deadline has already passed.
+func (c *Client) SuggestGasPriceByDeadline(deadline time.Time, priority GasPriority) (*big.Int, error) {
+	if !time.Now().Before(deadline) {
+		return nil, context.DeadlineExceeded
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	return c.SuggestGasPriceContext(ctx, priority)
+}
+
+// SuggestGasPriceWithConfidence returns a suggested gas price in wei along with the probability, between 0 and 1, that
+// a transaction at that price is mined within the target time of the priority. Use it for risk-aware submission logic.
+//
+// The confidence is NaN if the provider does not report it, which is the case for the ETH Gas Station API.
+func (c *Client) SuggestGasPriceWithConfidence(priority GasPriority) (price *big.Int, confidence float64, err error) {
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return nil, 0, err
+	}
+	price, err = prices.Price(priority)
+	if err != nil {
+		return nil, 0, err
+	}
+	return price, prices.PriceConfidence(priority), nil
+}
+
+// SuggestGasPriceWithPrices returns a suggested gas price in wei along with all the prices it was taken from, so other
+// priority levels can be used without loading prices again. The returned prices are a copy and may be modified freely.
+func (c *Client) SuggestGasPriceWithPrices(priority GasPriority) (*big.Int, GasPrices, error) {
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return nil, GasPrices{}, err
+	}
+	price, err := prices.Price(priority)
+	if err != nil {
+		return nil, GasPrices{}, err
+	}
+	return price, prices.copy(), nil
+}
+
+// GasPriceOptions returns every priority level as an option with its price, wait estimate and confidence, sorted by
+// ascending price, all taken from a single response. Unless the client was configured with WithMaxResultAge, it always
+// makes a new call to the provider. The wait estimates come from the model set with WithCustomConfirmationModel if any.
+func (c *Client) GasPriceOptions() ([]GasPriceOption, error) {
+	prices, err := c.loadWaits()
+	if err != nil {
+		return nil, err
+	}
+	return prices.Options(), nil
+}
+
+// SuggestGasPriceDetails returns the suggested gas price of priority along with its wait estimate and confidence, for
+// presenting a choice such as "fast: 45 gwei, ~30s" to a user. The wait estimate comes from the model set with
+// WithCustomConfirmationModel if any. Use GasPriceOptions for every level of a single response at once.
+func (c *Client) SuggestGasPriceDetails(priority GasPriority) (GasPriceOption, error) {
+	prices, err := c.loadWaits()
+	if err != nil {
+		return GasPriceOption{}, err
+	}
+	return prices.Option(priority)
+}
+
+// Snapshot returns all the prices of a single response, so values derived from several priority levels are consistent
+// even if the cache is refreshed in between. A caching client returns its cached prices, loading new prices if they
+// have expired. The returned prices are a copy and may be modified freely.
+func (c *Client) Snapshot() (GasPrices, error) {
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return GasPrices{}, err
+	}
+	return prices.copy(), nil
+}
+
+// SuggestGasPriceRat returns a suggested gas price in gwei as an exact rational number, converted with the decimals of
+// the native token of the client, see WithNativeTokenDecimals. Unless the client was configured with WithMaxResultAge,
+// it always makes a new call to the ETH Gas Station API.
+func (c *Client) SuggestGasPriceRat(priority GasPriority) (*big.Rat, error) {
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	price, err := prices.Price(priority)
+	if err != nil {
+		return nil, err
+	}
+	return scaleDecimals(price, c.NativeTokenDecimals()-9), nil
+}
+
+// PriceForMaxWait returns the cheapest gas price in wei that is expected to confirm within maxWait, based on the
+// prediction table reported by the provider, with wait times estimated by the model set with
+// WithCustomConfirmationModel if any.
+func (c *Client) PriceForMaxWait(maxWait time.Duration) (*big.Int, error) {
+	prices, err := c.loadWaits()
+	if err != nil {
+		return nil, err
+	}
+	return prices.PriceForMaxWait(maxWait)
+}
+
+// PriorityForTargetWait returns the cheapest priority level that is expected to confirm within target, based on the
+// wait times reported by the provider for each level, or estimated by the model set with WithCustomConfirmationModel.
+func (c *Client) PriorityForTargetWait(target time.Duration) (GasPriority, error) {
+	prices, err := c.loadWaits()
+	if err != nil {
+		return "", err
+	}
+	return prices.PriorityForTargetWait(target)
+}
+
+// CachedPrices returns the prices currently cached by the client along with the prices they replaced on the most recent
+// refresh that changed them, so callers can compute how prices moved between refreshes. A refresh that returns the same
+// prices, as compared by WithResultComparator, only makes the cached prices fresh again. Only one previous snapshot is
+// kept, and it is empty until the prices have changed at least once.
+//
+// CachedPrices never loads a new response. It returns false if the client isn't caching or nothing is cached yet.
+func (c *Client) CachedPrices() (current, previous GasPrices, ok bool) {
+	if c.cache == nil {
+		return GasPrices{}, GasPrices{}, false
+	}
+	return c.cache.cached()
+}
+
+// Invalidate marks the prices cached by the client as expired, so the next call loads new prices from the provider
+// whatever their age, for instance after a fork or switching providers. Unlike a refresh, no prices are loaded until
+// then. A background refresh in flight is discarded once it completes, and the result shared by WithFetchDedupWindow
+// and the failure remembered by WithErrorCache are forgotten.
+//
+// The invalidated prices are still returned by CachedPrices, and by SuggestGasPriceOrStale if loading new prices fails.
+func (c *Client) Invalidate() {
+	if c.cache != nil {
+		c.cache.invalidate()
+	}
+	if c.priorityCache != nil {
+		c.priorityCache.invalidate()
+	}
+	if c.percentileCache != nil {
+		c.percentileCache.invalidate()
+	}
+	if c.dedup != nil {
+		c.dedup.forget()
+	}
+	if c.errCache != nil {
+		c.errCache.reset()
+	}
+}
+
+// NewGasPriceSuggester returns a function that can be used to either load a new gas price response, or use a cached
+// response if it is within the age range defined by maxResultAge. The returned function uses the client's configuration.
+func (c *Client) NewGasPriceSuggester(maxResultAge time.Duration) (GasPriceSuggester, error) {
+	suggest, err := c.NewGasPriceSuggesterContext(context.Background(), maxResultAge)
+	if err != nil {
+		return nil, err
+	}
+	return func(priority GasPriority) (*big.Int, error) {
+		return suggest(context.Background(), priority)
+	}, nil
+}
+
+// NewGasPriceSuggesterContext is like NewGasPriceSuggester, but the first response is loaded with ctx, and the
+// returned function binds the request of each new response to the context it is given.
+func (c *Client) NewGasPriceSuggesterContext(
+	ctx context.Context,
+	maxResultAge time.Duration,
+) (ContextGasPriceSuggester, error) {
+	if maxResultAge < 0 {
+		return nil, errors.New("eth: max result age must not be negative")
+	}
+	prices, err := c.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := gasPriceManager{
+		latestPrices: prices,
+		fetchedAt:    c.clock(),
+		now:          c.now,
+		maxResultAge: maxResultAge,
+		fetch:        c.fetch,
+	}
+
+	return func(ctx context.Context, priority GasPriority) (*big.Int, error) {
+		return m.suggestCachedGasPrice(ctx, priority)
+	}, nil
+}
+
+// Close shuts down the client. Calls that are in flight are canceled and return ErrClientClosed, and Close waits for
+// them to return before closing idle connections of the underlying HTTP client. Any call made after Close returns
+// ErrClientClosed.
+//
+// Close is idempotent and safe to call concurrently with other calls on the client. It always returns nil.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		if c.done != nil {
+			close(c.done)
+		}
+	}
+	c.mu.Unlock()
+
+	// no new calls can be registered once closed, so it is safe to wait here
+	c.inFlight.Wait()
+	c.closeEvents()
+	closeIdleConnections(c.source())
+	return nil
+}
+
+// SetKey changes the API key used by the default provider, as configured with WithAPIKey. It is safe to call while
+// the client is in use, and takes effect on the next request. An empty key reverts to the key set with the
+// package-level SetKey, if any, and other keys that are rejected by ValidateKey are an error. It is an error for a
+// client configured with WithKeys.
+func (c *Client) SetKey(key string) error {
+	if c.keys != nil {
+		return errors.New("eth: client uses a key pool")
+	}
+	if key != "" {
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+	}
+	if err := c.checkTLS(key != ""); err != nil {
+		return err
+	}
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	c.apiKey = key
+	return nil
+}
+
+// checkTLS returns an error if the URL of the default provider isn't https while TLS is required, which it is by
+// default if keyed is set, as the key would be sent in plaintext
+func (c *Client) checkTLS(keyed bool) error {
+	required := keyed
+	if c.requireTLS != nil {
+		required = *c.requireTLS
+	}
+	if !required || c.url == "" {
+		return nil
+	}
+	if u, err := url.Parse(c.url); err == nil && strings.EqualFold(u.Scheme, "https") {
+		return nil
+	}
+	return errors.New("eth: url must use https, use WithRequireTLS(false) to allow plaintext requests")
+}
+
+// SetProvider replaces the provider prices are loaded from, for switching a live client to another provider, such as
+// from a failing paid API to a node, without recreating it. It is safe to call while the client is in use: fetches
+// started after it returns use provider, while fetches in flight complete against the previous provider. Prices
+// already cached are served until they expire, call Invalidate to load prices from provider on the next call.
+//
+// The previous provider is not closed, close its idle connections once it is no longer used, if needed. Options that
+// configure the default provider, such as WithAPIKey, no longer apply once a provider is set. A client configured with
+// WithProviderSwapGrace serves the prices last loaded from the previous provider while provider fails to load them.
+func (c *Client) SetProvider(provider Provider) error {
+	if provider == nil {
+		return errors.New("eth: provider must not be nil")
+	}
+	if validator, ok := provider.(configValidator); ok {
+		if err := validator.validateConfig(); err != nil {
+			return err
+		}
+	}
+	var swap providerSwap
+	if last, ok := c.LastFetch(); ok && c.swapGrace > 0 {
+		swap.at = time.Now()
+		swap.until = swap.at.Add(c.swapGrace)
+		swap.prices = last.Prices
+	}
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	c.provider = provider
+	c.swap = swap
+	return nil
+}
+
+// providerSwap holds the prices last loaded from a provider replaced by SetProvider at at, to be served until until
+type providerSwap struct {
+	at, until time.Time
+	prices    GasPrices
+}
+
+// swapPrices returns the prices of the previous provider if the grace period of the last provider swap is running
+func (c *Client) swapPrices() (GasPrices, bool) {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	if c.swap.until.IsZero() || !time.Now().Before(c.swap.until) {
+		return GasPrices{}, false
+	}
+	return c.swap.prices.copy(), true
+}
+
+// endSwapGrace ends the grace period of the last provider swap after a fetch that started at start succeeded, if it
+// started after the swap, so it was loaded from the new provider
+func (c *Client) endSwapGrace(start time.Time) {
+	if c.swapGrace == 0 {
+		return
+	}
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	if !c.swap.at.IsZero() && !start.Before(c.swap.at) {
+		c.swap = providerSwap{}
+	}
+}
+
+// SetTimeout changes the timeout of each request, as configured with WithTimeout. It is safe to call while the client
+// is in use, and takes effect on the next request. A zero timeout disables it.
+func (c *Client) SetTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return errors.New("eth: timeout must not be negative")
+	}
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	c.timeout = timeout
+	return nil
+}
+
+// begin registers an in-flight call on the client. The returned context is derived from parent and canceled when the
+// client is closed, the returned function must be called when the call is done.
+func (c *Client) begin(parent context.Context) (context.Context, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, nil, ErrClientClosed
+	}
+	if c.done == nil {
+		c.done = make(chan struct{})
+	}
+	c.inFlight.Add(1)
+
+	ctx, cancel := context.WithCancel(parent)
+	go func(closed <-chan struct{}) {
+		select {
+		case <-closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}(c.done)
+
+	return ctx, func() {
+		cancel()
+		c.inFlight.Done()
+	}, nil
+}
+
+// closedChan returns a channel that is closed when the client is closed
+func (c *Client) closedChan() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.done == nil {
+		c.done = make(chan struct{})
+		if c.closed {
+			close(c.done)
+		}
+	}
+	return c.done
+}
+
+func (c *Client) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+type backgroundRefreshKey struct{}
+
+// contextWithBackgroundRefresh marks ctx as that of a background refresh, which is bound by the refresh timeout
+func contextWithBackgroundRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, backgroundRefreshKey{}, true)
+}
+
+func isBackgroundRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(backgroundRefreshKey{}).(bool)
+	return refresh
+}
+
+// detachedContext carries the values of parent, such as a request ID or tracing span, but not its deadline or
+// cancellation, for work that outlives the call that started it. Fail fast mode is not carried, since the caller
+// doesn't wait for the work.
+type detachedContext struct {
+	parent context.Context
+}
+
+// detach returns a context with the values of parent that is never done
+func detach(parent context.Context) context.Context {
+	return detachedContext{parent: parent}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	if _, ok := key.(failFastKey); ok {
+		return nil
+	}
+	return c.parent.Value(key)
+}
+
+// withTimeout derives a context bounded by the client's timeout, but only if the timeout is tighter than the existing
+// deadline of parent. A caller with a shorter deadline is never forced to wait for the client's timeout.
+func (c *Client) withTimeout(parent context.Context) (context.Context, context.CancelFunc) {
+	c.configMu.RLock()
+	timeout := c.timeout
+	c.configMu.RUnlock()
+	if c.refreshTimeout > 0 && isBackgroundRefresh(parent) {
+		timeout = c.refreshTimeout
+	}
+
+	if timeout <= 0 {
+		return parent, func() {}
+	}
+	if deadline, ok := parent.Deadline(); ok && time.Until(deadline) <= timeout {
+		return parent, func() {}
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// source returns the configured provider, or the default provider if none was configured
+func (c *Client) source() Provider {
+	if provider := c.customProvider(); provider != nil {
+		return provider
+	}
+	provider := c.defaultProvider()
+	if c.keys != nil {
+		return &keyPoolProvider{pool: c.keys, provider: provider}
+	}
+	return &provider
+}
+
+// customProvider returns the provider set with WithProvider or SetProvider, or nil if the client uses the default
+// provider
+func (c *Client) customProvider() Provider {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return c.provider
+}
+
+// defaultProvider returns the default provider configured with the client's options
+func (c *Client) defaultProvider() ETHGasStationProvider {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return ETHGasStationProvider{
+		URL:           c.url,
+		APIKey:        c.apiKey,
+		InputScale:    c.inputScale,
+		HTTPClient:    c.httpClient,
+		CharsetReader: c.charsetReader,
+		EnvelopePath:  c.envelopePath,
+		FieldMapping:  c.fieldMapping,
+		OnSchemaDrift: c.schemaDrift,
+		StrictSchema:  c.strictSchema,
+		AllowedFields: c.allowedFields,
+	}
+}
+
+// load returns the cached prices if the client is caching, otherwise it always fetches new prices
+func (c *Client) load(ctx context.Context) (prices GasPrices, err error) {
+	if c.cache != nil {
+		prices, err = c.cache.latest(ctx)
+	} else {
+		prices, err = c.fetch(ctx)
+	}
+	if err != nil {
+		if prices, ok := c.swapPrices(); ok && !errors.Is(err, ErrClientClosed) {
+			return prices, nil
+		}
+		return c.lastResortOr(ctx, err)
+	}
+	return prices, nil
+}
+
+// lastResortOr computes prices with the function set with WithLastResort after loading prices failed with err. The
+// prices are not cached, and err is returned if there is no such function, the client is closed or the function fails
+// too.
+func (c *Client) lastResortOr(ctx context.Context, err error) (GasPrices, error) {
+	if c.lastResort == nil || errors.Is(err, ErrClientClosed) {
+		return GasPrices{}, err
+	}
+	prices, lastResortErr := c.fetchWith(ctx, c.lastResort)
+	if lastResortErr != nil {
+		return GasPrices{}, err
+	}
+	return prices, nil
+}
+
+// fetch loads new prices from the provider and applies the client's configuration
+func (c *Client) fetch(ctx context.Context) (GasPrices, error) {
+	start := time.Now()
+	prices, err := c.fetchWith(ctx, func(ctx context.Context) (GasPrices, error) {
+		load := c.fetchWithRetry
+		if c.processCacheAge > 0 {
+			load = func(ctx context.Context) (GasPrices, error) {
+				return c.fetchShared(ctx, c.fetchWithRetry)
+			}
+		}
+		fetch := load
+		if c.errCache != nil {
+			fetch = func(ctx context.Context) (GasPrices, error) {
+				return c.errCache.do(ctx, load)
+			}
+		}
+		if c.dedup != nil {
+			return c.dedup.do(ctx, fetch)
+		}
+		return fetch(ctx)
+	})
+	if err != nil {
+		return GasPrices{}, err
+	}
+	c.recordFetch(prices, start)
+	c.endSwapGrace(start)
+	return prices, nil
+}
+
+// fetchWith loads prices with load as an in-flight call of the client, and applies the client's configuration
+func (c *Client) fetchWith(ctx context.Context, load func(context.Context) (GasPrices, error)) (GasPrices, error) {
+	if c.failFast {
+		ctx = ContextWithFailFast(ctx)
+	}
+	ctx, done, err := c.begin(ctx)
+	if err != nil {
+		return GasPrices{}, c.wrapError(err)
+	}
+	defer done()
+
+	prices, err := load(ctx)
+	if err != nil {
+		if c.isClosed() {
+			// the request was canceled by Close
+			err = ErrClientClosed
+		}
+		return GasPrices{}, c.wrapError(err)
+	}
+	if err := c.validate(prices); err != nil {
+		return GasPrices{}, c.wrapError(err)
+	}
+	if err := c.checkPlausible(prices); err != nil {
+		return GasPrices{}, c.wrapError(err)
+	}
+	if err := c.checkBaseline(prices); err != nil {
+		return GasPrices{}, c.wrapError(err)
+	}
+	if c.transform != nil {
+		prices = c.transform(prices)
+	}
+	if c.roundTo != nil {
+		prices = roundPrices(prices, c.roundTo, c.roundingMode)
+	}
+	return prices, nil
+}
+
+// wrapError prefixes err with the name of the client, if it has one
+func (c *Client) wrapError(err error) error {
+	if c.name == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", c.name, err)
+}
@@ -0,0 +1,1641 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTransport sends every request to a test server, regardless of the requested URL
+type testTransport struct {
+	server *url.URL
+}
+
+func (t testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.server.Scheme
+	req.URL.Host = t.server.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestClient returns a client that sends all requests to a test server using handler, and a function that stops
+// the server
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) (*Client, func()) {
+	server := httptest.NewServer(handler)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	httpClient := &http.Client{Transport: testTransport{server: serverURL}}
+	c, err := NewClient(append([]Option{WithHTTPClient(httpClient)}, opts...)...)
+	require.NoError(t, err)
+	return c, server.Close
+}
+
+const testResponse = `{"fast": 200.0, "fastest": 250.0, "safeLow": 100.0, "average": 150.0}`
+
+func serveTestResponse(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte(testResponse))
+}
+
+func TestWithInputScale(t *testing.T) {
+	// 1. default client assumes tenths of gwei
+	c, err := NewClient()
+	require.NoError(t, err)
+	assert.Equal(t, InputScaleTenthsOfGwei, c.inputScale)
+
+	// 2. gwei scale is applied to the client
+	c, err = NewClient(WithInputScale(InputScaleGwei))
+	require.NoError(t, err)
+	assert.Equal(t, InputScaleGwei, c.inputScale)
+	c, err = NewClient(WithInputScale(InputScaleWei))
+	require.NoError(t, err)
+	assert.Equal(t, "wei", c.inputScale.String())
+
+	// 3. unknown scales are rejected
+	_, err = NewClient(WithInputScale(InputScale(42)))
+	assert.Error(t, err)
+}
+
+func TestNewGasPricesWithScale(t *testing.T) {
+	response := ethGasStationResponse{Fast: "10.0", Fastest: "10.0", SafeLow: "10.0", Average: "10.0"}
+	oneGweiInBaseUnits := big.NewInt(int64(1e9))
+
+	prices, err := newGasPrices(response, InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	assert.Equal(t, 0, oneGweiInBaseUnits.Cmp(prices.Fast))
+
+	prices, err = newGasPrices(response, InputScaleGwei)
+	require.NoError(t, err)
+	assert.Equal(t, 0, new(big.Int).Mul(oneGweiInBaseUnits, big.NewInt(10)).Cmp(prices.Fast))
+
+	// wei prices are taken as they are, including the predictions
+	response = ethGasStationResponse{Fast: "12012345678", GasPriceRange: map[string]float64{"10000000001": 5}}
+	prices, err = newGasPrices(response, InputScaleWei)
+	require.NoError(t, err)
+	assert.Equal(t, "12012345678", prices.Fast.String())
+	assert.Equal(t, "10000000001", prices.Predictions[0].Price.String())
+}
+
+func TestWithResultTransform(t *testing.T) {
+	twoGwei := big.NewInt(2e9)
+	markup := func(prices GasPrices) GasPrices {
+		prices.Fast.Add(prices.Fast, twoGwei)
+		return prices
+	}
+
+	fetches := 0
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		fetches++
+		return GasPrices{Fast: big.NewInt(1e9), Fastest: big.NewInt(2e9)}, nil
+	})
+
+	// 1. the transform is applied to fresh prices
+	c, err := NewClient(WithProvider(provider), WithResultTransform(markup))
+	require.NoError(t, err)
+
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "3000000000", price.String())
+	price, err = c.SuggestGasPrice(GasPriorityFastest)
+	require.NoError(t, err)
+	assert.Equal(t, "2000000000", price.String())
+
+	// 2. refreshes of the cache are transformed before they are stored
+	c, err = NewClient(WithProvider(provider), WithResultTransform(markup), WithMaxResultAge(time.Hour))
+	require.NoError(t, err)
+
+	fetches = 0
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "3000000000", price.String())
+
+	// 3. the transform is not applied again on cache hits, and callers can't modify the cached value
+	price.SetInt64(0)
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "3000000000", price.String())
+	assert.Equal(t, 1, fetches)
+}
+
+func TestClientClose(t *testing.T) {
+	started := make(chan struct{})
+	c, stop := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+	defer stop()
+
+	// 1. in-flight calls are canceled and return the closed error
+	errs := make(chan error)
+	go func() {
+		_, err := c.SuggestGasPrice(GasPriorityFast)
+		errs <- err
+	}()
+	<-started
+	require.NoError(t, c.Close())
+	assert.Equal(t, ErrClientClosed, <-errs)
+
+	// 2. calls after close fail immediately
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	assert.Equal(t, ErrClientClosed, err)
+
+	// 3. close is idempotent
+	assert.NoError(t, c.Close())
+}
+
+func TestClientSuggestGasPrice(t *testing.T) {
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	require.NoError(t, c.Close())
+}
+
+func TestWithResultComparator(t *testing.T) {
+	var (
+		calls  int32
+		script = []int64{1, 1, 2, 3}
+	)
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return GasPrices{Fast: big.NewInt(script[n-1]), Average: big.NewInt(int64(n))}, nil
+	})
+	refresh := func(c *Client, times int) (current, previous GasPrices) {
+		for i := 0; i < times; i++ {
+			_, err := c.SuggestGasPrice(GasPriorityFast)
+			require.NoError(t, err)
+		}
+		current, previous, ok := c.CachedPrices()
+		require.True(t, ok)
+		return current, previous
+	}
+
+	// 1. by default refreshes are unchanged only if every price is equal
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(0))
+	require.NoError(t, err)
+	current, previous := refresh(c, 2)
+	assert.Equal(t, "2", current.Average.String())
+	assert.Equal(t, "1", previous.Average.String())
+
+	// 2. an unchanged refresh keeps the previous snapshot, but serves the refreshed prices
+	atomic.StoreInt32(&calls, 0)
+	sameFast := func(previous, next GasPrices) bool {
+		return previous.Fast.Cmp(next.Fast) == 0
+	}
+	c, err = NewClient(WithProvider(provider), WithMaxResultAge(0), WithResultComparator(sameFast))
+	require.NoError(t, err)
+	current, previous = refresh(c, 2)
+	assert.Equal(t, "2", current.Average.String())
+	assert.Nil(t, previous.Fast)
+	current, previous = refresh(c, 1)
+	assert.Equal(t, "2", current.Fast.String())
+	assert.Equal(t, "2", previous.Average.String())
+
+	// 3. a comparator requires caching and must not be nil
+	_, err = NewClient(WithResultComparator(func(_, _ GasPrices) bool { return true }))
+	assert.Error(t, err)
+	_, err = NewClient(WithMaxResultAge(time.Minute), WithResultComparator(nil))
+	assert.Error(t, err)
+}
+
+func TestWithWarningLogger(t *testing.T) {
+	atomic.StoreInt32(&freeEndpointWarned, 0)
+	defer atomic.StoreInt32(&freeEndpointWarned, 0)
+	var warnings []string
+	warn := func(msg string) {
+		warnings = append(warnings, msg)
+	}
+
+	// 1. clients with a provider, url or key don't warn
+	_, err := NewClient(WithWarningLogger(warn), WithProvider(countingProvider(0, nil, new(int32))))
+	require.NoError(t, err)
+	_, err = NewClient(WithWarningLogger(warn), WithURL("https://gas.example.com"))
+	require.NoError(t, err)
+	_, err = NewClient(WithWarningLogger(warn), WithAPIKey("0123456789abcdef"))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// 2. a client on the free endpoint warns, once per process
+	_, err = NewClient(WithWarningLogger(warn))
+	require.NoError(t, err)
+	_, err = NewClient(WithWarningLogger(warn))
+	require.NoError(t, err)
+	assert.Equal(t, []string{freeEndpointWarning}, warnings)
+
+	// 3. the logger must not be nil
+	_, err = NewClient(WithWarningLogger(nil))
+	assert.Error(t, err)
+}
+
+func TestWithLastResort(t *testing.T) {
+	var calls, lastResortCalls int32
+	outage := errors.New("outage")
+	provider := countingProvider(math.MaxInt32, outage, &calls)
+	lastResort := func(context.Context) (GasPrices, error) {
+		atomic.AddInt32(&lastResortCalls, 1)
+		return GasPrices{SafeLow: big.NewInt(1e9), Average: big.NewInt(2e9), Fast: big.NewInt(3e9),
+			Fastest: big.NewInt(4e9)}, nil
+	}
+
+	// 1. prices are computed by the last resort once the provider failed
+	c, err := NewClient(WithProvider(provider), WithLastResort(lastResort), WithRoundTo(2))
+	require.NoError(t, err)
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "4000000000", price.String(), "last resort prices are rounded like provider prices")
+	result, err := c.SuggestDetailed(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, ResultSourceLastResort, result.Source)
+
+	// 2. a caching client doesn't cache them, so the next call tries the provider again
+	c, err = NewClient(WithProvider(provider), WithLastResort(lastResort), WithMaxResultAge(time.Minute))
+	require.NoError(t, err)
+	atomic.StoreInt32(&calls, 0)
+	for i := 0; i < 2; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	_, _, ok := c.CachedPrices()
+	assert.False(t, ok)
+
+	// 3. the error of the provider is returned if the last resort fails too
+	c, err = NewClient(WithProvider(provider), WithLastResort(func(context.Context) (GasPrices, error) {
+		return GasPrices{}, errors.New("node down")
+	}))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, outage))
+
+	// 4. it is not called for a closed client, and must not be nil
+	atomic.StoreInt32(&lastResortCalls, 0)
+	c, err = NewClient(WithProvider(provider), WithLastResort(lastResort))
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrClientClosed))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&lastResortCalls))
+	_, err = NewClient(WithLastResort(nil))
+	assert.Error(t, err)
+}
+
+func TestClientSuggestGasPriceWithRefresh(t *testing.T) {
+	var calls, fail int32
+	release := make(chan struct{}, 2)
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n > 1 {
+			<-release
+		}
+		if atomic.LoadInt32(&fail) == 1 {
+			return GasPrices{}, errors.New("failure")
+		}
+		return GasPrices{Fast: big.NewInt(int64(n))}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(10*time.Millisecond), WithAsyncRefresh())
+	require.NoError(t, err)
+
+	// 1. fresh prices come with a closed channel
+	price, refreshed, err := c.SuggestGasPriceWithRefresh(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "1", price.String())
+	_, ok := <-refreshed
+	assert.False(t, ok)
+
+	// 2. stale prices come with a channel that receives the refreshed prices, shared by calls during the refresh
+	time.Sleep(20 * time.Millisecond)
+	price, refreshed, err = c.SuggestGasPriceWithRefresh(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "1", price.String())
+	_, joined, err := c.SuggestGasPriceWithRefresh(GasPriorityFast)
+	require.NoError(t, err)
+	release <- struct{}{}
+	prices, ok := <-refreshed
+	require.True(t, ok)
+	assert.Equal(t, "2", prices.Fast.String())
+	prices, ok = <-joined
+	require.True(t, ok)
+	assert.Equal(t, "2", prices.Fast.String())
+	_, ok = <-refreshed
+	assert.False(t, ok)
+
+	// 3. the channel is closed without prices if the refresh fails
+	atomic.StoreInt32(&fail, 1)
+	time.Sleep(20 * time.Millisecond)
+	price, refreshed, err = c.SuggestGasPriceWithRefresh(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "2", price.String())
+	release <- struct{}{}
+	_, ok = <-refreshed
+	assert.False(t, ok)
+
+	// 4. a client that doesn't cache loads new prices and returns a closed channel
+	atomic.StoreInt32(&fail, 0)
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &calls)))
+	require.NoError(t, err)
+	price, refreshed, err = c.SuggestGasPriceWithRefresh(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	_, ok = <-refreshed
+	assert.False(t, ok)
+}
+
+func TestClientInvalidate(t *testing.T) {
+	var calls int32
+	started, release := make(chan struct{}), make(chan struct{})
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 2 {
+			close(started)
+			<-release
+		}
+		return GasPrices{Fast: big.NewInt(int64(n))}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(10*time.Millisecond), WithAsyncRefresh())
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+
+	// 1. a stale read starts a background refresh, which is discarded once the cache is invalidated
+	time.Sleep(20 * time.Millisecond)
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "1", price.String())
+	<-started
+	c.Invalidate()
+
+	// 2. the next read waits for new prices rather than serving the invalidated ones
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "3", price.String())
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	current, previous, ok := c.CachedPrices()
+	require.True(t, ok)
+	assert.Equal(t, "3", current.Fast.String())
+	assert.Equal(t, "1", previous.Fast.String())
+
+	// 3. invalidating forgets the result shared by a client that doesn't cache
+	atomic.StoreInt32(&calls, 10)
+	c, err = NewClient(WithProvider(provider), WithFetchDedupWindow(time.Minute), WithErrorCache(time.Minute))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(11), atomic.LoadInt32(&calls))
+	c.Invalidate()
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "12", price.String())
+
+	// 4. invalidating a client that doesn't cache anything is a no-op
+	c, err = NewClient()
+	require.NoError(t, err)
+	c.Invalidate()
+}
+
+func TestClientCachedPrices(t *testing.T) {
+	// 1. clients without a cache never have cached prices
+	c, err := NewClient()
+	require.NoError(t, err)
+	_, _, ok := c.CachedPrices()
+	assert.False(t, ok)
+
+	// 2. nothing is cached before the first call
+	c, err = NewClient(WithMaxResultAge(0))
+	require.NoError(t, err)
+	_, _, ok = c.CachedPrices()
+	assert.False(t, ok)
+
+	fast := int64(0)
+	c.cache.fetch = func(context.Context) (GasPrices, error) {
+		fast++
+		return GasPrices{Fast: big.NewInt(fast)}, nil
+	}
+
+	// 3. the first refresh has no previous snapshot
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	current, previous, ok := c.CachedPrices()
+	require.True(t, ok)
+	assert.Equal(t, int64(1), current.Fast.Int64())
+	assert.Nil(t, previous.Fast)
+
+	// 4. only the most recent previous snapshot is kept
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	current, previous, ok = c.CachedPrices()
+	require.True(t, ok)
+	assert.Equal(t, int64(3), current.Fast.Int64())
+	assert.Equal(t, int64(2), previous.Fast.Int64())
+
+	// 5. returned prices are copies of the cache
+	current.Fast.SetInt64(100)
+	current, _, _ = c.CachedPrices()
+	assert.Equal(t, int64(3), current.Fast.Int64())
+}
+
+func TestClientTimeout(t *testing.T) {
+	hang := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	// 1. the client timeout applies when it is sooner than the caller's deadline
+	c, stop := newTestClient(t, hang, WithTimeout(50*time.Millisecond))
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.SuggestGasPriceContext(ctx, GasPriorityFast)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.NoError(t, ctx.Err(), "caller context should not have expired")
+
+	// 2. the caller's deadline applies when it is sooner than the client timeout
+	c, stop = newTestClient(t, hang, WithTimeout(2*time.Second))
+	defer stop()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	_, err = c.SuggestGasPriceContext(ctx, GasPriorityFast)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestClientWithTimeout(t *testing.T) {
+	c, err := NewClient(WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	// 1. a parent with a sooner deadline is used as is
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+
+	ctx, cancelTimeout := c.withTimeout(parent)
+	deadline, ok := ctx.Deadline()
+	cancelTimeout()
+	require.True(t, ok)
+	assert.Equal(t, parentDeadline, deadline)
+
+	// 2. a parent with a later deadline is bounded by the client timeout
+	parent, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	ctx, cancelTimeout = c.withTimeout(parent)
+	deadline, ok = ctx.Deadline()
+	cancelTimeout()
+	require.True(t, ok)
+	assert.True(t, deadline.Before(time.Now().Add(time.Minute+time.Second)))
+
+	// 3. a parent without a deadline is bounded by the client timeout
+	ctx, cancelTimeout = c.withTimeout(context.Background())
+	_, ok = ctx.Deadline()
+	cancelTimeout()
+	assert.True(t, ok)
+
+	// 4. negative timeouts are rejected
+	_, err = NewClient(WithTimeout(-time.Second))
+	assert.Error(t, err)
+}
+
+func TestClientSuggestGasPriceRat(t *testing.T) {
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(120500000000)}, nil
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	price, err := c.SuggestGasPriceRat(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "241/2", price.String())
+}
+
+func TestWithProvider(t *testing.T) {
+	// 1. providers replace the default provider
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{SafeLow: big.NewInt(42)}, nil
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	price, err := c.SuggestGasPrice(GasPrioritySafeLow)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), price.Int64())
+
+	// 2. nil providers are rejected
+	_, err = NewClient(WithProvider(nil))
+	assert.Error(t, err)
+}
+
+func TestClientSuggestGasPriceWithConfidence(t *testing.T) {
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{
+			Fast:       big.NewInt(20e9),
+			SafeLow:    big.NewInt(10e9),
+			Confidence: map[GasPriority]float64{GasPriorityFast: 0.95},
+		}, nil
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	// 1. reported confidence is returned with the price
+	price, confidence, err := c.SuggestGasPriceWithConfidence(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, 0.95, confidence)
+
+	// 2. missing confidence is NaN
+	price, confidence, err = c.SuggestGasPriceWithConfidence(GasPrioritySafeLow)
+	require.NoError(t, err)
+	assert.Equal(t, "10000000000", price.String())
+	assert.True(t, math.IsNaN(confidence))
+}
+
+func TestClientUpdatedAt(t *testing.T) {
+	updatedAt := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(20e9), UpdatedAt: updatedAt}, nil
+	})
+
+	// 1. the upstream timestamp is kept through caching and transforms
+	c, err := NewClient(
+		WithProvider(provider),
+		WithMaxResultAge(time.Minute),
+		WithResultTransform(func(prices GasPrices) GasPrices { return prices }),
+	)
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+
+	current, _, ok := c.CachedPrices()
+	require.True(t, ok)
+	assert.True(t, updatedAt.Equal(current.UpdatedAt))
+
+	// 2. the ETH Gas Station API does not report a timestamp
+	prices, err := newGasPrices(ethGasStationResponse{Fast: "200", Fastest: "300", SafeLow: "100", Average: "150"}, InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	assert.True(t, prices.UpdatedAt.IsZero())
+}
+
+func TestWithName(t *testing.T) {
+	failure := &FetchError{StatusCode: http.StatusBadGateway, Err: errors.New("bad gateway")}
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{}, failure
+	})
+	c, err := NewClient(WithName("mainnet"), WithProvider(provider))
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet", c.Name())
+
+	// 1. errors are prefixed with the name and still match their cause
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "mainnet: "))
+	var fetchErr *FetchError
+	assert.True(t, errors.As(err, &fetchErr))
+
+	// 2. including errors from a closed client
+	require.NoError(t, c.Close())
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrClientClosed))
+	assert.True(t, strings.HasPrefix(err.Error(), "mainnet: "))
+}
+
+func TestClientSuggestGasPriceByDeadline(t *testing.T) {
+	var requests int32
+	c, stop := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	defer stop()
+
+	// 1. the request is canceled at the deadline
+	start := time.Now()
+	_, err := c.SuggestGasPriceByDeadline(start.Add(50*time.Millisecond), GasPriorityFast)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(start) < time.Second)
+
+	// 2. a deadline in the past fails without a request
+	_, err = c.SuggestGasPriceByDeadline(time.Now().Add(-time.Second), GasPriorityFast)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestClientSuggestGasPriceWithPrices(t *testing.T) {
+	c, stop := newTestClient(t, serveTestResponse, WithMaxResultAge(time.Minute))
+	defer stop()
+
+	// 1. the requested price is returned with every other price
+	price, prices, err := c.SuggestGasPriceWithPrices(GasPriorityAverage)
+	require.NoError(t, err)
+	assert.Equal(t, "15000000000", price.String())
+	assert.Equal(t, "20000000000", prices.Fast.String())
+	assert.Equal(t, "25000000000", prices.Fastest.String())
+	assert.Equal(t, "10000000000", prices.SafeLow.String())
+
+	// 2. the prices are a copy of the cached prices
+	prices.Fast.SetInt64(0)
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+}
+
+func TestWithMaxStaleness(t *testing.T) {
+	var calls int32
+	fail := int32(0)
+	release := make(chan struct{}, 1)
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n > 1 {
+			<-release
+		}
+		if atomic.LoadInt32(&fail) == 1 {
+			return GasPrices{}, errors.New("failure")
+		}
+		return GasPrices{Fast: big.NewInt(int64(n))}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(20*time.Millisecond), WithMaxStaleness(100*time.Millisecond))
+	require.NoError(t, err)
+
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "1", price.String())
+
+	// 1. stale prices are served while they are refreshed in the background
+	time.Sleep(30 * time.Millisecond)
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "1", price.String())
+
+	release <- struct{}{}
+	time.Sleep(10 * time.Millisecond)
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "2", price.String())
+
+	// 2. prices past the max staleness are not served if the refresh fails
+	atomic.StoreInt32(&fail, 1)
+	time.Sleep(110 * time.Millisecond)
+	release <- struct{}{}
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+
+	// 3. the max staleness requires caching and must not be less than the max result age
+	_, err = NewClient(WithMaxStaleness(time.Minute))
+	assert.Error(t, err)
+	_, err = NewClient(WithMaxResultAge(time.Minute), WithMaxStaleness(time.Second))
+	assert.Error(t, err)
+}
+
+func TestClientPriorityForTargetWait(t *testing.T) {
+	c, stop := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"fast": 200, "fastest": 250, "safeLow": 100, "average": 150,
+			"fastWait": 5, "fastestWait": 3, "safeLowWait": 125, "avgWait": 22}`))
+	})
+	defer stop()
+
+	// wait times in tenths of a minute are taken from the response
+	priority, err := c.PriorityForTargetWait(3 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, GasPriorityAverage, priority)
+}
+
+func TestClientReconfigure(t *testing.T) {
+	keys := make(chan string, 10)
+	c, stop := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.URL.Query().Get("api-key")
+		serveTestResponse(w, r)
+	}, WithAPIKey("old"))
+	defer stop()
+
+	// 1. a new key is used by the next request
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "old", <-keys)
+
+	require.NoError(t, c.SetKey("new"))
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "new", <-keys)
+
+	// 2. the timeout can be changed, but not to a negative value
+	require.NoError(t, c.SetTimeout(time.Second))
+	assert.Error(t, c.SetTimeout(-time.Second))
+
+	// 3. reconfiguring is safe while calls are in flight
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			_ = c.SetKey("rotated")
+			_ = c.SetTimeout(time.Duration(i+1) * time.Second)
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+		<-keys
+	}
+	<-done
+}
+
+func TestWithRequireTLS(t *testing.T) {
+	// 1. a key can't be sent to a plaintext url by default
+	_, err := NewClient(WithURL("http://gas.example/api"), WithAPIKey("secret"))
+	assert.Error(t, err)
+	_, err = NewClient(WithURL("http://gas.example/api"), WithKeys([]string{"a", "b"}))
+	assert.Error(t, err)
+
+	// 2. https urls, the default endpoints and plaintext urls without a key are allowed
+	_, err = NewClient(WithURL("https://gas.example/api"), WithAPIKey("secret"))
+	assert.NoError(t, err)
+	_, err = NewClient(WithAPIKey("secret"), WithRequireTLS(true))
+	assert.NoError(t, err)
+	c, err := NewClient(WithURL("http://gas.example/api"))
+	require.NoError(t, err)
+
+	// 3. setting a key later is checked too
+	assert.Error(t, c.SetKey("secret"))
+	assert.NoError(t, c.SetKey(""))
+
+	// 4. TLS can be required without a key, or disabled for a local mock
+	_, err = NewClient(WithURL("http://gas.example/api"), WithRequireTLS(true))
+	assert.Error(t, err)
+	_, err = NewClient(WithURL("http://127.0.0.1:8080"), WithAPIKey("secret"), WithRequireTLS(false))
+	assert.NoError(t, err)
+}
+
+func TestClientSetProvider(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	old := ProviderFunc(func(context.Context) (GasPrices, error) {
+		close(started)
+		<-release
+		return GasPrices{Fast: big.NewInt(1)}, nil
+	})
+	c, err := NewClient(WithProvider(old))
+	require.NoError(t, err)
+
+	// 1. a fetch in flight completes against the provider it started with
+	fetched := make(chan *big.Int)
+	go func() {
+		price, err := c.SuggestGasPrice(GasPriorityFast)
+		assert.NoError(t, err)
+		fetched <- price
+	}()
+	<-started
+	require.NoError(t, c.SetProvider(ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(2)}, nil
+	})))
+
+	// 2. later fetches use the new provider
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "2", price.String())
+	close(release)
+	assert.Equal(t, "1", (<-fetched).String())
+
+	// 3. the default provider can be replaced too, but not with nil
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+	require.NoError(t, c.SetProvider(countingProvider(0, nil, new(int32))))
+	_, err = c.SuggestGasPrice(GasPriorityAverage)
+	assert.Error(t, err)
+	assert.Error(t, c.SetProvider(nil))
+}
+
+func TestWithProviderSwapGrace(t *testing.T) {
+	var fails int32 = 1
+	warmingUp := ProviderFunc(func(context.Context) (GasPrices, error) {
+		if atomic.LoadInt32(&fails) == 1 {
+			return GasPrices{}, errors.New("cold start")
+		}
+		return GasPrices{Fast: big.NewInt(2)}, nil
+	})
+	c, err := NewClient(WithProvider(countingProvider(0, nil, new(int32))), WithProviderSwapGrace(time.Hour))
+	require.NoError(t, err)
+
+	// 1. without a previous fetch there is nothing to serve
+	require.NoError(t, c.SetProvider(warmingUp))
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+
+	// 2. failures of the new provider serve the prices of the previous one during the grace period
+	require.NoError(t, c.SetProvider(countingProvider(0, nil, new(int32))))
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	require.NoError(t, c.SetProvider(warmingUp))
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+
+	// 3. the grace period ends once the new provider succeeds
+	atomic.StoreInt32(&fails, 0)
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "2", price.String())
+	atomic.StoreInt32(&fails, 1)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+
+	// 4. or once it expires
+	c, err = NewClient(WithProvider(countingProvider(0, nil, new(int32))), WithProviderSwapGrace(time.Millisecond))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	require.NoError(t, c.SetProvider(warmingUp))
+	time.Sleep(5 * time.Millisecond)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+
+	// 5. the grace period must be positive
+	_, err = NewClient(WithProviderSwapGrace(0))
+	assert.Error(t, err)
+}
+
+func TestWithAsyncRefresh(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n > 1 {
+			<-release
+		}
+		return GasPrices{Fast: big.NewInt(int64(n))}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(10*time.Millisecond), WithAsyncRefresh())
+	require.NoError(t, err)
+
+	// 1. the first call waits for prices
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "1", price.String())
+
+	// 2. stale prices are served immediately, regardless of their age, with one refresh at a time
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		price, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+		assert.Equal(t, "1", price.String())
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 3. the refreshed prices are served once loaded
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	current, _, ok := c.CachedPrices()
+	require.True(t, ok)
+	assert.Equal(t, "2", current.Fast.String())
+
+	// 4. async refresh requires caching and can't be combined with a max staleness
+	_, err = NewClient(WithAsyncRefresh())
+	assert.Error(t, err)
+	_, err = NewClient(WithMaxResultAge(time.Second), WithMaxStaleness(time.Minute), WithAsyncRefresh())
+	assert.Error(t, err)
+}
+
+func TestWithMaxServeAge(t *testing.T) {
+	var offset int64
+	start := time.Now()
+	now := func() time.Time {
+		return start.Add(time.Duration(atomic.LoadInt64(&offset)))
+	}
+
+	var calls, fail int32
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&fail) == 1 {
+			return GasPrices{}, errors.New("refresh failed")
+		}
+		return GasPrices{Fast: big.NewInt(int64(n))}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(time.Minute), WithAsyncRefresh(),
+		WithMaxServeAge(time.Hour), WithNowFunc(now))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+
+	// 1. stale prices within the max serve age are served
+	atomic.StoreInt32(&fail, 1)
+	atomic.StoreInt64(&offset, int64(30*time.Minute))
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "1", price.String())
+
+	// 2. older prices are an error, by every read, and are still refreshed in the background
+	atomic.StoreInt64(&offset, int64(2*time.Hour))
+	time.Sleep(10 * time.Millisecond)
+	refreshes := atomic.LoadInt32(&calls)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrValueTooOld))
+	_, _, err = c.SuggestGasPriceOrStale(context.Background(), GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrValueTooOld))
+	time.Sleep(10 * time.Millisecond)
+	assert.Greater(t, atomic.LoadInt32(&calls), refreshes)
+
+	// 3. a successful refresh serves prices again
+	atomic.StoreInt32(&fail, 0)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+
+	// 4. the prices of a refresher are limited too, as measured by the clock of the client
+	refresher, err := c.NewRefresher(context.Background(), time.Hour, WithWarmOnStart(true))
+	require.NoError(t, err)
+	defer refresher.Stop()
+	atomic.AddInt64(&offset, int64(59*time.Minute))
+	_, err = refresher.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+	atomic.AddInt64(&offset, int64(2*time.Minute))
+	_, err = refresher.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrValueTooOld))
+
+	// 5. the max serve age must be positive and not less than the max result age
+	_, err = NewClient(WithMaxServeAge(0))
+	assert.Error(t, err)
+	_, err = NewClient(WithMaxResultAge(time.Hour), WithMaxServeAge(time.Minute))
+	assert.Error(t, err)
+}
+
+type requestIDKey struct{}
+
+func TestBackgroundRefreshContextValues(t *testing.T) {
+	type refresh struct {
+		requestID interface{}
+		err       error
+		failFast  bool
+	}
+	var calls int32
+	refreshed := make(chan refresh, 1)
+	provider := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			refreshed <- refresh{requestID: ctx.Value(requestIDKey{}), err: ctx.Err(), failFast: isFailFast(ctx)}
+		}
+		return GasPrices{Fast: big.NewInt(20e9)}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(time.Millisecond), WithAsyncRefresh())
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	// 1. the refresh started by a call carries its values, but outlives it and doesn't inherit fail fast mode
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestIDKey{}, "req-1"))
+	_, err = c.SuggestGasPriceContext(ContextWithFailFast(ctx), GasPriorityFast)
+	require.NoError(t, err)
+	cancel()
+
+	r := <-refreshed
+	assert.Equal(t, "req-1", r.requestID)
+	assert.NoError(t, r.err)
+	assert.False(t, r.failFast)
+}
+
+func TestWithResponseCharsetHandling(t *testing.T) {
+	// decodeUTF16LE stands in for a general purpose charset reader such as charset.NewReaderLabel
+	decodeUTF16LE := func(charset string, input io.Reader) (io.Reader, error) {
+		if charset != "utf-16le" {
+			return nil, errors.New("unsupported charset")
+		}
+		raw, err := ioutil.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		units := make([]uint16, len(raw)/2)
+		for i := range units {
+			units[i] = uint16(raw[2*i]) | uint16(raw[2*i+1])<<8
+		}
+		return strings.NewReader(string(utf16.Decode(units))), nil
+	}
+	serveCharset := func(charset string, body []byte) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset="+charset)
+			_, _ = w.Write(body)
+		}
+	}
+
+	var encoded []byte
+	for _, unit := range utf16.Encode([]rune(testResponse)) {
+		encoded = append(encoded, byte(unit), byte(unit>>8))
+	}
+
+	charsetHandling := WithResponseCharsetHandling(decodeUTF16LE)
+
+	// 1. a body in a declared charset is converted before it is decoded
+	c, closeServer := newTestClient(t, serveCharset("UTF-16LE", encoded), charsetHandling)
+	defer closeServer()
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+
+	// 2. UTF-8 bodies are decoded without the charset reader
+	c, closeServer = newTestClient(t, serveCharset("utf-8", []byte(testResponse)), charsetHandling)
+	defer closeServer()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+
+	// 3. a charset the reader doesn't support fails the request
+	c, closeServer = newTestClient(t, serveCharset("koi8-r", []byte(testResponse)), charsetHandling)
+	defer closeServer()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+
+	// 4. the charset reader must be set
+	_, err = NewClient(WithResponseCharsetHandling(nil))
+	assert.Error(t, err)
+}
+
+func TestWithResponseEnvelopePath(t *testing.T) {
+	serveBody := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}
+	}
+
+	// 1. the gas object is decoded from within the envelope
+	c, closeServer := newTestClient(t, serveBody(`{"status": "ok", "data": `+testResponse+`}`),
+		WithResponseEnvelopePath("data"))
+	defer closeServer()
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+
+	// 2. nested paths are followed
+	c, closeServer = newTestClient(t, serveBody(`{"result": {"gas": `+testResponse+`}}`),
+		WithResponseEnvelopePath("result.gas"))
+	defer closeServer()
+	price, err = c.SuggestGasPrice(GasPrioritySafeLow)
+	require.NoError(t, err)
+	assert.Equal(t, "10000000000", price.String())
+
+	// 3. responses that don't match the path are rejected
+	for _, body := range []string{testResponse, `{"result": []}`, `{"result": {"price": 1}}`} {
+		c, closeServer = newTestClient(t, serveBody(body), WithResponseEnvelopePath("result.gas"))
+		defer closeServer()
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		assert.Error(t, err, body)
+	}
+
+	// 4. paths with empty field names are rejected
+	for _, path := range []string{".", "data.", "a..b"} {
+		_, err = NewClient(WithResponseEnvelopePath(path))
+		assert.Error(t, err, path)
+	}
+}
+
+func TestWithFieldMapping(t *testing.T) {
+	serveBody := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}
+	}
+	remapped := `{"data": {"rapid": 300.0, "fastest": 250.0, "safeLow": 100.0, "standard": "120.5", "fast": 1.0}}`
+	mapping := map[GasPriority]string{GasPriorityFast: "rapid", GasPriorityAverage: "standard"}
+
+	// 1. mapped levels are decoded from their fields, other levels from the default fields
+	c, closeServer := newTestClient(t, serveBody(remapped), WithResponseEnvelopePath("data"), WithFieldMapping(mapping))
+	defer closeServer()
+	prices, err := c.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, "30000000000", prices.Fast.String())
+	assert.Equal(t, "12050000000", prices.Average.String())
+	assert.Equal(t, "25000000000", prices.Fastest.String())
+	assert.Equal(t, "120.5", prices.Raw[GasPriorityAverage])
+
+	// 2. responses missing a mapped field, or with a field that isn't a number, are rejected
+	for _, body := range []string{testResponse, `{"rapid": "fast", "standard": 1}`} {
+		c, closeServer = newTestClient(t, serveBody(body), WithFieldMapping(mapping))
+		defer closeServer()
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		assert.Error(t, err, body)
+	}
+
+	// 3. unknown priorities and empty field names are rejected
+	for _, invalid := range []map[GasPriority]string{{GasPriorityTurbo: "turbo"}, {GasPriorityFast: ""}} {
+		_, err = NewClient(WithFieldMapping(invalid))
+		assert.Error(t, err)
+	}
+}
+
+func TestClientSnapshot(t *testing.T) {
+	var calls int32
+	c, err := NewClient(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(time.Minute))
+	require.NoError(t, err)
+
+	// 1. the snapshot is taken from the cached response
+	snapshot, err := c.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", snapshot.Fast.String())
+	_, err = c.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 2. the snapshot is a copy that doesn't change with the cache
+	snapshot.Fast.SetInt64(0)
+	current, _, ok := c.CachedPrices()
+	require.True(t, ok)
+	assert.Equal(t, "20000000000", current.Fast.String())
+
+	// 3. errors loading prices are returned
+	c, err = NewClient(WithProvider(countingProvider(1, errors.New("unavailable"), new(int32))))
+	require.NoError(t, err)
+	_, err = c.Snapshot()
+	assert.Error(t, err)
+}
+
+func TestWithResultTTLJitter(t *testing.T) {
+	// 1. the max result age is varied within the fraction
+	ages := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		c, err := NewClient(WithMaxResultAge(time.Minute), WithResultTTLJitter(0.1))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, int64(c.cache.maxResultAge), int64(54*time.Second))
+		assert.LessOrEqual(t, int64(c.cache.maxResultAge), int64(66*time.Second))
+		ages[c.cache.maxResultAge] = true
+	}
+	assert.Greater(t, len(ages), 1, "clients should draw different ages")
+
+	// 2. the jittered age is used by the per-priority cache and never exceeds the max staleness
+	c, err := NewClient(
+		WithMaxResultAge(time.Minute),
+		WithMaxStaleness(time.Minute),
+		WithResultTTLJitter(0.5),
+		WithPerPriorityCache(),
+	)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, int64(c.cache.maxResultAge), int64(time.Minute))
+	assert.Equal(t, c.cache.maxResultAge, c.priorityCache.maxResultAge)
+
+	// 3. invalid fractions and clients that don't cache are rejected
+	for _, fraction := range []float64{-0.1, 1, math.NaN()} {
+		_, err = NewClient(WithMaxResultAge(time.Minute), WithResultTTLJitter(fraction))
+		assert.Error(t, err)
+	}
+	_, err = NewClient(WithResultTTLJitter(0.1))
+	assert.Error(t, err)
+}
+
+func TestWithBlockTimeCap(t *testing.T) {
+	var offset int64
+	start := time.Now()
+	now := func() time.Time {
+		return start.Add(time.Duration(atomic.LoadInt64(&offset)))
+	}
+	var calls int32
+	blockTime := 12 * time.Second
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		atomic.AddInt32(&calls, 1)
+		return GasPrices{Fast: big.NewInt(20e9), BlockTime: blockTime}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(time.Minute), WithBlockTimeCap(), WithNowFunc(now))
+	require.NoError(t, err)
+
+	// 1. reads within a block are served from the cache
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	atomic.StoreInt64(&offset, int64(blockTime))
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 2. prices older than a block are fetched again, despite the longer max result age
+	atomic.StoreInt64(&offset, int64(blockTime+time.Second))
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 3. a shorter max result age is kept, as is the max result age of prices without a block time
+	c, err = NewClient(WithProvider(provider), WithMaxResultAge(time.Second), WithBlockTimeCap())
+	require.NoError(t, err)
+	c.cache.latestPrices = GasPrices{BlockTime: blockTime}
+	assert.Equal(t, time.Second, c.cache.maxAge())
+	c.cache.maxResultAge = time.Minute
+	c.cache.latestPrices = GasPrices{}
+	assert.Equal(t, time.Minute, c.cache.maxAge())
+
+	// 4. clients that don't cache are rejected
+	_, err = NewClient(WithBlockTimeCap())
+	assert.Error(t, err)
+}
+
+func TestClientGasPriceOptions(t *testing.T) {
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+
+	options, err := c.GasPriceOptions()
+	require.NoError(t, err)
+	require.Len(t, options, 4)
+	assert.Equal(t, GasPrioritySafeLow, options[0].Priority)
+	assert.Equal(t, "10000000000", options[0].PriceWei.String())
+	assert.Equal(t, GasPriorityFastest, options[3].Priority)
+	assert.Equal(t, "25000000000", options[3].PriceWei.String())
+}
+
+func TestSuggestGasPriceDetails(t *testing.T) {
+	c, stop := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"fast": 450, "fastest": 500, "safeLow": 100, "average": 150, "fastWait": 5}`))
+	})
+	defer stop()
+
+	// 1. the price comes with the wait estimate of its level
+	details, err := c.SuggestGasPriceDetails(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, GasPriorityFast, details.Priority)
+	assert.Equal(t, "45000000000", details.PriceWei.String())
+	assert.Equal(t, 30*time.Second, details.EstimatedWait)
+	assert.True(t, math.IsNaN(details.Confidence))
+
+	// 2. levels without a wait estimate have a zero wait, and unknown levels are rejected
+	details, err = c.SuggestGasPriceDetails(GasPrioritySafeLow)
+	require.NoError(t, err)
+	assert.Zero(t, details.EstimatedWait)
+	_, err = c.SuggestGasPriceDetails(GasPriority("slow"))
+	assert.Error(t, err)
+}
+
+func TestCacheStateTransitions(t *testing.T) {
+	var offset int64
+	start := time.Now()
+	now := func() time.Time {
+		return start.Add(time.Duration(atomic.LoadInt64(&offset)))
+	}
+
+	var calls, fail int32
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&fail) == 1 {
+			return GasPrices{}, errors.New("failure")
+		}
+		return GasPrices{Fast: big.NewInt(int64(n))}, nil
+	})
+	c, err := NewClient(
+		WithProvider(provider),
+		WithMaxResultAge(time.Minute),
+		WithMaxStaleness(5*time.Minute),
+		WithNowFunc(now),
+	)
+	require.NoError(t, err)
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		fail    bool
+
+		// price is the price returned by the call, or empty if it returns an error
+		price string
+		// calls is the number of calls made to the provider, including background refreshes, after the step
+		calls int32
+		// state is the state of the cache once any background refresh is done
+		state cacheState
+	}{
+		{name: "empty cache fails", fail: true, price: "", calls: 1, state: cacheEmpty},
+		{name: "empty cache loads", price: "2", calls: 2, state: cacheFresh},
+		{name: "fresh until the max result age", advance: time.Minute, price: "2", calls: 2, state: cacheFresh},
+		{name: "stale served while refresh fails", advance: time.Second, fail: true, price: "2", calls: 3, state: cacheStale},
+		{name: "stale served, refresh succeeds", advance: 59 * time.Second, price: "2", calls: 4, state: cacheFresh},
+		{name: "refreshed prices served", price: "4", calls: 4, state: cacheFresh},
+		{name: "stale up to max staleness", advance: 5 * time.Minute, fail: true, price: "4", calls: 5, state: cacheStale},
+		{name: "expired cache fails", advance: time.Second, fail: true, price: "", calls: 6, state: cacheExpired},
+		{name: "expired cache loads", price: "7", calls: 7, state: cacheFresh},
+	}
+	for _, step := range steps {
+		atomic.AddInt64(&offset, int64(step.advance))
+		if step.fail {
+			atomic.StoreInt32(&fail, 1)
+		} else {
+			atomic.StoreInt32(&fail, 0)
+		}
+
+		price, err := c.SuggestGasPrice(GasPriorityFast)
+		if step.price == "" {
+			assert.Error(t, err, step.name)
+		} else if assert.NoError(t, err, step.name) {
+			assert.Equal(t, step.price, price.String(), step.name)
+		}
+
+		// wait for any background refresh started by the call
+		require.Eventually(t, func() bool {
+			c.cache.Lock()
+			defer c.cache.Unlock()
+			return !c.cache.refreshing
+		}, time.Second, time.Millisecond, step.name)
+
+		assert.Equal(t, step.calls, atomic.LoadInt32(&calls), step.name)
+		c.cache.Lock()
+		assert.Equal(t, step.state, c.cache.state(), step.name)
+		c.cache.Unlock()
+	}
+
+	// suggesters of the client use its clock too
+	var suggesterCalls int32
+	clock := start
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &suggesterCalls)),
+		WithNowFunc(func() time.Time { return clock }))
+	require.NoError(t, err)
+	suggester, err := c.NewGasPriceSuggesterContext(context.Background(), time.Minute)
+	require.NoError(t, err)
+	for _, advance := range []time.Duration{0, time.Minute, time.Second} {
+		clock = clock.Add(advance)
+		_, err = suggester(context.Background(), GasPriorityFast)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&suggesterCalls))
+
+	// the clock must be set
+	_, err = NewClient(WithNowFunc(nil))
+	assert.Error(t, err)
+}
+
+func TestZeroMaxResultAge(t *testing.T) {
+	var calls int32
+	start := time.Now()
+	now := func() time.Time {
+		return start
+	}
+
+	// 1. a zero max age loads new prices on every call, even if the clock doesn't advance
+	c, err := NewClient(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(0), WithNowFunc(now))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), calls)
+
+	// 2. so does a suggester
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &calls)))
+	require.NoError(t, err)
+	suggester, err := c.NewGasPriceSuggester(0)
+	require.NoError(t, err)
+	_, err = suggester(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), calls)
+
+	// 3. negative max ages are rejected
+	_, err = NewClient(WithMaxResultAge(-time.Second))
+	assert.Error(t, err)
+	_, err = NewGasPriceSuggester(-time.Second)
+	assert.Error(t, err)
+	_, err = NewClientFromConfig(Config{MaxResultAge: -time.Second})
+	assert.Error(t, err)
+}
+
+func TestWithDefaultPriority(t *testing.T) {
+	// 1. Suggest uses the fast priority by default
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+	assert.Equal(t, GasPriorityFast, c.DefaultPriority())
+	price, err := c.Suggest()
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+
+	// 2. the configured priority is used instead
+	c, stop = newTestClient(t, serveTestResponse, WithDefaultPriority(GasPriorityAverage))
+	defer stop()
+	price, err = c.Suggest()
+	require.NoError(t, err)
+	assert.Equal(t, "15000000000", price.String())
+
+	// 3. the derived turbo level can be the default
+	c, stop = newTestClient(t, serveTestResponse, WithDefaultPriority(GasPriorityTurbo))
+	defer stop()
+	price, err = c.Suggest()
+	require.NoError(t, err)
+	assert.Equal(t, "22500000000", price.String())
+
+	// 4. unknown priorities are rejected
+	_, err = NewClient(WithDefaultPriority(GasPriority("foo")))
+	assert.Error(t, err)
+}
+
+func TestWithLocalAddr(t *testing.T) {
+	var remoteAddr atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr.Store(r.RemoteAddr)
+		serveTestResponse(w, r)
+	}))
+	defer server.Close()
+
+	// 1. requests originate from the local address
+	c, err := NewClient(WithURL(server.URL), WithLocalAddr("127.0.0.1"), WithIPv4Only())
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(remoteAddr.Load().(string), "127.0.0.1:"))
+
+	// 2. the dialer settings can't be combined with a custom http client
+	_, err = NewClient(WithLocalAddr("127.0.0.1"), WithHTTPClient(http.DefaultClient))
+	assert.Error(t, err)
+	_, err = NewClient(WithIPv4Only(), WithHTTPClient(http.DefaultClient))
+	assert.Error(t, err)
+
+	// 3. the local address must be an ip address
+	_, err = NewClient(WithLocalAddr("localhost"))
+	assert.Error(t, err)
+}
+
+func TestWithSystemProxy(t *testing.T) {
+	usesSystemProxy := func(c *Client) bool {
+		transport, ok := c.httpClient.Transport.(*http.Transport)
+		return ok && reflect.ValueOf(transport.Proxy).Pointer() == reflect.ValueOf(http.ProxyFromEnvironment).Pointer()
+	}
+
+	// 1. the default transport uses the proxy of the environment
+	c, err := NewClient(WithSystemProxy())
+	require.NoError(t, err)
+	assert.True(t, usesSystemProxy(c))
+
+	// 2. a custom transport is cloned with its proxy replaced, leaving the custom client unchanged
+	direct := &http.Transport{}
+	httpClient := &http.Client{Transport: direct, Timeout: time.Second}
+	c, err = NewClient(WithHTTPClient(httpClient), WithSystemProxy())
+	require.NoError(t, err)
+	assert.True(t, usesSystemProxy(c))
+	assert.Equal(t, time.Second, c.httpClient.Timeout)
+	assert.Nil(t, direct.Proxy)
+	assert.Equal(t, direct, httpClient.Transport)
+
+	// 3. so is the transport built for a local address
+	c, err = NewClient(WithLocalAddr("127.0.0.1"), WithSystemProxy())
+	require.NoError(t, err)
+	assert.True(t, usesSystemProxy(c))
+
+	// 4. a transport whose proxy can't be set is rejected
+	_, err = NewClient(WithHTTPClient(&http.Client{Transport: testTransport{}}), WithSystemProxy())
+	assert.Error(t, err)
+}
+
+func TestWithDisableKeepAlives(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(serveTestResponse))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	// 1. each request uses a new connection
+	c, err := NewClient(WithURL(server.URL), WithDisableKeepAlives())
+	require.NoError(t, err)
+	defer c.Close()
+	for i := 0; i < 2; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&conns))
+	assert.True(t, c.httpClient.Transport.(*http.Transport).DisableKeepAlives)
+
+	// 2. the transport of a custom client is cloned, and can be combined with the system proxy
+	custom := &http.Transport{}
+	c, err = NewClient(WithHTTPClient(&http.Client{Transport: custom}), WithDisableKeepAlives(), WithSystemProxy())
+	require.NoError(t, err)
+	transport := c.httpClient.Transport.(*http.Transport)
+	assert.True(t, transport.DisableKeepAlives)
+	assert.NotNil(t, transport.Proxy)
+	assert.False(t, custom.DisableKeepAlives)
+}
+
+func TestClientSuggestGasPriceOrStale(t *testing.T) {
+	var offset int64
+	start := time.Now()
+	now := func() time.Time {
+		return start.Add(time.Duration(atomic.LoadInt64(&offset)))
+	}
+
+	var calls, fail int32
+	provider := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&fail) == 1 {
+			<-ctx.Done()
+			return GasPrices{}, &FetchError{Err: ctx.Err()}
+		}
+		return GasPrices{Fast: big.NewInt(int64(n))}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(time.Minute), WithNowFunc(now))
+	require.NoError(t, err)
+
+	// 1. nothing is cached yet, so a failed load is an error
+	atomic.StoreInt32(&fail, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = c.SuggestGasPriceOrStale(ctx, GasPriorityFast)
+	assert.Error(t, err)
+
+	// 2. fresh prices are not stale
+	atomic.StoreInt32(&fail, 0)
+	price, stale, err := c.SuggestGasPriceOrStale(context.Background(), GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "2", price.String())
+	assert.False(t, stale)
+
+	// 3. expired prices are returned as stale if the refresh is canceled, however old they are
+	atomic.StoreInt64(&offset, int64(time.Hour))
+	atomic.StoreInt32(&fail, 1)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	price, stale, err = c.SuggestGasPriceOrStale(ctx, GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "2", price.String())
+	assert.True(t, stale)
+
+	// 4. a successful refresh replaces them
+	atomic.StoreInt32(&fail, 0)
+	price, stale, err = c.SuggestGasPriceOrStale(context.Background(), GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "4", price.String())
+	assert.False(t, stale)
+
+	// 5. a closed client returns an error rather than stale prices
+	atomic.StoreInt64(&offset, int64(2*time.Hour))
+	require.NoError(t, c.Close())
+	_, _, err = c.SuggestGasPriceOrStale(context.Background(), GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrClientClosed))
+}
+
+func TestClientSuggestGasPriceInto(t *testing.T) {
+	c, stop := newTestClient(t, serveTestResponse, WithMaxResultAge(time.Minute))
+	defer stop()
+
+	// 1. the price is written to dst, including the derived turbo level
+	dst := big.NewInt(1)
+	require.NoError(t, c.SuggestGasPriceInto(dst, GasPriorityFast))
+	assert.Equal(t, "20000000000", dst.String())
+	require.NoError(t, c.SuggestGasPriceInto(dst, GasPriorityTurbo))
+	assert.Equal(t, "22500000000", dst.String())
+
+	// 2. dst doesn't alias the cached prices
+	dst.SetInt64(0)
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+
+	// 3. dst is left unchanged on errors, and must be set
+	assert.Error(t, c.SuggestGasPriceInto(dst, GasPriority("unknown")))
+	assert.Equal(t, "0", dst.String())
+	assert.Error(t, c.SuggestGasPriceInto(nil, GasPriorityFast))
+
+	// 4. cache hits don't allocate
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = c.SuggestGasPriceInto(dst, GasPriorityFast)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func BenchmarkClientSuggestGasPrice(b *testing.B) {
+	c, err := NewClient(WithProvider(countingProvider(0, nil, new(int32))), WithMaxResultAge(time.Hour))
+	require.NoError(b, err)
+
+	b.Run("Allocating", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.SuggestGasPrice(GasPriorityFast); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Into", func(b *testing.B) {
+		dst := new(big.Int)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := c.SuggestGasPriceInto(dst, GasPriorityFast); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+)
+
+// CompareProviders loads prices from both providers concurrently and returns the relative difference of each priority
+// level, (a - b) / b, so a positive difference means a is higher than b. Use it to monitor a primary provider a for
+// drift against a reference provider b. Priority levels that are missing from either response are omitted, and a level
+// that is zero in b but not in a has an infinite difference.
+//
+// An error is returned if either provider fails.
+func CompareProviders(ctx context.Context, a, b Provider) (map[GasPriority]*big.Float, error) {
+	var (
+		reference    GasPrices
+		referenceErr error
+		done         = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		reference, referenceErr = b.Fetch(ctx)
+	}()
+	prices, err := a.Fetch(ctx)
+	<-done
+	if err != nil {
+		return nil, err
+	}
+	if referenceErr != nil {
+		return nil, referenceErr
+	}
+
+	differences := make(map[GasPriority]*big.Float, len(priorityOrder))
+	for _, priority := range priorityOrder {
+		price, err := prices.price(priority)
+		if err != nil {
+			continue
+		}
+		referencePrice, err := reference.price(priority)
+		if err != nil {
+			continue
+		}
+		differences[priority] = relativeDifference(price, referencePrice)
+	}
+	return differences, nil
+}
+
+// PriceDifference is the relative difference of the price of a priority level between two providers.
+type PriceDifference struct {
+	Priority   GasPriority
+	Difference *big.Float
+}
+
+// CompareProvidersOrdered is like CompareProviders, but returns the differences as a slice ordered from the cheapest
+// to the fastest priority level, for output that is stable across runs.
+func CompareProvidersOrdered(ctx context.Context, a, b Provider) ([]PriceDifference, error) {
+	differences, err := CompareProviders(ctx, a, b)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]PriceDifference, 0, len(differences))
+	for _, priority := range priorityOrder {
+		if difference, ok := differences[priority]; ok {
+			ordered = append(ordered, PriceDifference{Priority: priority, Difference: difference})
+		}
+	}
+	return ordered, nil
+}
+
+// relativeDifference returns (a - b) / b, which is zero if both are zero and infinite if only b is
+func relativeDifference(a, b *big.Int) *big.Float {
+	if b.Sign() == 0 {
+		if a.Sign() == 0 {
+			return new(big.Float)
+		}
+		return new(big.Float).SetInf(a.Sign() < 0)
+	}
+	difference := new(big.Float).SetInt(new(big.Int).Sub(a, b))
+	return difference.Quo(difference, new(big.Float).SetInt(b))
+}
@@ -0,0 +1,76 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareProviders(t *testing.T) {
+	primary := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(110), SafeLow: big.NewInt(45), Average: big.NewInt(0), Fastest: big.NewInt(1)}, nil
+	})
+	reference := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(100), SafeLow: big.NewInt(50), Average: big.NewInt(0), Fastest: big.NewInt(0)}, nil
+	})
+
+	// 1. each priority level is compared relative to the reference
+	differences, err := CompareProviders(context.Background(), primary, reference)
+	require.NoError(t, err)
+	fast, _ := differences[GasPriorityFast].Float64()
+	assert.InDelta(t, 0.1, fast, 1e-9)
+	safeLow, _ := differences[GasPrioritySafeLow].Float64()
+	assert.InDelta(t, -0.1, safeLow, 1e-9)
+
+	// 2. zero prices in the reference don't divide by zero
+	assert.Equal(t, 0, differences[GasPriorityAverage].Sign())
+	assert.True(t, differences[GasPriorityFastest].IsInf())
+
+	// 3. levels missing from either response are omitted
+	partial := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(100)}, nil
+	})
+	differences, err = CompareProviders(context.Background(), primary, partial)
+	require.NoError(t, err)
+	assert.Len(t, differences, 1)
+
+	// 4. a failure of either provider is returned
+	failing := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{}, errors.New("unavailable")
+	})
+	_, err = CompareProviders(context.Background(), primary, failing)
+	assert.Error(t, err)
+	_, err = CompareProviders(context.Background(), failing, reference)
+	assert.Error(t, err)
+}
+
+func TestCompareProvidersOrdered(t *testing.T) {
+	primary := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(110), SafeLow: big.NewInt(45), Fastest: big.NewInt(1)}, nil
+	})
+	reference := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(100), SafeLow: big.NewInt(50), Fastest: big.NewInt(1)}, nil
+	})
+
+	// 1. differences are ordered from the cheapest to the fastest level, skipping missing levels
+	for i := 0; i < 10; i++ {
+		differences, err := CompareProvidersOrdered(context.Background(), primary, reference)
+		require.NoError(t, err)
+		require.Len(t, differences, 3)
+		assert.Equal(t, GasPrioritySafeLow, differences[0].Priority)
+		assert.Equal(t, GasPriorityFast, differences[1].Priority)
+		assert.Equal(t, GasPriorityFastest, differences[2].Priority)
+		assert.Equal(t, 0, differences[2].Difference.Sign())
+	}
+
+	// 2. a failure of either provider is returned
+	failing := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{}, errors.New("unavailable")
+	})
+	_, err := CompareProvidersOrdered(context.Background(), primary, failing)
+	assert.Error(t, err)
+}
@@ -0,0 +1,284 @@
+package gas
+
+import "time"
+
+// Config is an alternative to functional options for configuring a Client, for users who load their configuration
+// from a file. Each field mirrors an option, and leaving a field at its zero value keeps the default of that option.
+//
+// Durations are encoded as integer nanoseconds in JSON. Options that take functions or other values that can't be
+// loaded from a file, such as WithProvider, can be passed to NewClientFromConfig alongside the Config.
+type Config struct {
+	// Name mirrors WithName.
+	Name string `json:"name"`
+
+	// ChainID mirrors WithChainID.
+	ChainID uint64 `json:"chainId"`
+
+	// RequireTLS mirrors WithRequireTLS if it is set.
+	RequireTLS *bool `json:"requireTLS"`
+
+	// NativeTokenDecimals mirrors WithNativeTokenDecimals if it is set.
+	NativeTokenDecimals *int `json:"nativeTokenDecimals"`
+
+	// URL and APIKey mirror WithURL and WithAPIKey.
+	URL    string `json:"url"`
+	APIKey string `json:"apiKey"`
+
+	// APIKeys and KeyCooldown mirror WithKeys and WithKeyCooldown.
+	APIKeys     []string      `json:"apiKeys"`
+	KeyCooldown time.Duration `json:"keyCooldown"`
+
+	// FreeEndpointFallback mirrors WithFreeEndpointFallback.
+	FreeEndpointFallback bool `json:"freeEndpointFallback"`
+
+	// LocalAddr, IPv4Only, SystemProxy and DisableKeepAlives mirror WithLocalAddr, WithIPv4Only, WithSystemProxy and
+	// WithDisableKeepAlives.
+	LocalAddr         string `json:"localAddr"`
+	IPv4Only          bool   `json:"ipv4Only"`
+	SystemProxy       bool   `json:"systemProxy"`
+	DisableKeepAlives bool   `json:"disableKeepAlives"`
+
+	// InputScale mirrors WithInputScale.
+	InputScale InputScale `json:"inputScale"`
+
+	// ResponseEnvelopePath mirrors WithResponseEnvelopePath.
+	ResponseEnvelopePath string `json:"responseEnvelopePath"`
+
+	// FieldMapping mirrors WithFieldMapping.
+	FieldMapping map[GasPriority]string `json:"fieldMapping"`
+
+	// StrictSchema and AllowedFields mirror WithStrictSchema and WithAllowedFields.
+	StrictSchema  bool     `json:"strictSchema"`
+	AllowedFields []string `json:"allowedFields"`
+
+	// Timeout mirrors WithTimeout, and RefreshTimeout mirrors WithRefreshTimeout.
+	Timeout        time.Duration `json:"timeout"`
+	RefreshTimeout time.Duration `json:"refreshTimeout"`
+
+	// Retries and RetryBackoff mirror WithRetry, and RetryableStatusCodes mirrors WithRetryableStatus used with
+	// RetryableStatusCodes.
+	Retries              int           `json:"retries"`
+	RetryBackoff         time.Duration `json:"retryBackoff"`
+	RetryableStatusCodes []int         `json:"retryableStatusCodes"`
+
+	// MaxRetryElapsed mirrors WithMaxRetryElapsed.
+	MaxRetryElapsed time.Duration `json:"maxRetryElapsed"`
+
+	// FailFast mirrors WithFailFast.
+	FailFast bool `json:"failFast"`
+
+	// ErrorTiming mirrors WithErrorTiming.
+	ErrorTiming bool `json:"errorTiming"`
+
+	// MaxResultAge mirrors WithMaxResultAge, the client only caches if it is positive and a negative age is rejected.
+	MaxResultAge time.Duration `json:"maxResultAge"`
+
+	// ResultTTLJitter mirrors WithResultTTLJitter.
+	ResultTTLJitter float64 `json:"resultTTLJitter"`
+
+	// MaxStaleness mirrors WithMaxStaleness.
+	MaxStaleness time.Duration `json:"maxStaleness"`
+
+	// MaxServeAge mirrors WithMaxServeAge.
+	MaxServeAge time.Duration `json:"maxServeAge"`
+
+	// PerPriorityCache mirrors WithPerPriorityCache.
+	PerPriorityCache bool `json:"perPriorityCache"`
+
+	// AsyncRefresh mirrors WithAsyncRefresh.
+	AsyncRefresh bool `json:"asyncRefresh"`
+
+	// EventBufferSize mirrors WithEvents, events are only emitted if it is positive and a negative size is rejected.
+	EventBufferSize int `json:"eventBufferSize"`
+
+	// BlockTimeCap mirrors WithBlockTimeCap.
+	BlockTimeCap bool `json:"blockTimeCap"`
+
+	// MaxConcurrentFetches mirrors WithMaxConcurrentFetches, requests are only limited if it is positive.
+	MaxConcurrentFetches int `json:"maxConcurrentFetches"`
+
+	// FetchDedupWindow mirrors WithFetchDedupWindow, fetches are only coalesced if it is positive.
+	FetchDedupWindow time.Duration `json:"fetchDedupWindow"`
+
+	// ErrorCacheTTL mirrors WithErrorCache, failures are only remembered if it is positive.
+	ErrorCacheTTL time.Duration `json:"errorCacheTTL"`
+
+	// ProcessCacheMaxAge mirrors WithProcessCache, prices are only shared if it is positive.
+	ProcessCacheMaxAge time.Duration `json:"processCacheMaxAge"`
+
+	// RoundTo and RoundingMode mirror WithRoundTo and WithRoundingMode.
+	RoundTo      uint64       `json:"roundTo"`
+	RoundingMode RoundingMode `json:"roundingMode"`
+
+	// RejectZeroPrices mirrors WithRejectZeroPrices.
+	RejectZeroPrices bool `json:"rejectZeroPrices"`
+
+	// RejectInvertedWaitTimes mirrors WithRejectInvertedWaitTimes.
+	RejectInvertedWaitTimes bool `json:"rejectInvertedWaitTimes"`
+
+	// MaxPriceChange mirrors WithMaxPriceChange.
+	MaxPriceChange float64 `json:"maxPriceChange"`
+
+	// RejectImplausiblePrices mirrors WithRejectImplausiblePrices, and PlausibleMinGwei and PlausibleMaxGwei mirror
+	// WithPlausibleRange if either is set.
+	RejectImplausiblePrices bool    `json:"rejectImplausiblePrices"`
+	PlausibleMinGwei        float64 `json:"plausibleMinGwei"`
+	PlausibleMaxGwei        float64 `json:"plausibleMaxGwei"`
+
+	// DefaultPriority mirrors WithDefaultPriority.
+	DefaultPriority GasPriority `json:"defaultPriority"`
+
+	// PriorityPercentiles mirrors WithPriorityPercentiles.
+	PriorityPercentiles map[GasPriority]float64 `json:"priorityPercentiles"`
+}
+
+// NewClientFromConfig returns a new Client configured with config, followed by any additional options.
+//
+// An error is returned if any of the configured values are invalid.
+func NewClientFromConfig(config Config, opts ...Option) (*Client, error) {
+	return NewClient(append(config.options(), opts...)...)
+}
+
+// options translates the non-zero fields of the config to options
+func (config Config) options() []Option {
+	var opts []Option
+	if config.Name != "" {
+		opts = append(opts, WithName(config.Name))
+	}
+	if config.ChainID != 0 {
+		opts = append(opts, WithChainID(config.ChainID))
+	}
+	if config.RequireTLS != nil {
+		opts = append(opts, WithRequireTLS(*config.RequireTLS))
+	}
+	if config.NativeTokenDecimals != nil {
+		opts = append(opts, WithNativeTokenDecimals(*config.NativeTokenDecimals))
+	}
+	if config.URL != "" {
+		opts = append(opts, WithURL(config.URL))
+	}
+	if config.APIKey != "" {
+		opts = append(opts, WithAPIKey(config.APIKey))
+	}
+	if config.APIKeys != nil {
+		opts = append(opts, WithKeys(config.APIKeys))
+	}
+	if config.KeyCooldown != 0 {
+		opts = append(opts, WithKeyCooldown(config.KeyCooldown))
+	}
+	if config.FreeEndpointFallback {
+		opts = append(opts, WithFreeEndpointFallback())
+	}
+	if config.LocalAddr != "" {
+		opts = append(opts, WithLocalAddr(config.LocalAddr))
+	}
+	if config.IPv4Only {
+		opts = append(opts, WithIPv4Only())
+	}
+	if config.SystemProxy {
+		opts = append(opts, WithSystemProxy())
+	}
+	if config.DisableKeepAlives {
+		opts = append(opts, WithDisableKeepAlives())
+	}
+	if config.InputScale != InputScaleTenthsOfGwei {
+		opts = append(opts, WithInputScale(config.InputScale))
+	}
+	if config.ResponseEnvelopePath != "" {
+		opts = append(opts, WithResponseEnvelopePath(config.ResponseEnvelopePath))
+	}
+	if len(config.FieldMapping) > 0 {
+		opts = append(opts, WithFieldMapping(config.FieldMapping))
+	}
+	if config.StrictSchema {
+		opts = append(opts, WithStrictSchema())
+	}
+	if len(config.AllowedFields) > 0 {
+		opts = append(opts, WithAllowedFields(config.AllowedFields...))
+	}
+	if config.Timeout != 0 {
+		opts = append(opts, WithTimeout(config.Timeout))
+	}
+	if config.RefreshTimeout != 0 {
+		opts = append(opts, WithRefreshTimeout(config.RefreshTimeout))
+	}
+	if config.Retries != 0 || config.RetryBackoff != 0 {
+		opts = append(opts, WithRetry(config.Retries, config.RetryBackoff))
+	}
+	if config.RetryableStatusCodes != nil {
+		opts = append(opts, WithRetryableStatus(RetryableStatusCodes(config.RetryableStatusCodes...)))
+	}
+	if config.MaxRetryElapsed != 0 {
+		opts = append(opts, WithMaxRetryElapsed(config.MaxRetryElapsed))
+	}
+	if config.FailFast {
+		opts = append(opts, WithFailFast())
+	}
+	if config.ErrorTiming {
+		opts = append(opts, WithErrorTiming())
+	}
+	if config.MaxResultAge != 0 {
+		opts = append(opts, WithMaxResultAge(config.MaxResultAge))
+	}
+	if config.ResultTTLJitter != 0 {
+		opts = append(opts, WithResultTTLJitter(config.ResultTTLJitter))
+	}
+	if config.MaxStaleness != 0 {
+		opts = append(opts, WithMaxStaleness(config.MaxStaleness))
+	}
+	if config.MaxServeAge != 0 {
+		opts = append(opts, WithMaxServeAge(config.MaxServeAge))
+	}
+	if config.PerPriorityCache {
+		opts = append(opts, WithPerPriorityCache())
+	}
+	if config.AsyncRefresh {
+		opts = append(opts, WithAsyncRefresh())
+	}
+	if config.EventBufferSize != 0 {
+		opts = append(opts, WithEvents(config.EventBufferSize))
+	}
+	if config.BlockTimeCap {
+		opts = append(opts, WithBlockTimeCap())
+	}
+	if config.MaxConcurrentFetches > 0 {
+		opts = append(opts, WithMaxConcurrentFetches(config.MaxConcurrentFetches))
+	}
+	if config.FetchDedupWindow > 0 {
+		opts = append(opts, WithFetchDedupWindow(config.FetchDedupWindow))
+	}
+	if config.ErrorCacheTTL > 0 {
+		opts = append(opts, WithErrorCache(config.ErrorCacheTTL))
+	}
+	if config.ProcessCacheMaxAge > 0 {
+		opts = append(opts, WithProcessCache(config.ProcessCacheMaxAge))
+	}
+	if config.RoundTo != 0 {
+		opts = append(opts, WithRoundTo(config.RoundTo))
+	}
+	if config.RoundingMode != RoundNearest {
+		opts = append(opts, WithRoundingMode(config.RoundingMode))
+	}
+	if config.RejectZeroPrices {
+		opts = append(opts, WithRejectZeroPrices())
+	}
+	if config.RejectInvertedWaitTimes {
+		opts = append(opts, WithRejectInvertedWaitTimes())
+	}
+	if config.MaxPriceChange != 0 {
+		opts = append(opts, WithMaxPriceChange(config.MaxPriceChange))
+	}
+	if config.RejectImplausiblePrices {
+		opts = append(opts, WithRejectImplausiblePrices())
+	}
+	if config.PlausibleMinGwei != 0 || config.PlausibleMaxGwei != 0 {
+		opts = append(opts, WithPlausibleRange(config.PlausibleMinGwei, config.PlausibleMaxGwei))
+	}
+	if config.DefaultPriority != "" {
+		opts = append(opts, WithDefaultPriority(config.DefaultPriority))
+	}
+	if config.PriorityPercentiles != nil {
+		opts = append(opts, WithPriorityPercentiles(config.PriorityPercentiles))
+	}
+	return opts
+}
@@ -0,0 +1,79 @@
+package gas
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientFromConfig(t *testing.T) {
+	var config Config
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"name": "mainnet",
+		"url": "https://gas.example.com/prices.json",
+		"apiKey": "secret",
+		"inputScale": 1,
+		"timeout": 5000000000,
+		"retries": 2,
+		"retryBackoff": 100000000,
+		"retryableStatusCodes": [503],
+		"maxResultAge": 60000000000,
+		"roundTo": 1,
+		"roundingMode": 1,
+		"priorityPercentiles": {"fast": 80}
+	}`), &config))
+
+	// 1. every field is translated to its option
+	c, err := NewClientFromConfig(config)
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet", c.Name())
+	assert.Equal(t, InputScaleGwei, c.inputScale)
+	assert.Equal(t, 5*time.Second, c.timeout)
+	assert.Equal(t, 2, c.retries)
+	assert.Equal(t, 100*time.Millisecond, c.retryBackoff)
+	assert.True(t, c.retryableStatus(http.StatusServiceUnavailable))
+	assert.False(t, c.retryableStatus(http.StatusBadGateway))
+	require.NotNil(t, c.cache)
+	assert.Equal(t, time.Minute, c.cache.maxResultAge)
+	assert.Equal(t, 0, c.roundTo.Cmp(big.NewInt(1e9)))
+	assert.Equal(t, RoundUp, c.roundingMode)
+	assert.Equal(t, 80.0, c.PriorityToPercentile(GasPriorityFast))
+
+	provider, ok := c.source().(*ETHGasStationProvider)
+	require.True(t, ok)
+	assert.Equal(t, "https://gas.example.com/prices.json?api-key=secret", provider.url())
+
+	// 2. a zero config uses the defaults
+	c, err = NewClientFromConfig(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, c.cache)
+	assert.Equal(t, ETHGasStationURL, c.source().(*ETHGasStationProvider).url())
+
+	// 3. invalid values are rejected
+	_, err = NewClientFromConfig(Config{Timeout: -time.Second})
+	assert.Error(t, err)
+	_, err = NewClientFromConfig(Config{URL: "not a url"})
+	assert.Error(t, err)
+	_, err = NewClientFromConfig(Config{EventBufferSize: -1})
+	assert.Error(t, err)
+}
+
+func TestETHGasStationProviderURL(t *testing.T) {
+	// 1. the API key uses the keyed endpoint unless a URL is set
+	provider := &ETHGasStationProvider{APIKey: "a b"}
+	assert.Equal(t, keylink+url.QueryEscape("a b"), provider.url())
+
+	// 2. a URL is used as is without a key
+	provider = &ETHGasStationProvider{URL: "https://gas.example.com/prices.json?chain=1"}
+	assert.Equal(t, "https://gas.example.com/prices.json?chain=1", provider.url())
+
+	// 3. the key is added to the query of a URL
+	provider.APIKey = "secret"
+	assert.Equal(t, "https://gas.example.com/prices.json?api-key=secret&chain=1", provider.url())
+}
@@ -0,0 +1,99 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// ConfirmationModel estimates the time for a transaction paying priceWei to be mined, given the prices reported by the
+// provider. It is used by a client configured with WithCustomConfirmationModel instead of the wait times reported by
+// the provider, and must not modify prices.
+type ConfirmationModel func(priceWei *big.Int, prices GasPrices) time.Duration
+
+// EstimateWait returns the estimated time for a transaction paying price in wei to be mined, interpolated linearly
+// between the two nearest prices of the prediction table, or between the priority levels with a wait time if there is
+// no prediction table. A price above the most expensive one has its wait time.
+//
+// An error is returned if there are neither predictions nor wait times, or price is below the cheapest of them.
+func (p GasPrices) EstimateWait(price *big.Int) (time.Duration, error) {
+	if price == nil || price.Sign() < 0 {
+		return 0, errors.New("eth: gas price must not be negative")
+	}
+	predictions := p.Predictions
+	if len(predictions) == 0 {
+		for _, priority := range priorityOrder {
+			levelPrice, err := p.price(priority)
+			if wait, ok := p.Waits[priority]; ok && err == nil {
+				predictions = append(predictions, PricePrediction{Price: levelPrice, Wait: wait})
+			}
+		}
+		sort.SliceStable(predictions, func(i, j int) bool {
+			return predictions[i].Price.Cmp(predictions[j].Price) < 0
+		})
+	}
+	if len(predictions) == 0 {
+		return 0, errors.New("eth: response does not include a prediction table or wait times")
+	}
+
+	// predictions are sorted by ascending price
+	i := sort.Search(len(predictions), func(i int) bool {
+		return predictions[i].Price.Cmp(price) >= 0
+	})
+	switch {
+	case i == len(predictions):
+		return predictions[i-1].Wait, nil
+	case predictions[i].Price.Cmp(price) == 0:
+		return predictions[i].Wait, nil
+	case i == 0:
+		return 0, errors.New("eth: gas price is below the cheapest price with a wait time")
+	}
+	below, above := predictions[i-1], predictions[i]
+	fraction := new(big.Rat).SetFrac(new(big.Int).Sub(price, below.Price), new(big.Int).Sub(above.Price, below.Price))
+	f, _ := fraction.Float64()
+	return below.Wait + time.Duration(f*float64(above.Wait-below.Wait)), nil
+}
+
+// EstimateWait returns the estimated time for a transaction paying price in wei to be mined, from the model set with
+// WithCustomConfirmationModel or otherwise as described for GasPrices.EstimateWait.
+func (c *Client) EstimateWait(price *big.Int) (time.Duration, error) {
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	if c.confirmationModel == nil {
+		return prices.EstimateWait(price)
+	}
+	if price == nil || price.Sign() < 0 {
+		return 0, errors.New("eth: gas price must not be negative")
+	}
+	return c.confirmationModel(new(big.Int).Set(price), prices), nil
+}
+
+// loadWaits loads the prices for a wait-based selector, with their wait times replaced by the confirmation model of
+// the client, if set
+func (c *Client) loadWaits() (GasPrices, error) {
+	prices, err := c.load(context.Background())
+	if err != nil || c.confirmationModel == nil {
+		return prices, err
+	}
+	return prices.withConfirmationModel(c.confirmationModel), nil
+}
+
+// withConfirmationModel returns a copy of p whose predictions and wait times of every priority level with a price are
+// estimated by model
+func (p GasPrices) withConfirmationModel(model ConfirmationModel) GasPrices {
+	modeled := p.copy()
+	for i, prediction := range modeled.Predictions {
+		modeled.Predictions[i].Wait = model(new(big.Int).Set(prediction.Price), p)
+	}
+	modeled.Waits = make(map[GasPriority]time.Duration, len(priorityOrder))
+	for _, priority := range priorityOrder {
+		if price, err := p.Price(priority); err == nil {
+			modeled.Waits[priority] = model(price, p)
+		}
+	}
+	return modeled
+}
@@ -0,0 +1,98 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasPricesEstimateWait(t *testing.T) {
+	prices := GasPrices{
+		Predictions: []PricePrediction{
+			{Price: big.NewInt(10), Wait: 10 * time.Minute},
+			{Price: big.NewInt(20), Wait: 2 * time.Minute},
+			{Price: big.NewInt(30), Wait: time.Minute},
+		},
+		SafeLow: big.NewInt(100),
+		Fast:    big.NewInt(300),
+		Waits:   map[GasPriority]time.Duration{GasPrioritySafeLow: 5 * time.Minute, GasPriorityFast: time.Minute},
+	}
+
+	// 1. prices of the prediction table have its wait times, and prices between them are interpolated
+	wait, err := prices.EstimateWait(big.NewInt(20))
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, wait)
+	wait, err = prices.EstimateWait(big.NewInt(15))
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Minute, wait)
+
+	// 2. prices above the table have its shortest wait, prices below it can't be estimated
+	wait, err = prices.EstimateWait(big.NewInt(1000))
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, wait)
+	_, err = prices.EstimateWait(big.NewInt(5))
+	assert.Error(t, err)
+
+	// 3. without a prediction table, the wait times of the priority levels are interpolated
+	prices.Predictions = nil
+	wait, err = prices.EstimateWait(big.NewInt(200))
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Minute, wait)
+
+	// 4. prices without wait times can't be estimated
+	_, err = GasPrices{Fast: big.NewInt(300)}.EstimateWait(big.NewInt(300))
+	assert.Error(t, err)
+}
+
+func TestWithCustomConfirmationModel(t *testing.T) {
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{
+			SafeLow: big.NewInt(10e9),
+			Average: big.NewInt(15e9),
+			Fast:    big.NewInt(20e9),
+			Fastest: big.NewInt(25e9),
+			Waits:   map[GasPriority]time.Duration{GasPrioritySafeLow: time.Hour, GasPriorityFastest: time.Hour},
+		}, nil
+	})
+	// each gwei above 10 saves a minute off of 20 minutes
+	model := func(price *big.Int, _ GasPrices) time.Duration {
+		return time.Duration(30-new(big.Int).Div(price, big.NewInt(1e9)).Int64()) * time.Minute
+	}
+	c, err := NewClient(WithProvider(provider), WithCustomConfirmationModel(model))
+	require.NoError(t, err)
+
+	// 1. the model estimates the wait of any price
+	wait, err := c.EstimateWait(big.NewInt(12e9))
+	require.NoError(t, err)
+	assert.Equal(t, 18*time.Minute, wait)
+
+	// 2. the wait-based selectors use the model for every level with a price
+	priority, err := c.PriorityForTargetWait(15 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, GasPriorityAverage, priority)
+	params, err := c.FeeParamsForWait(10 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", params.GasPrice.String())
+	assert.Equal(t, 10*time.Minute, params.EstimatedWait)
+	options, err := c.GasPriceOptions()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, options[3].EstimatedWait)
+
+	// 3. without a model, the reported wait times are used
+	c, err = NewClient(WithProvider(provider))
+	require.NoError(t, err)
+	priority, err = c.PriorityForTargetWait(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, GasPrioritySafeLow, priority)
+	wait, err = c.EstimateWait(big.NewInt(12e9))
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, wait)
+
+	// 4. the model must not be nil
+	_, err = NewClient(WithCustomConfirmationModel(nil))
+	assert.Error(t, err)
+}
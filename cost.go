@@ -0,0 +1,103 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// TokenRate is the price of one whole native token, such as one ether, in US dollars.
+type TokenRate struct {
+	// Symbol is the symbol of the token the rate is for, such as "ETH" or "MATIC". If set, it must match the native
+	// token of the chain the client was configured for, so a rate from the wrong market isn't applied by accident.
+	Symbol string
+
+	// USD is the price of one token in US dollars.
+	USD *big.Rat
+}
+
+// EstimateCostUSD returns the cost in US dollars of a transaction using gasLimit gas priced at priority, given the
+// rate of the native token the gas is paid in. Prices are in the smallest unit of the native token, which has 18
+// decimals like ether, use Client.EstimateCostUSD for a token with other decimals.
+func (p GasPrices) EstimateCostUSD(gasLimit uint64, priority GasPriority, rate TokenRate) (*big.Rat, error) {
+	return p.estimateCostUSD(gasLimit, priority, rate, DefaultNativeTokenDecimals)
+}
+
+// estimateCostUSD is like EstimateCostUSD, for prices in the smallest unit of a native token with decimals
+func (p GasPrices) estimateCostUSD(
+	gasLimit uint64,
+	priority GasPriority,
+	rate TokenRate,
+	decimals int,
+) (*big.Rat, error) {
+	if rate.USD == nil || rate.USD.Sign() < 0 {
+		return nil, errors.New("eth: token rate must be set and not negative")
+	}
+	price, err := p.price(priority)
+	if err != nil {
+		return nil, err
+	}
+	cost := new(big.Int).Mul(price, new(big.Int).SetUint64(gasLimit))
+	tokens := scaleDecimals(cost, decimals)
+	return tokens.Mul(tokens, rate.USD), nil
+}
+
+// EstimateCostUSD is like GasPrices.EstimateCostUSD, using the prices of a single response. An error is returned if
+// the symbol of rate doesn't match the native token of the client, ETH unless it was configured for another chain with
+// WithChainID. The cost is converted to whole tokens with the decimals of the native token of the client, see
+// WithNativeTokenDecimals. Unless the client was configured with WithMaxResultAge, it always makes a new call to the
+// provider.
+func (c *Client) EstimateCostUSD(gasLimit uint64, priority GasPriority, rate TokenRate) (*big.Rat, error) {
+	if rate.Symbol != "" && rate.Symbol != c.NativeToken() {
+		return nil, fmt.Errorf("eth: rate is for %s, but gas is paid in %s", rate.Symbol, c.NativeToken())
+	}
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return prices.estimateCostUSD(gasLimit, priority, rate, c.NativeTokenDecimals())
+}
+
+// NativeToken returns the symbol of the native token gas is paid in, as registered for the chain the client was
+// configured for with WithChainID, or ETH.
+func (c *Client) NativeToken() string {
+	if c.nativeToken == "" {
+		return "ETH"
+	}
+	return c.nativeToken
+}
+
+// NativeTokenDecimals returns the number of decimals of the native token gas is paid in, as set with
+// WithNativeTokenDecimals or registered for the chain the client was configured for, or 18.
+func (c *Client) NativeTokenDecimals() int {
+	if c.tokenDecimals == nil {
+		return DefaultNativeTokenDecimals
+	}
+	return *c.tokenDecimals
+}
+
+// configureChain sets the native token of the chain the client is configured for and its decimals, unless they are
+// set, and its provider unless one is set
+func (c *Client) configureChain() error {
+	symbol, err := NativeToken(c.chainID)
+	if err != nil {
+		return err
+	}
+	c.nativeToken = symbol
+	if c.tokenDecimals == nil {
+		decimals := NativeTokenDecimals(c.chainID)
+		c.tokenDecimals = &decimals
+	}
+	if c.provider != nil {
+		return nil
+	}
+	provider, err := ChainProvider(c.chainID)
+	if err != nil {
+		return err
+	}
+	if provider != mainnetProvider {
+		c.provider = provider
+	}
+	return nil
+}
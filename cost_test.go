@@ -0,0 +1,65 @@
+package gas
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCostUSD(t *testing.T) {
+	prices := GasPrices{Fast: big.NewInt(20e9)}
+
+	// 1. the cost is the gas used at the price, in the native token, times its rate
+	cost, err := prices.EstimateCostUSD(21000, GasPriorityFast, TokenRate{USD: big.NewRat(2000, 1)})
+	require.NoError(t, err)
+	assert.Equal(t, "0.84", cost.FloatString(2))
+
+	// 2. a rate must be set
+	_, err = prices.EstimateCostUSD(21000, GasPriorityFast, TokenRate{})
+	assert.Error(t, err)
+
+	// 3. a client for another chain rejects rates of the wrong token
+	polygon, err := NewClient(WithChainID(ChainIDPolygon), WithProvider(countingProvider(0, nil, new(int32))))
+	require.NoError(t, err)
+	assert.Equal(t, "MATIC", polygon.NativeToken())
+	cost, err = polygon.EstimateCostUSD(21000, GasPriorityFast, TokenRate{Symbol: "MATIC", USD: big.NewRat(1, 2)})
+	require.NoError(t, err)
+	assert.Equal(t, "0.00021", cost.FloatString(5))
+	_, err = polygon.EstimateCostUSD(21000, GasPriorityFast, TokenRate{Symbol: "ETH", USD: big.NewRat(2000, 1)})
+	assert.Error(t, err)
+
+	// 4. clients default to ETH
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+	assert.Equal(t, "ETH", c.NativeToken())
+	_, err = c.EstimateCostUSD(21000, GasPriorityFast, TokenRate{Symbol: "ETH", USD: big.NewRat(2000, 1)})
+	assert.NoError(t, err)
+}
+
+func TestWithChainID(t *testing.T) {
+	// 1. the provider registered for the chain is used, while mainnet keeps the default provider
+	c, err := NewClient(WithChainID(ChainIDPolygon))
+	require.NoError(t, err)
+	assert.IsType(t, &PolygonGasStationProvider{}, c.source())
+	c, err = NewClient(WithChainID(ChainIDMainnet), WithAPIKey("key"))
+	require.NoError(t, err)
+	assert.Equal(t, "key", c.source().(*ETHGasStationProvider).APIKey)
+
+	// 2. chains without a provider or native token are rejected
+	_, err = NewClient(WithChainID(ChainIDBSC))
+	assert.True(t, errors.Is(err, ErrUnknownChain))
+	_, err = NewClient(WithChainID(999999))
+	assert.True(t, errors.Is(err, ErrUnknownChain))
+	_, err = NewClient(WithChainID(0))
+	assert.Error(t, err)
+
+	// 3. native tokens can be registered
+	require.NoError(t, RegisterNativeToken(999999, "TEST"))
+	c, err = NewClient(WithChainID(999999), WithProvider(countingProvider(0, nil, new(int32))))
+	require.NoError(t, err)
+	assert.Equal(t, "TEST", c.NativeToken())
+	assert.Error(t, RegisterNativeToken(1, ""))
+}
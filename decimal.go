@@ -0,0 +1,126 @@
+package gas
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// SuggestGasPriceWeiString is like SuggestGasPrice, but returns the price in wei as a decimal string, such as
+// "20000000000", for storing it in JSON or a database column without handling a big.Int.
+func (c *Client) SuggestGasPriceWeiString(priority GasPriority) (string, error) {
+	price, err := c.SuggestGasPrice(priority)
+	if err != nil {
+		return "", err
+	}
+	return price.String(), nil
+}
+
+// SuggestGasPriceGweiString is like SuggestGasPrice, but returns the price in gwei as an exact decimal string, such as
+// "20" or "120.5", see FormatGwei. A gwei is a billionth of a whole native token, given the decimals of the native
+// token of the client, see WithNativeTokenDecimals.
+func (c *Client) SuggestGasPriceGweiString(priority GasPriority) (string, error) {
+	price, err := c.SuggestGasPrice(priority)
+	if err != nil {
+		return "", err
+	}
+	return formatDecimal(price, c.NativeTokenDecimals()-9), nil
+}
+
+// SuggestGasPriceEtherString is like SuggestGasPrice, but returns the price in ether as an exact decimal string, such
+// as "0.00000002", see FormatEther. On a chain that pays gas in another token, it is the price in whole native tokens,
+// given the decimals of the native token of the client, see WithNativeTokenDecimals.
+func (c *Client) SuggestGasPriceEtherString(priority GasPriority) (string, error) {
+	price, err := c.SuggestGasPrice(priority)
+	if err != nil {
+		return "", err
+	}
+	return formatDecimal(price, c.NativeTokenDecimals()), nil
+}
+
+// FormatGwei formats an amount in wei as an exact decimal number of gwei, without trailing zeros or an exponent, such
+// as "120.5" for 120500000000 wei.
+func FormatGwei(wei *big.Int) string {
+	return formatDecimal(wei, 9)
+}
+
+// FormatEther formats an amount in wei as an exact decimal number of ether, without trailing zeros or an exponent, such
+// as "0.00000002" for 20000000000 wei.
+func FormatEther(wei *big.Int) string {
+	return formatDecimal(wei, 18)
+}
+
+// formatDecimal formats x divided by 10^decimals exactly, without trailing zeros. A negative number of decimals
+// multiplies x instead.
+func formatDecimal(x *big.Int, decimals int) string {
+	if decimals < 0 {
+		return new(big.Int).Mul(x, pow10(-decimals)).String()
+	}
+	digits := new(big.Int).Abs(x).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	integer, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	s := integer
+	if fraction != "" {
+		s += "." + fraction
+	}
+	if x.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// scaleDecimals returns x divided by 10^decimals exactly, or multiplied for a negative number of decimals
+func scaleDecimals(x *big.Int, decimals int) *big.Rat {
+	if decimals < 0 {
+		return new(big.Rat).SetInt(new(big.Int).Mul(x, pow10(-decimals)))
+	}
+	return new(big.Rat).SetFrac(x, pow10(decimals))
+}
+
+// pow10 returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// gasPriceUnits maps the unit suffixes accepted by ParseGasPrice to the number of decimals of the unit, longest first
+// so "gwei" isn't mistaken for "wei"
+var gasPriceUnits = []struct {
+	suffix   string
+	decimals int64
+}{
+	{"ether", 18},
+	{"gwei", 9},
+	{"eth", 18},
+	{"wei", 0},
+}
+
+// ParseGasPrice parses a gas price with a unit suffix, such as "30gwei", "0.00000003eth" or "30000000000wei", and
+// returns it in wei. A number without a suffix is in wei. The suffix is case-insensitive and may be separated from the
+// number by spaces, and the number may have decimals, which are converted exactly. It returns an error if the price is
+// negative or is not a whole number of wei, such as "0.1wei".
+func ParseGasPrice(s string) (*big.Int, error) {
+	number, decimals := strings.ToLower(strings.TrimSpace(s)), int64(0)
+	for _, unit := range gasPriceUnits {
+		if strings.HasSuffix(number, unit.suffix) {
+			number, decimals = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix)), unit.decimals
+			break
+		}
+	}
+
+	// big.Rat also parses fractions like "1/3", which aren't a decimal number
+	value, ok := new(big.Rat).SetString(number)
+	if !ok || strings.Contains(number, "/") {
+		return nil, fmt.Errorf("eth: invalid gas price %q", s)
+	}
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("eth: gas price %q must not be negative", s)
+	}
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil)))
+	if !value.IsInt() {
+		return nil, fmt.Errorf("eth: gas price %q is not a whole number of wei", s)
+	}
+	return new(big.Int).Set(value.Num()), nil
+}
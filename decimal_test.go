@@ -0,0 +1,75 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatDecimal(t *testing.T) {
+	// 1. amounts are formatted exactly, without trailing zeros
+	for wei, expected := range map[int64]string{
+		0:             "0",
+		1:             "0.000000001",
+		120500000000:  "120.5",
+		20000000000:   "20",
+		1000000000001: "1000.000000001",
+		-1500000000:   "-1.5",
+	} {
+		assert.Equal(t, expected, FormatGwei(big.NewInt(wei)), wei)
+	}
+	assert.Equal(t, "0.00000002", FormatEther(big.NewInt(20e9)))
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	assert.Equal(t, "123456789012.34567890123456789", FormatEther(huge))
+}
+
+func TestSuggestGasPriceDecimalStrings(t *testing.T) {
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+
+	wei, err := c.SuggestGasPriceWeiString(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", wei)
+	gwei, err := c.SuggestGasPriceGweiString(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20", gwei)
+	ether, err := c.SuggestGasPriceEtherString(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "0.00000002", ether)
+
+	_, err = c.SuggestGasPriceGweiString(GasPriority("unknown"))
+	assert.Error(t, err)
+}
+
+func TestParseGasPrice(t *testing.T) {
+	// 1. prices are parsed exactly in each unit, bare numbers in wei
+	for input, expected := range map[string]string{
+		"30gwei":          "30000000000",
+		"0.00000003eth":   "30000000000",
+		"30000000000":     "30000000000",
+		"30000000000wei":  "30000000000",
+		" 1.5 GWei ":      "1500000000",
+		"0.000000001gwei": "1",
+		"2ether":          "2000000000000000000",
+		"1e9wei":          "1000000000",
+		"0":               "0",
+	} {
+		price, err := ParseGasPrice(input)
+		if assert.NoError(t, err, input) {
+			assert.Equal(t, expected, price.String(), input)
+		}
+	}
+
+	// 2. invalid, negative and fractional wei prices are rejected
+	for _, input := range []string{"", "gwei", "30 kwei", "1/3gwei", "-1gwei", "0.1wei", "0.0000000001gwei"} {
+		_, err := ParseGasPrice(input)
+		assert.Error(t, err, input)
+	}
+
+	// 3. formatted prices parse back to the same amount
+	price, err := ParseGasPrice(FormatGwei(big.NewInt(120500000001)) + "gwei")
+	require.NoError(t, err)
+	assert.Equal(t, "120500000001", price.String())
+}
@@ -0,0 +1,75 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// fetchGroup coalesces concurrent fetches of a client into one, and shares a successful result with fetches that start
+// within window of it completing. Each caller gets its own copy of the prices, which it may modify freely.
+type fetchGroup struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	call *fetchCall
+}
+
+type fetchCall struct {
+	// done is closed when the fetch completes, the fields below are read only after that
+	done       chan struct{}
+	prices     GasPrices
+	err        error
+	finishedAt time.Time
+}
+
+// do calls fetch unless a call is already in flight or recently succeeded, in which case its result is returned. The
+// shared call is bound to the context of the caller that started it, other callers only stop waiting when their own
+// ctx is done. If the shared call fails because the context of its caller is done, a waiting caller whose ctx is not
+// done starts a new call rather than failing with that error.
+func (g *fetchGroup) do(ctx context.Context, fetch func(context.Context) (GasPrices, error)) (GasPrices, error) {
+	for {
+		g.mu.Lock()
+		call := g.call
+		if call == nil || (!call.finishedAt.IsZero() && time.Since(call.finishedAt) >= g.window) {
+			break
+		}
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			canceled := errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)
+			if !canceled || ctx.Err() != nil {
+				return call.prices.copy(), call.err
+			}
+		case <-ctx.Done():
+			return GasPrices{}, ctx.Err()
+		}
+	}
+	call := &fetchCall{done: make(chan struct{})}
+	g.call = call
+	g.mu.Unlock()
+
+	prices, err := fetch(ctx)
+
+	g.mu.Lock()
+	call.prices, call.err = prices, err
+	call.finishedAt = time.Now()
+	if err != nil {
+		// failures are only shared with the callers that were already waiting
+		g.call = nil
+	}
+	g.mu.Unlock()
+	close(call.done)
+
+	return prices.copy(), err
+}
+
+// forget stops sharing the result of a completed fetch, a fetch in flight is still shared
+func (g *fetchGroup) forget() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.call != nil && !g.call.finishedAt.IsZero() {
+		g.call = nil
+	}
+}
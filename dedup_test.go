@@ -0,0 +1,151 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchGroup(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (GasPrices, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return GasPrices{Fast: big.NewInt(20e9)}, nil
+	}
+	g := fetchGroup{window: 50 * time.Millisecond}
+
+	// 1. concurrent fetches share one call
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prices, err := g.do(context.Background(), fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, "20000000000", prices.Fast.String())
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 2. the result is shared within the window, and fetched again after it
+	_, err := g.do(context.Background(), fetch)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = g.do(context.Background(), fetch)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestFetchGroupFailure(t *testing.T) {
+	failure := errors.New("failure")
+	var calls int32
+	fetch := func(context.Context) (GasPrices, error) {
+		atomic.AddInt32(&calls, 1)
+		return GasPrices{}, failure
+	}
+	g := fetchGroup{window: time.Minute}
+
+	// 1. failures are not shared with later calls
+	_, err := g.do(context.Background(), fetch)
+	assert.Equal(t, failure, err)
+	_, err = g.do(context.Background(), fetch)
+	assert.Equal(t, failure, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestFetchGroupLeaderCanceled(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 1)
+	fetch := func(ctx context.Context) (GasPrices, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			return GasPrices{Fast: big.NewInt(20e9)}, nil
+		}
+		started <- struct{}{}
+		<-ctx.Done()
+		return GasPrices{}, &FetchError{Err: ctx.Err()}
+	}
+	g := fetchGroup{window: time.Minute}
+
+	// 1. a waiter whose context is live fetches again when the caller that started the call cancels
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := g.do(leaderCtx, fetch)
+		leaderErr <- err
+	}()
+	<-started
+	waiter := make(chan error, 1)
+	go func() {
+		prices, err := g.do(context.Background(), fetch)
+		if err == nil {
+			assert.Equal(t, "20000000000", prices.Fast.String())
+		}
+		waiter <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	assert.True(t, errors.Is(<-leaderErr, context.Canceled))
+	assert.NoError(t, <-waiter)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWithFetchDedupWindow(t *testing.T) {
+	var calls int32
+	c, err := NewClient(WithProvider(countingProvider(0, nil, &calls)), WithFetchDedupWindow(time.Minute))
+	require.NoError(t, err)
+
+	// 1. calls within the window share a request
+	for i := 0; i < 3; i++ {
+		price, err := c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+		assert.Equal(t, "20000000000", price.String())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 2. each call gets its own copy of the shared prices, so a transform may modify them in place
+	release := make(chan struct{})
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		<-release
+		return GasPrices{Fast: big.NewInt(20e9)}, nil
+	})
+	double := func(prices GasPrices) GasPrices {
+		prices.Fast.Mul(prices.Fast, big.NewInt(2))
+		return prices
+	}
+	c, err = NewClient(WithProvider(provider), WithFetchDedupWindow(time.Minute), WithResultTransform(double))
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			price, err := c.SuggestGasPrice(GasPriorityFast)
+			assert.NoError(t, err)
+			assert.Equal(t, "40000000000", price.String())
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "40000000000", price.String())
+
+	// 3. negative windows are rejected
+	_, err = NewClient(WithFetchDedupWindow(-time.Second))
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxResultAge is the maximum age of cached results for the Default client, unless configured otherwise with
+// ConfigureDefault.
+const DefaultMaxResultAge = time.Minute
+
+// DefaultTimeout bounds each request made by the package-level functions and the Default client, so a stalled API
+// can't block them forever. It doesn't apply if the HTTP client set with SetHTTPClient has a timeout of its own.
+const DefaultTimeout = 30 * time.Second
+
+var (
+	defaultOnce   sync.Once
+	defaultClient *Client
+
+	// defaultReady is set once defaultClient is initialized, so it can be checked without initializing it
+	defaultReady int32
+
+	// preferCached is set with SetPreferCached
+	preferCached int32
+)
+
+// Default returns a process-wide shared Client that caches results for DefaultMaxResultAge.
+//
+// The client is initialized exactly once, on the first call to Default or ConfigureDefault, and is safe to use from
+// multiple goroutines.
+//
+// If it can't be initialized, such as because the key set with SetKey is malformed, every call on the returned client
+// fails with the error of NewClient, and the package-level functions keep making a new call each time even if
+// SetPreferCached is in effect.
+func Default() *Client {
+	defaultOnce.Do(func() {
+		c, err := NewClient(WithMaxResultAge(DefaultMaxResultAge), WithTimeout(DefaultTimeout))
+		if err != nil {
+			defaultClient = failedClient(err)
+			return
+		}
+		defaultClient = c
+		atomic.StoreInt32(&defaultReady, 1)
+	})
+	return defaultClient
+}
+
+// failedClient returns a client whose calls all fail with err
+func failedClient(err error) *Client {
+	c := new(Client)
+	c.provider = ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{}, err
+	})
+	return c
+}
+
+// ConfigureDefault initializes the shared client returned by Default with the provided options instead of the default
+// configuration. Include WithMaxResultAge to keep caching enabled.
+//
+// It must be called before the first call to Default, and returns an error if the shared client is already initialized
+// or if any of the options are invalid.
+func ConfigureDefault(opts ...Option) error {
+	c, err := NewClient(opts...)
+	if err != nil {
+		return err
+	}
+
+	configured := false
+	defaultOnce.Do(func() {
+		defaultClient = c
+		atomic.StoreInt32(&defaultReady, 1)
+		configured = true
+	})
+	if !configured {
+		return errors.New("eth: default client is already initialized")
+	}
+	return nil
+}
+
+// SetPreferCached makes the package-level SuggestGasPrice, SuggestGasPriceContext, SuggestGasPriceDetails,
+// SuggestFastGasPrice, SuggestGasPriceRat, SuggestFees, GasEstimates, PriceForMaxWait, FeeParamsForWait, EstimateWait
+// and GasPriceOptions use the shared client returned by Default instead of making a new call to the ETH Gas Station
+// API, so code can move to the shared cache without changing each call site.
+//
+// The shared client is only used once it has been initialized by a call to Default or ConfigureDefault, these
+// functions never initialize it themselves. Until then they keep making a new call each time. Once it is used, its
+// configuration applies entirely, including its provider and cache. NewGasPriceSuggester is not affected, since it
+// has a cache of its own.
+func SetPreferCached(prefer bool) {
+	var value int32
+	if prefer {
+		value = 1
+	}
+	atomic.StoreInt32(&preferCached, value)
+}
+
+// packageClient returns the client used by the package-level functions
+func packageClient() *Client {
+	if atomic.LoadInt32(&preferCached) == 1 && atomic.LoadInt32(&defaultReady) == 1 {
+		return defaultClient
+	}
+	return newPackageClient()
+}
+
+var (
+	// httpClientMu guards httpClient, which is set with SetHTTPClient
+	httpClientMu sync.RWMutex
+	httpClient   *http.Client
+)
+
+// SetHTTPClient sets the HTTP client used by the package-level functions that make a new call to the ETH Gas Station
+// API and by NewGasPriceSuggester, such as to change the timeout from DefaultTimeout or reuse connections. A nil client
+// restores http.DefaultClient. It does not affect clients created with NewClient, use WithHTTPClient for them.
+func SetHTTPClient(client *http.Client) {
+	httpClientMu.Lock()
+	defer httpClientMu.Unlock()
+	httpClient = client
+}
+
+// packageHTTPClient returns the client set with SetHTTPClient, or http.DefaultClient
+func packageHTTPClient() *http.Client {
+	httpClientMu.RLock()
+	defer httpClientMu.RUnlock()
+	if httpClient == nil {
+		return http.DefaultClient
+	}
+	return httpClient
+}
+
+// newPackageClient returns a client with the default configuration and the HTTP client set with SetHTTPClient, bounded
+// by DefaultTimeout unless that HTTP client has a timeout
+func newPackageClient() *Client {
+	c := new(Client)
+	httpClientMu.RLock()
+	defer httpClientMu.RUnlock()
+	c.httpClient = httpClient
+	if httpClient == nil || httpClient.Timeout == 0 {
+		c.timeout = DefaultTimeout
+	}
+	return c
+}
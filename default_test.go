@@ -0,0 +1,182 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetDefault() {
+	defaultOnce = sync.Once{}
+	defaultClient = nil
+	atomic.StoreInt32(&defaultReady, 0)
+	SetPreferCached(false)
+}
+
+func TestDefault(t *testing.T) {
+	resetDefault()
+	defer resetDefault()
+
+	// 1. concurrent callers all receive the same, caching client
+	var wg sync.WaitGroup
+	clients := make([]*Client, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i] = Default()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range clients {
+		assert.True(t, c == clients[0], "all callers should share one client")
+	}
+	require.NotNil(t, clients[0].cache)
+	assert.Equal(t, DefaultMaxResultAge, clients[0].cache.maxResultAge)
+
+	// 2. the shared client can not be reconfigured once initialized
+	assert.Error(t, ConfigureDefault(WithMaxResultAge(time.Second)))
+}
+
+func TestConfigureDefault(t *testing.T) {
+	resetDefault()
+	defer resetDefault()
+
+	// 1. invalid options are rejected without initializing the shared client
+	assert.Error(t, ConfigureDefault(WithInputScale(InputScale(42))))
+
+	// 2. valid options are used by the shared client
+	require.NoError(t, ConfigureDefault(WithMaxResultAge(time.Second), WithInputScale(InputScaleGwei)))
+	c := Default()
+	require.NotNil(t, c.cache)
+	assert.Equal(t, time.Second, c.cache.maxResultAge)
+	assert.Equal(t, InputScaleGwei, c.inputScale)
+}
+
+func TestDefaultInvalidKey(t *testing.T) {
+	resetDefault()
+	defer resetDefault()
+	defer resetKey()
+	server := httptest.NewServer(http.HandlerFunc(serveTestResponse))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	SetHTTPClient(&http.Client{Transport: testTransport{server: serverURL}})
+	defer SetHTTPClient(nil)
+
+	// 1. a malformed key set with SetKey makes every call on the shared client fail, rather than panic
+	SetKey("")
+	c := Default()
+	require.NotNil(t, c)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&defaultReady))
+
+	// 2. the package-level functions don't use the shared client, even if preferred
+	SetPreferCached(true)
+	price, err := SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+}
+
+func TestSetPreferCached(t *testing.T) {
+	resetDefault()
+	defer resetDefault()
+
+	var requests int32
+	server := httptest.NewServer(failingHandler(0, 0, &requests))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = testTransport{server: serverURL}
+	defer func() { http.DefaultClient.Transport = transport }()
+
+	// 1. the shared client is not initialized by the package-level functions
+	SetPreferCached(true)
+	_, err = SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&defaultReady))
+
+	// 2. once it is initialized, its cache is used
+	var calls int32
+	require.NoError(t, ConfigureDefault(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(time.Minute)))
+	for i := 0; i < 2; i++ {
+		price, err := SuggestFastGasPrice()
+		require.NoError(t, err)
+		assert.Equal(t, "20000000000", price.String())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// 3. without the flag, a new call is made each time
+	SetPreferCached(false)
+	_, err = SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestSetHTTPClient(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		serveTestResponse(w, r)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	SetHTTPClient(&http.Client{Transport: testTransport{server: serverURL}})
+	defer SetHTTPClient(nil)
+
+	// 1. the package-level functions use the HTTP client, bound to the context of the call
+	price, err := SuggestGasPriceContext(context.Background(), GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = SuggestGasPriceContext(ctx, GasPriorityFast)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+
+	// 2. so does a suggester, whose new responses are bound to the context of each call
+	suggest, err := NewGasPriceSuggesterContext(context.Background(), 0)
+	require.NoError(t, err)
+	_, err = suggest(ctx, GasPriorityFast)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	price, err = suggest.SuggestGasPriceContext(context.Background(), GasPriorityAverage)
+	require.NoError(t, err)
+	assert.Equal(t, "15000000000", price.String())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// 3. the first response of a suggester is loaded with its context
+	_, err = NewGasPriceSuggesterContext(ctx, time.Minute)
+	assert.Error(t, err)
+
+	// 4. a nil client restores http.DefaultClient
+	SetHTTPClient(nil)
+	assert.Equal(t, http.DefaultClient, packageHTTPClient())
+}
+
+func TestPackageTimeout(t *testing.T) {
+	defer SetHTTPClient(nil)
+
+	// 1. package-level calls are bounded by DefaultTimeout by default
+	assert.Equal(t, DefaultTimeout, newPackageClient().timeout)
+
+	// 2. but not when the HTTP client has a timeout of its own
+	SetHTTPClient(&http.Client{Timeout: time.Second})
+	assert.Equal(t, time.Duration(0), newPackageClient().timeout)
+	SetHTTPClient(&http.Client{})
+	assert.Equal(t, DefaultTimeout, newPackageClient().timeout)
+}
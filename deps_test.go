@@ -0,0 +1,38 @@
+package gas
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStandardLibraryOnly ensures the package only imports the standard library, so importing it adds no dependencies.
+// Test files may import testing dependencies.
+func TestStandardLibraryOnly(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		require.NoError(t, err)
+
+		for _, spec := range parsed.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			require.NoError(t, err)
+
+			// standard library import paths have no dot in their first element
+			first := strings.SplitN(path, "/", 2)[0]
+			assert.False(t, strings.Contains(first, "."), "%s imports %s, which is not in the standard library", file, path)
+		}
+	}
+}
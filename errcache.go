@@ -0,0 +1,44 @@
+package gas
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// errorCache remembers the most recent fetch failure for ttl
+type errorCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	err      error
+	failedAt time.Time
+}
+
+// do returns the cached error if fetch failed within ttl, and otherwise calls fetch and caches its error. Failures
+// caused by the caller's context are not cached, since they say nothing about the provider.
+func (e *errorCache) do(ctx context.Context, fetch func(context.Context) (GasPrices, error)) (GasPrices, error) {
+	e.mu.Lock()
+	if e.err != nil && time.Since(e.failedAt) < e.ttl {
+		err := e.err
+		e.mu.Unlock()
+		return GasPrices{}, err
+	}
+	e.mu.Unlock()
+
+	prices, err := fetch(ctx)
+	if err != nil && ctx.Err() == nil {
+		e.mu.Lock()
+		e.err = err
+		e.failedAt = time.Now()
+		e.mu.Unlock()
+	}
+	return prices, err
+}
+
+// reset forgets the cached error
+func (e *errorCache) reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.err = nil
+}
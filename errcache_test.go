@@ -0,0 +1,55 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithErrorCache(t *testing.T) {
+	failure := errors.New("failure")
+	var calls int32
+	c, err := NewClient(WithProvider(countingProvider(1, failure, &calls)), WithErrorCache(30*time.Millisecond))
+	require.NoError(t, err)
+
+	// 1. a failure is returned without calling the provider within the ttl
+	for i := 0; i < 3; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		assert.Equal(t, failure, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 2. the provider is called again after the ttl
+	time.Sleep(40 * time.Millisecond)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 3. invalid ttls are rejected
+	_, err = NewClient(WithErrorCache(0))
+	assert.Error(t, err)
+}
+
+func TestErrorCacheContext(t *testing.T) {
+	var calls int32
+	e := errorCache{ttl: time.Minute}
+	fetch := func(ctx context.Context) (GasPrices, error) {
+		atomic.AddInt32(&calls, 1)
+		return GasPrices{}, ctx.Err()
+	}
+
+	// failures caused by the caller's context are not remembered
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := e.do(ctx, fetch)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = e.do(context.Background(), fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
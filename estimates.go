@@ -0,0 +1,54 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// GasEstimates returns all the prices of a single response in wei along with their estimated confirmation times: the
+// wait time of each priority level in Waits, the prediction table of wait times by price in Predictions and the block
+// time, for making cost and latency trade-offs beyond the fixed priority levels, such as with PriceForMaxWait. Unlike
+// Snapshot, the wait times are estimated by the model set with WithCustomConfirmationModel if any. The returned prices
+// are a copy and may be modified freely.
+func (c *Client) GasEstimates() (GasPrices, error) {
+	prices, err := c.loadWaits()
+	if err != nil {
+		return GasPrices{}, err
+	}
+	return prices.copy(), nil
+}
+
+// GasEstimator is a function that returns the prices and wait estimates of a response, as returned by
+// NewGasEstimator.
+type GasEstimator func() (GasPrices, error)
+
+// NewGasEstimator is like NewGasPriceSuggester, but the returned function returns all the prices and wait estimates,
+// as GasEstimates does, from prices cached until they are older than maxResultAge.
+func (c *Client) NewGasEstimator(maxResultAge time.Duration) (GasEstimator, error) {
+	if maxResultAge < 0 {
+		return nil, errors.New("eth: max result age must not be negative")
+	}
+	prices, err := c.fetch(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	m := gasPriceManager{
+		latestPrices: prices,
+		fetchedAt:    c.clock(),
+		now:          c.now,
+		maxResultAge: maxResultAge,
+		fetch:        c.fetch,
+	}
+	return func() (GasPrices, error) {
+		prices, err := m.latest(context.Background())
+		if err != nil {
+			return GasPrices{}, err
+		}
+		if c.confirmationModel != nil {
+			return prices.withConfirmationModel(c.confirmationModel), nil
+		}
+		return prices.copy(), nil
+	}, nil
+}
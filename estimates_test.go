@@ -0,0 +1,62 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasEstimates(t *testing.T) {
+	var calls int32
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		atomic.AddInt32(&calls, 1)
+		return GasPrices{
+			SafeLow:     big.NewInt(10e9),
+			Fast:        big.NewInt(20e9),
+			Predictions: []PricePrediction{{Price: big.NewInt(10e9), Wait: 10 * time.Minute}},
+			Waits:       map[GasPriority]time.Duration{GasPrioritySafeLow: 10 * time.Minute, GasPriorityFast: time.Minute},
+			BlockTime:   12 * time.Second,
+		}, nil
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	// 1. the prices come with their wait times, predictions and block time
+	estimates, err := c.GasEstimates()
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", estimates.Fast.String())
+	assert.Equal(t, time.Minute, estimates.Waits[GasPriorityFast])
+	assert.Len(t, estimates.Predictions, 1)
+	assert.Equal(t, 12*time.Second, estimates.BlockTime)
+
+	// 2. the wait times are estimated by the confirmation model of the client
+	model := func(*big.Int, GasPrices) time.Duration { return 3 * time.Minute }
+	c, err = NewClient(WithProvider(provider), WithCustomConfirmationModel(model))
+	require.NoError(t, err)
+	estimates, err = c.GasEstimates()
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Minute, estimates.Waits[GasPrioritySafeLow])
+	assert.Equal(t, 3*time.Minute, estimates.Predictions[0].Wait)
+
+	// 3. the estimator serves cached prices, as copies
+	atomic.StoreInt32(&calls, 0)
+	estimator, err := c.NewGasEstimator(time.Hour)
+	require.NoError(t, err)
+	estimates, err = estimator()
+	require.NoError(t, err)
+	estimates.Fast.SetInt64(1)
+	estimates, err = estimator()
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", estimates.Fast.String())
+	assert.Equal(t, 3*time.Minute, estimates.Waits[GasPriorityFast])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 4. negative max result ages are rejected
+	_, err = c.NewGasEstimator(-time.Second)
+	assert.Error(t, err)
+}
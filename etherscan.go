@@ -0,0 +1,158 @@
+package gas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// EtherscanURL is the Etherscan API endpoint used by the EtherscanProvider.
+const EtherscanURL = "https://api.etherscan.io/v2/api"
+
+// EtherscanProvider is a Provider that loads prices from the gas oracle of the Etherscan gas tracker, which requires
+// an API key. The safe, proposed and fast gas prices are served as the safeLow, average and fast priority levels, and
+// the suggested base fee is reported in GasPrices.BaseFee. Etherscan doesn't report a fastest
+// price, so requesting GasPriorityFastest or GasPriorityTurbo fails.
+//
+// Etherscan reports errors such as an invalid key with a successful status, they are errors of the fetch, and a rate
+// limited request is a *FetchError with status 429 so it can be retried.
+type EtherscanProvider struct {
+	// APIKey is sent as the apikey query parameter.
+	APIKey string
+
+	// ChainID is the chain the gas oracle is queried for, it defaults to 1 for Ethereum mainnet.
+	ChainID uint64
+
+	// URL replaces EtherscanURL, e.g. to use a proxy.
+	URL string
+
+	// HTTPClient is used to make requests, it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type etherscanResponse struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+type etherscanGasOracle struct {
+	SafeGasPrice    json.Number `json:"SafeGasPrice"`
+	ProposeGasPrice json.Number `json:"ProposeGasPrice"`
+	FastGasPrice    json.Number `json:"FastGasPrice"`
+	SuggestBaseFee  json.Number `json:"suggestBaseFee"`
+}
+
+// Fetch loads the latest prices from the Etherscan gas oracle.
+func (p *EtherscanProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url(), nil)
+	if err != nil {
+		return GasPrices{}, err
+	}
+
+	res, err := p.client().Do(req)
+	if err != nil {
+		return GasPrices{}, &FetchError{Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return GasPrices{}, &FetchError{StatusCode: res.StatusCode}
+	}
+
+	var response etherscanResponse
+	if err := decodeResponse(res.Body, &response); err != nil {
+		return GasPrices{}, err
+	}
+	if response.Status != "1" {
+		var reason string
+		if json.Unmarshal(response.Result, &reason) != nil || reason == "" {
+			reason = response.Message
+		}
+		if strings.Contains(strings.ToLower(reason), "rate limit") {
+			return GasPrices{}, &FetchError{StatusCode: http.StatusTooManyRequests}
+		}
+		return GasPrices{}, errors.New("eth: etherscan error: " + reason)
+	}
+
+	var oracle etherscanGasOracle
+	if err := json.Unmarshal(response.Result, &oracle); err != nil {
+		return GasPrices{}, err
+	}
+	return newEtherscanGasPrices(oracle)
+}
+
+// CloseIdleConnections closes idle connections of the underlying HTTP client.
+func (p *EtherscanProvider) CloseIdleConnections() {
+	p.client().CloseIdleConnections()
+}
+
+func (p *EtherscanProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
+
+// validateKey checks the key of the provider, which is required
+func (p *EtherscanProvider) validateKey() error {
+	return ValidateKey(p.APIKey)
+}
+
+func (p *EtherscanProvider) url() string {
+	endpoint := p.URL
+	if endpoint == "" {
+		endpoint = EtherscanURL
+	}
+	chainID := p.ChainID
+	if chainID == 0 {
+		chainID = 1
+	}
+
+	query := url.Values{}
+	query.Set("chainid", strconv.FormatUint(chainID, 10))
+	query.Set("module", "gastracker")
+	query.Set("action", "gasoracle")
+	query.Set("apikey", p.APIKey)
+	return endpoint + "?" + query.Encode()
+}
+
+// newEtherscanGasPrices converts the prices of the gas oracle, decimal numbers of gwei, to wei exactly
+func newEtherscanGasPrices(oracle etherscanGasOracle) (GasPrices, error) {
+	levels := []struct {
+		priority GasPriority
+		raw      json.Number
+	}{
+		{GasPrioritySafeLow, oracle.SafeGasPrice},
+		{GasPriorityAverage, oracle.ProposeGasPrice},
+		{GasPriorityFast, oracle.FastGasPrice},
+	}
+	result := GasPrices{Raw: make(map[GasPriority]string, len(levels))}
+	for _, level := range levels {
+		if level.raw == "" {
+			return GasPrices{}, errors.New("eth: response has no gas price for priority " + string(level.priority))
+		}
+		price, err := parseScaledDecimalToWei(level.raw.String(), InputScaleGwei)
+		if err != nil {
+			return GasPrices{}, err
+		}
+		if result, err = result.withPrice(level.priority, price); err != nil {
+			return GasPrices{}, err
+		}
+		result.Raw[level.priority] = level.raw.String()
+	}
+
+	if oracle.SuggestBaseFee != "" {
+		exact, err := parseDecimalGasPrice(oracle.SuggestBaseFee.String())
+		if err != nil {
+			return GasPrices{}, err
+		}
+		// the suggested base fee has more decimals than a wei, it is rounded to the nearest wei
+		result.BaseFee = roundRat(exact.Mul(exact, gweiConversionFactor))
+	}
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtherscanProvider(t *testing.T) {
+	response := `{"status": "1", "message": "OK", "result": {"LastBlock": "19000000", "SafeGasPrice": "20", ` +
+		`"ProposeGasPrice": "21.5", "FastGasPrice": "23.000000001", "suggestBaseFee": "19.5123456789", ` +
+		`"gasUsedRatio": "0.4,0.6"}}`
+	requests := make(chan *url.URL, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.URL
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+	provider := &EtherscanProvider{APIKey: "test-key", ChainID: 137, URL: server.URL + "/v2/api"}
+
+	// 1. the gas oracle of the chain is requested with the key
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	requested := <-requests
+	assert.Equal(t, "/v2/api", requested.Path)
+	assert.Equal(t, "137", requested.Query().Get("chainid"))
+	assert.Equal(t, "gasoracle", requested.Query().Get("action"))
+	assert.Equal(t, "test-key", requested.Query().Get("apikey"))
+
+	// 2. the prices are converted from gwei exactly, and the base fee is rounded to the nearest wei
+	assert.Equal(t, "20000000000", prices.SafeLow.String())
+	assert.Equal(t, "21500000000", prices.Average.String())
+	assert.Equal(t, "23000000001", prices.Fast.String())
+	assert.Nil(t, prices.Fastest)
+	assert.Equal(t, "19512345679", prices.BaseFee.String())
+	assert.Equal(t, "21.5", prices.Raw[GasPriorityAverage])
+
+	// 3. errors reported with a successful status fail the fetch, and rate limiting can be retried
+	response = `{"status": "0", "message": "NOTOK", "result": "Invalid API Key"}`
+	_, err = provider.Fetch(context.Background())
+	assert.EqualError(t, err, "eth: etherscan error: Invalid API Key")
+	<-requests
+	response = `{"status": "0", "message": "NOTOK", "result": "Max rate limit reached"}`
+	_, err = provider.Fetch(context.Background())
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusTooManyRequests, fetchErr.StatusCode)
+	<-requests
+
+	// 4. the key is required by a client
+	_, err = NewClient(WithProvider(&EtherscanProvider{}))
+	assert.Error(t, err)
+	assert.Equal(t, InputScaleGwei, provider.Unit())
+}
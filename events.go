@@ -0,0 +1,95 @@
+package gas
+
+import "time"
+
+// EventType is the kind of an Event.
+type EventType string
+
+const (
+	// EventFetchStarted is emitted before each attempt to load prices from the provider, including retries.
+	EventFetchStarted = EventType("fetch_started")
+
+	// EventFetchSucceeded is emitted when an attempt loaded prices, with the prices returned by the provider.
+	EventFetchSucceeded = EventType("fetch_succeeded")
+
+	// EventFetchFailed is emitted when an attempt failed, with its error.
+	EventFetchFailed = EventType("fetch_failed")
+
+	// EventCacheHit is emitted when a call is served from the whole-response cache, with the cached prices.
+	EventCacheHit = EventType("cache_hit")
+
+	// EventRefresh is emitted when a refresh in the background starts, either of stale cached prices or by a Refresher.
+	EventRefresh = EventType("refresh")
+)
+
+// Event describes a step of loading prices, as emitted on the channel returned by Client.Events.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// Attempt is the number of the attempt of a fetch event, starting at 1 for the first attempt of a call.
+	Attempt int
+
+	// RequestID is the request ID of the fetch of a fetch event, shared by all of its attempts, as returned by
+	// RequestIDFromContext for its requests.
+	RequestID string
+
+	// Prices are the prices loaded by a successful attempt, before any transform or rounding, or served by a cache hit.
+	// They are a copy and may be modified freely.
+	Prices GasPrices
+
+	// Err is the error of a failed attempt.
+	Err error
+
+	// Stale is set for a cache hit that served stale prices while they are refreshed in the background.
+	Stale bool
+}
+
+// Events returns the channel on which a client configured with WithEvents emits events as it loads prices, for
+// tailing from a debugging dashboard. It returns nil for other clients.
+//
+// The channel has the buffer size given to WithEvents, and emitting never blocks: when the buffer is full, the oldest
+// event is dropped to make room for the new one, so a slow consumer misses events rather than slowing down calls. The
+// channel is closed by Close.
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// emit sends event on the events channel, dropping the oldest event if it is full
+func (c *Client) emit(event Event) {
+	if c.events == nil {
+		return
+	}
+	event.Time = time.Now()
+	event.Prices = event.Prices.copy()
+
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+	if c.eventsClosed {
+		return
+	}
+	for {
+		select {
+		case c.events <- event:
+			return
+		default:
+		}
+		select {
+		case <-c.events:
+		default:
+		}
+	}
+}
+
+// closeEvents closes the events channel, once no more events can be emitted
+func (c *Client) closeEvents() {
+	if c.events == nil {
+		return
+	}
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+	if !c.eventsClosed {
+		c.eventsClosed = true
+		close(c.events)
+	}
+}
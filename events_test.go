@@ -0,0 +1,74 @@
+package gas
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainEvents returns the events buffered on events
+func drainEvents(events <-chan Event) []Event {
+	var drained []Event
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return drained
+			}
+			drained = append(drained, event)
+		default:
+			return drained
+		}
+	}
+}
+
+func TestWithEvents(t *testing.T) {
+	var calls int32
+	failure := &FetchError{StatusCode: http.StatusServiceUnavailable}
+	c, err := NewClient(WithProvider(countingProvider(1, failure, &calls)), WithRetry(2, time.Millisecond),
+		WithMaxResultAge(time.Minute), WithEvents(10))
+	require.NoError(t, err)
+
+	// 1. each attempt emits its start and outcome, then reads are served from the cache
+	for i := 0; i < 2; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	events := drainEvents(c.Events())
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+		assert.False(t, event.Time.IsZero())
+	}
+	assert.Equal(t, []EventType{EventFetchStarted, EventFetchFailed, EventFetchStarted, EventFetchSucceeded,
+		EventCacheHit}, types)
+	assert.Equal(t, 2, events[2].Attempt)
+	assert.True(t, errors.Is(events[1].Err, failure))
+	assert.Equal(t, "20000000000", events[3].Prices.Fast.String())
+	assert.Equal(t, "20000000000", events[4].Prices.Fast.String())
+
+	// 2. the channel is closed by Close
+	require.NoError(t, c.Close())
+	_, ok := <-c.Events()
+	assert.False(t, ok)
+
+	// 3. the oldest events are dropped once the buffer is full
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &calls)), WithEvents(1))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	events = drainEvents(c.Events())
+	require.Len(t, events, 1)
+	assert.Equal(t, EventFetchSucceeded, events[0].Type)
+
+	// 4. clients without events have no channel, and the buffer size must be positive
+	c, err = NewClient()
+	require.NoError(t, err)
+	assert.Nil(t, c.Events())
+	_, err = NewClient(WithEvents(0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,20 @@
+package gas
+
+import "context"
+
+type failFastKey struct{}
+
+// ContextWithFailFast returns a copy of ctx that makes a single attempt to load prices and fails immediately if that
+// attempt fails, for latency-critical calls on a client that otherwise retries. Pass it to SuggestGasPriceContext.
+//
+// Fail fast mode bypasses the retries configured with WithRetry, the Retry middleware, and falling back on other
+// providers of a provider returned by NewFallbackProvider. It does not bypass the cache, so cached prices are still
+// served, or the CircuitBreaker middleware, which already fails immediately while open.
+func ContextWithFailFast(ctx context.Context) context.Context {
+	return context.WithValue(ctx, failFastKey{}, true)
+}
+
+func isFailFast(ctx context.Context) bool {
+	failFast, _ := ctx.Value(failFastKey{}).(bool)
+	return failFast
+}
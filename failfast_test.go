@@ -0,0 +1,47 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWithFailFast(t *testing.T) {
+	var requests int32
+	c, stop := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 1, &requests), WithRetry(2, time.Millisecond))
+	defer stop()
+
+	// 1. a fail fast call is not retried
+	_, err := c.SuggestGasPriceContext(ContextWithFailFast(context.Background()), GasPriorityFast)
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusServiceUnavailable, fetchErr.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// 2. other calls are still retried
+	atomic.StoreInt32(&requests, 0)
+	c, stop = newTestClient(t, failingHandler(http.StatusServiceUnavailable, 1, &requests), WithRetry(2, time.Millisecond))
+	defer stop()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestWithFailFast(t *testing.T) {
+	transient := &FetchError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("unavailable")}
+	var calls int32
+	provider := Chain(countingProvider(1, transient, &calls), Retry(2, time.Millisecond, nil))
+
+	// 1. retry middleware is bypassed for clients configured to fail fast
+	c, err := NewClient(WithProvider(provider), WithFailFast())
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Equal(t, transient, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,136 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewFallbackProvider returns a Provider that tries each of providers in order until one succeeds, so prices can still be
+// loaded when a provider is down. If every provider fails, the error of the last provider tried is returned.
+//
+// Calls in fail fast mode, see ContextWithFailFast, only try the first provider. By default providers are always tried
+// in the order given, use WithAdaptiveOrder to prefer providers that are succeeding, and WithPreferenceWeights to
+// prefer some providers over others, such as a free node over a paid API.
+func NewFallbackProvider(providers []Provider, opts ...FallbackOption) Provider {
+	p := &fallbackProvider{
+		providers:  append([]Provider(nil), providers...),
+		lastFailed: make([]time.Time, len(providers)),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// FallbackOption configures a provider returned by NewFallbackProvider.
+type FallbackOption func(*fallbackProvider)
+
+// WithAdaptiveOrder moves providers that failed their most recent call behind the providers that didn't, so a
+// persistently failing primary provider doesn't cost a failed request on every call. A failed provider is tried in its
+// original position again once recheck has passed since it failed, so a recovered provider is preferred again.
+func WithAdaptiveOrder(recheck time.Duration) FallbackOption {
+	return func(p *fallbackProvider) {
+		p.adaptive = true
+		p.recheck = recheck
+	}
+}
+
+// WithPreferenceWeights tries providers from the highest weight to the lowest, where weights[i] is the weight of the
+// i-th provider, so the most preferred source is used while it works and the others only when it fails. Providers
+// without a weight have a weight of 0, and providers with the same weight keep their order. Combined with
+// WithAdaptiveOrder, the most preferred provider that isn't failing is tried first, and failing providers are tried
+// last, by weight.
+func WithPreferenceWeights(weights []float64) FallbackOption {
+	return func(p *fallbackProvider) {
+		p.weights = append([]float64(nil), weights...)
+	}
+}
+
+type fallbackProvider struct {
+	providers []Provider
+	adaptive  bool
+	recheck   time.Duration
+	weights   []float64
+
+	// lastFailed holds when each provider last failed, it is zero once the provider succeeds
+	mu         sync.Mutex
+	lastFailed []time.Time
+}
+
+func (p *fallbackProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	if len(p.providers) == 0 {
+		return GasPrices{}, errors.New("eth: no providers to fall back on")
+	}
+
+	var err error
+	for _, i := range p.order() {
+		var prices GasPrices
+		prices, err = p.providers[i].Fetch(ctx)
+		p.record(i, err)
+		if err == nil {
+			return prices, nil
+		}
+		if ctx.Err() != nil || isFailFast(ctx) {
+			// the remaining providers would fail the same way, or the caller doesn't want to wait for them
+			return GasPrices{}, err
+		}
+	}
+	return GasPrices{}, err
+}
+
+// order returns the indexes of the providers in the order they should be tried
+func (p *fallbackProvider) order() []int {
+	order := make([]int, len(p.providers))
+	for i := range order {
+		order[i] = i
+	}
+	if !p.adaptive && p.weights == nil {
+		return order
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	failing := func(i int) bool {
+		return p.adaptive && !p.lastFailed[i].IsZero() && now.Sub(p.lastFailed[i]) < p.recheck
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if failingA, failingB := failing(order[a]), failing(order[b]); failingA != failingB {
+			return failingB
+		}
+		return p.weight(order[a]) > p.weight(order[b])
+	})
+	return order
+}
+
+// weight returns the preference weight of the i-th provider
+func (p *fallbackProvider) weight(i int) float64 {
+	if i < len(p.weights) {
+		return p.weights[i]
+	}
+	return 0
+}
+
+func (p *fallbackProvider) record(i int, err error) {
+	if !p.adaptive {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		p.lastFailed[i] = time.Time{}
+	} else {
+		p.lastFailed[i] = time.Now()
+	}
+}
+
+func (p *fallbackProvider) CloseIdleConnections() {
+	for _, provider := range p.providers {
+		closeIdleConnections(provider)
+	}
+}
@@ -0,0 +1,122 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackProvider(t *testing.T) {
+	failure := errors.New("failure")
+	var primary, secondary int32
+	provider := NewFallbackProvider([]Provider{
+		countingProvider(1, failure, &primary),
+		countingProvider(0, nil, &secondary),
+	})
+
+	// 1. the next provider is used when one fails
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", prices.Fast.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&secondary))
+
+	// 2. without adaptive ordering the primary provider is always tried first
+	_, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&primary))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&secondary))
+
+	// 3. the last error is returned if every provider fails
+	last := errors.New("last")
+	primary, secondary = 0, 0
+	provider = NewFallbackProvider([]Provider{
+		countingProvider(1, failure, &primary),
+		countingProvider(1, last, &secondary),
+	})
+	_, err = provider.Fetch(context.Background())
+	assert.Equal(t, last, err)
+
+	// 4. there must be a provider
+	_, err = NewFallbackProvider(nil).Fetch(context.Background())
+	assert.Error(t, err)
+}
+
+func TestFallbackProviderAdaptiveOrder(t *testing.T) {
+	failure := errors.New("failure")
+	var primary, secondary int32
+	provider := NewFallbackProvider([]Provider{
+		countingProvider(1, failure, &primary),
+		countingProvider(0, nil, &secondary),
+	}, WithAdaptiveOrder(30*time.Millisecond))
+
+	// 1. a failed provider is skipped while others succeed
+	for i := 0; i < 3; i++ {
+		_, err := provider.Fetch(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&primary))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&secondary))
+
+	// 2. and preferred again once it recovers after the recheck interval
+	time.Sleep(40 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		_, err := provider.Fetch(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&primary))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&secondary))
+}
+
+func TestFallbackProviderPreferenceWeights(t *testing.T) {
+	failure := errors.New("failure")
+	var paid, node, backup int32
+	providers := []Provider{
+		countingProvider(0, nil, &paid),
+		countingProvider(1, failure, &node),
+		countingProvider(0, nil, &backup),
+	}
+
+	// 1. the provider with the highest weight is tried first, and the rest by weight
+	provider := NewFallbackProvider(providers, WithPreferenceWeights([]float64{1, 10}))
+	_, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&node))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&paid))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&backup))
+
+	// 2. without health tracking the preferred provider is tried on every call
+	_, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&node))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&paid))
+
+	// 3. with adaptive ordering a failing preferred provider is skipped until the recheck interval has passed
+	paid, node = 0, 0
+	provider = NewFallbackProvider(providers, WithPreferenceWeights([]float64{1, 10}), WithAdaptiveOrder(time.Minute))
+	for i := 0; i < 3; i++ {
+		_, err = provider.Fetch(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&node))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&paid))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&backup))
+}
+
+func TestFallbackProviderFailFast(t *testing.T) {
+	failure := errors.New("failure")
+	var primary, secondary int32
+	provider := NewFallbackProvider([]Provider{
+		countingProvider(1, failure, &primary),
+		countingProvider(0, nil, &secondary),
+	})
+
+	// 1. only the first provider is tried in fail fast mode
+	_, err := provider.Fetch(ContextWithFailFast(context.Background()))
+	assert.Equal(t, failure, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&secondary))
+}
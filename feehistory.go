@@ -0,0 +1,322 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// defaultFeeHistoryBlocks is the number of recent blocks a FeeHistoryProvider requests if none is set
+	defaultFeeHistoryBlocks = 20
+
+	// maxProjectedBlocks bounds how far ProjectBaseFee projects the base fee, beyond which the projection is meaningless
+	maxProjectedBlocks = 1024
+
+	// defaultBaseFeeBlocks is the number of full blocks the max fee of a FeeHistoryProvider covers if none is set, which
+	// about doubles the base fee
+	defaultBaseFeeBlocks = 6
+)
+
+// FeeHistoryProvider is a Provider that computes EIP-1559 fees from the eth_feeHistory JSON-RPC method of an Ethereum
+// node. The priority fee of each priority level is the median, across recent blocks, of the priority fees paid at a
+// percentile of each block, the percentile returned by PriorityToPercentile unless overridden. The max fee covers the
+// base fee of the next block rising for BaseFeeBlocks full blocks, plus the priority fee, as computed by MaxFeePerGas.
+//
+// The legacy gas price of each priority level is the base fee of the next block plus its priority fee. The base fee of
+// the next block is the one reported by eth_feeHistory, or that of the pending block if PendingBaseFeeMultiplier is
+// set.
+type FeeHistoryProvider struct {
+	// URL is the JSON-RPC endpoint of the node.
+	URL string
+
+	// Blocks is the number of recent blocks the priority fees are taken from, it defaults to 20.
+	Blocks int
+
+	// Percentiles overrides the percentile, between 0 and 100, of the priority fees of a block that priority levels
+	// are served from.
+	Percentiles map[GasPriority]float64
+
+	// BaseFeeBlocks is the number of full blocks the max fee covers the rise of the base fee for, it defaults to 6,
+	// which about doubles the base fee.
+	BaseFeeBlocks int
+
+	// PendingBaseFeeMultiplier, if set, reads the base fee of the pending block with eth_getBlockByNumber, and computes
+	// the max fee as that base fee times the multiplier, rounded up, plus the priority fee, instead of covering
+	// BaseFeeBlocks full blocks. It must be at least 1, such as 2 to cover the base fee doubling.
+	PendingBaseFeeMultiplier float64
+
+	// HTTPClient is used to make requests, it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// BlockTime is the block time of the chain of the node, such as 12 seconds for Ethereum, which is reported in
+	// GasPrices.BlockTime since the node doesn't report it.
+	BlockTime time.Duration
+}
+
+// pendingBlock holds the fields of the pending block returned by eth_getBlockByNumber that are used
+type pendingBlock struct {
+	BaseFeePerGas string `json:"baseFeePerGas"`
+}
+
+type feeHistory struct {
+	// BaseFeePerGas holds the base fee of each block, followed by that of the next block
+	BaseFeePerGas []string `json:"baseFeePerGas"`
+
+	// GasUsedRatio holds the fraction of the gas limit each block used
+	GasUsedRatio []float64 `json:"gasUsedRatio"`
+
+	// Reward holds the priority fees paid at each requested percentile of each block
+	Reward [][]string `json:"reward"`
+}
+
+// Fetch loads the fee history of recent blocks from the node and computes fees from it.
+func (p *FeeHistoryProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	blocks := p.Blocks
+	if blocks == 0 {
+		blocks = defaultFeeHistoryBlocks
+	}
+	if blocks < 0 {
+		return GasPrices{}, errors.New("eth: number of blocks must not be negative")
+	}
+	multiplier := p.PendingBaseFeeMultiplier
+	if multiplier != 0 && !(multiplier >= 1 && !math.IsInf(multiplier, 1)) {
+		return GasPrices{}, errors.New("eth: pending base fee multiplier must be finite and at least 1")
+	}
+
+	// the node requires the percentiles in ascending order, but priority levels may share one
+	columns := make(map[GasPriority]int, len(priorityOrder))
+	var percentiles []float64
+	for _, priority := range priorityOrder {
+		percentile := p.percentile(priority)
+		if !(percentile >= 0 && percentile <= 100) {
+			return GasPrices{}, errors.New("eth: percentile must be between 0 and 100")
+		}
+		percentiles = append(percentiles, percentile)
+	}
+	sort.Float64s(percentiles)
+	percentiles = uniqueFloats(percentiles)
+	for _, priority := range priorityOrder {
+		columns[priority] = sort.SearchFloat64s(percentiles, p.percentile(priority))
+	}
+
+	blockCount, _ := encodeQuantity(big.NewInt(int64(blocks)))
+	var history feeHistory
+	params := []interface{}{blockCount, "latest", percentiles}
+	if err := callRPC(ctx, p.client(), p.URL, "eth_feeHistory", params, &history); err != nil {
+		return GasPrices{}, err
+	}
+	var pendingBaseFee *big.Int
+	if multiplier != 0 {
+		var block pendingBlock
+		params := []interface{}{"pending", false}
+		if err := callRPC(ctx, p.client(), p.URL, "eth_getBlockByNumber", params, &block); err != nil {
+			return GasPrices{}, err
+		}
+		if block.BaseFeePerGas == "" {
+			return GasPrices{}, errors.New("eth: pending block has no base fee")
+		}
+		var err error
+		if pendingBaseFee, err = decodeQuantity(block.BaseFeePerGas); err != nil {
+			return GasPrices{}, err
+		}
+	}
+	return p.newGasPrices(history, columns, pendingBaseFee)
+}
+
+// ProjectBaseFee estimates the base fee of the block that is blocks blocks after the latest block, such as 1 for the
+// next block, to help decide whether to submit a transaction now or wait. The base fee of the next block is known
+// exactly, and later blocks are projected from it assuming that they use the mean fraction of their gas limit used by
+// the recent Blocks blocks, each moving the base fee by up to 12.5% as EIP-1559 does.
+//
+// It is only an estimate: demand changes from block to block, so the further out the block, the less reliable the
+// projection. The true base fee is always within the range given by rising or falling 12.5% per block.
+func (p *FeeHistoryProvider) ProjectBaseFee(ctx context.Context, blocks int) (*big.Int, error) {
+	if blocks < 1 || blocks > maxProjectedBlocks {
+		return nil, errors.New("eth: number of blocks to project must be between 1 and 1024")
+	}
+	historyBlocks := p.Blocks
+	if historyBlocks == 0 {
+		historyBlocks = defaultFeeHistoryBlocks
+	}
+	if historyBlocks < 0 {
+		return nil, errors.New("eth: number of blocks must not be negative")
+	}
+
+	blockCount, _ := encodeQuantity(big.NewInt(int64(historyBlocks)))
+	var history feeHistory
+	params := []interface{}{blockCount, "latest", []float64{}}
+	if err := callRPC(ctx, p.client(), p.URL, "eth_feeHistory", params, &history); err != nil {
+		return nil, err
+	}
+	if len(history.BaseFeePerGas) == 0 {
+		return nil, errors.New("eth: no base fee in fee history")
+	}
+	baseFee, err := decodeQuantity(history.BaseFeePerGas[len(history.BaseFeePerGas)-1])
+	if err != nil {
+		return nil, err
+	}
+	if blocks == 1 || len(history.GasUsedRatio) == 0 {
+		return baseFee, nil
+	}
+
+	var used float64
+	for _, ratio := range history.GasUsedRatio {
+		used += ratio
+	}
+	used /= float64(len(history.GasUsedRatio))
+	if !(used >= 0) {
+		return nil, errors.New("eth: invalid gas used ratio in fee history")
+	}
+	if used > 1 {
+		used = 1
+	}
+
+	// a block using the target of half its gas limit leaves the base fee unchanged, a full block raises it by 12.5%
+	// and an empty one lowers it by 12.5%
+	change := new(big.Rat).SetFloat64((2*used - 1) / 8)
+	factor := change.Add(change, big.NewRat(1, 1))
+	fee := new(big.Rat).SetInt(baseFee)
+	for i := 1; i < blocks; i++ {
+		fee.Mul(fee, factor)
+		// each projected base fee is rounded down to a whole wei, like the changes of the protocol
+		fee.SetInt(new(big.Int).Quo(fee.Num(), fee.Denom()))
+	}
+	return fee.Num(), nil
+}
+
+// CloseIdleConnections closes idle connections of the underlying HTTP client.
+func (p *FeeHistoryProvider) CloseIdleConnections() {
+	p.client().CloseIdleConnections()
+}
+
+func (p *FeeHistoryProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
+
+func (p *FeeHistoryProvider) percentile(priority GasPriority) float64 {
+	if percentile, ok := p.Percentiles[priority]; ok {
+		return percentile
+	}
+	return PriorityToPercentile(priority)
+}
+
+// newGasPrices computes the fees of each priority level from the rewards in its column of the fee history, on top of
+// pendingBaseFee if it is set and otherwise of the next base fee in the fee history
+func (p *FeeHistoryProvider) newGasPrices(
+	history feeHistory,
+	columns map[GasPriority]int,
+	pendingBaseFee *big.Int,
+) (GasPrices, error) {
+	baseFee := pendingBaseFee
+	if baseFee == nil {
+		if len(history.BaseFeePerGas) == 0 {
+			return GasPrices{}, errors.New("eth: no base fee in fee history")
+		}
+		var err error
+		if baseFee, err = decodeQuantity(history.BaseFeePerGas[len(history.BaseFeePerGas)-1]); err != nil {
+			return GasPrices{}, err
+		}
+	}
+	var err error
+	rewards := make([][]*big.Int, len(history.Reward))
+	for i, block := range history.Reward {
+		rewards[i] = make([]*big.Int, len(block))
+		for j, reward := range block {
+			if rewards[i][j], err = decodeQuantity(reward); err != nil {
+				return GasPrices{}, err
+			}
+		}
+	}
+
+	baseFeeBlocks := p.BaseFeeBlocks
+	if baseFeeBlocks == 0 {
+		baseFeeBlocks = defaultBaseFeeBlocks
+	}
+	result := GasPrices{
+		BaseFee:   baseFee,
+		Fees:      make(map[GasPriority]FeeSuggestion, len(priorityOrder)),
+		BlockTime: p.BlockTime,
+	}
+	for _, priority := range priorityOrder {
+		tip, err := MedianReward(rewards, columns[priority])
+		if err != nil {
+			return GasPrices{}, err
+		}
+		var maxFee *big.Int
+		if pendingBaseFee != nil {
+			maxFee = scaleBaseFee(baseFee, p.PendingBaseFeeMultiplier)
+			maxFee.Add(maxFee, tip)
+		} else if maxFee, err = MaxFeePerGas(baseFee, baseFeeBlocks, tip); err != nil {
+			return GasPrices{}, err
+		}
+		price := new(big.Int).Add(baseFee, tip)
+
+		switch priority {
+		case GasPriorityFast:
+			result.Fast = price
+		case GasPriorityFastest:
+			result.Fastest = price
+		case GasPrioritySafeLow:
+			result.SafeLow = price
+		case GasPriorityAverage:
+			result.Average = price
+		}
+		result.Fees[priority] = FeeSuggestion{MaxFeePerGas: maxFee, MaxPriorityFeePerGas: tip}
+	}
+	return result, nil
+}
+
+// scaleBaseFee returns baseFee times multiplier, rounded up to a whole wei
+func scaleBaseFee(baseFee *big.Int, multiplier float64) *big.Int {
+	scaled := new(big.Rat).SetFloat64(multiplier)
+	scaled.Mul(scaled, new(big.Rat).SetInt(baseFee))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return quotient
+}
+
+// MedianReward returns the median, across blocks, of the priority fee at index in the rewards of each block, as
+// returned in the reward array of eth_feeHistory for the requested percentiles. Blocks without a reward at index are
+// skipped, and the median of an even number of rewards is the mean of the middle two, rounded down.
+func MedianReward(rewards [][]*big.Int, index int) (*big.Int, error) {
+	var column []*big.Int
+	for _, block := range rewards {
+		if index >= 0 && index < len(block) && block[index] != nil {
+			column = append(column, block[index])
+		}
+	}
+	if len(column) == 0 {
+		return nil, errors.New("eth: no rewards in fee history")
+	}
+	sort.Slice(column, func(i, j int) bool {
+		return column[i].Cmp(column[j]) < 0
+	})
+
+	middle := len(column) / 2
+	if len(column)%2 == 1 {
+		return new(big.Int).Set(column[middle]), nil
+	}
+	median := new(big.Int).Add(column[middle-1], column[middle])
+	return median.Rsh(median, 1), nil
+}
+
+// uniqueFloats removes consecutive duplicates from sorted values
+func uniqueFloats(values []float64) []float64 {
+	unique := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
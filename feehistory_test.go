@@ -0,0 +1,188 @@
+package gas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveFeeHistory responds to eth_feeHistory with base fees and rewards in gwei, recording the requested percentiles
+func serveFeeHistory(t *testing.T, baseFees []int64, rewards [][]int64, percentiles *[]float64) http.HandlerFunc {
+	gwei := func(values []int64) string {
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = fmt.Sprintf(`"0x%x"`, value*1e9)
+		}
+		return "[" + strings.Join(quoted, ",") + "]"
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "eth_feeHistory", request.Method)
+		require.Len(t, request.Params, 3)
+		require.Equal(t, `"0x3"`, string(request.Params[0]))
+		require.NoError(t, json.Unmarshal(request.Params[2], percentiles))
+
+		blocks := make([]string, len(rewards))
+		for i, block := range rewards {
+			blocks[i] = gwei(block)
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 1, "result": {"oldestBlock": "0x1", "baseFeePerGas": %s, `+
+			`"gasUsedRatio": [0.5, 0.5, 0.5], "reward": [%s]}}`, gwei(baseFees), strings.Join(blocks, ","))
+	}
+}
+
+func TestFeeHistoryProvider(t *testing.T) {
+	var percentiles []float64
+	rewards := [][]int64{{1, 2, 3, 4}, {1, 2, 5, 6}, {3, 4, 4, 8}}
+	server := httptest.NewServer(serveFeeHistory(t, []int64{10, 11, 12, 13}, rewards, &percentiles))
+	defer server.Close()
+
+	// 1. priority fees are the median of the rewards at the percentile of each level, on top of the next base fee
+	provider := &FeeHistoryProvider{URL: server.URL, Blocks: 3}
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{35, 60, 90, 95}, percentiles)
+	assert.Equal(t, "13000000000", prices.BaseFee.String())
+	assert.Equal(t, "14000000000", prices.SafeLow.String())
+	assert.Equal(t, "15000000000", prices.Average.String())
+	assert.Equal(t, "17000000000", prices.Fast.String())
+	assert.Equal(t, "19000000000", prices.Fastest.String())
+
+	// 2. the max fee covers the base fee rising for six full blocks
+	expected, err := MaxFeePerGas(big.NewInt(13e9), 6, big.NewInt(4e9))
+	require.NoError(t, err)
+	fast := prices.Fees[GasPriorityFast]
+	assert.Equal(t, expected, fast.MaxFeePerGas)
+	assert.Equal(t, "4000000000", fast.MaxPriorityFeePerGas.String())
+
+	// 3. levels sharing a percentile request it once
+	provider.Percentiles = map[GasPriority]float64{GasPriorityFast: 60}
+	provider.BaseFeeBlocks = 1
+	prices, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{35, 60, 95}, percentiles)
+	assert.Equal(t, "15000000000", prices.Fast.String())
+	assert.Equal(t, "16625000000", prices.Fees[GasPriorityFast].MaxFeePerGas.String())
+
+	// 4. invalid percentiles and block counts are rejected
+	provider.Percentiles = map[GasPriority]float64{GasPriorityFast: 101}
+	_, err = provider.Fetch(context.Background())
+	assert.Error(t, err)
+	_, err = (&FeeHistoryProvider{URL: server.URL, Blocks: -1}).Fetch(context.Background())
+	assert.Error(t, err)
+}
+
+func TestFeeHistoryProviderPendingBaseFee(t *testing.T) {
+	var percentiles []float64
+	history := serveFeeHistory(t, []int64{10, 11, 12, 13}, [][]int64{{1, 2, 3, 4}}, &percentiles)
+	pendingBaseFee := `"0x37e11d601"` // 15000000001 wei
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		if !strings.Contains(string(body), "eth_getBlockByNumber") {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			history(w, r)
+			return
+		}
+		assert.Contains(t, string(body), `"params":["pending",false]`)
+		_, _ = fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 1, "result": {"number": "0x2", "baseFeePerGas": %s}}`, pendingBaseFee)
+	}))
+	defer server.Close()
+
+	// 1. the max fee is the pending base fee times the multiplier, rounded up, plus the priority fee
+	provider := &FeeHistoryProvider{URL: server.URL, Blocks: 3, PendingBaseFeeMultiplier: 1.5}
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "15000000001", prices.BaseFee.String())
+	assert.Equal(t, "18000000001", prices.Fast.String())
+	fast := prices.Fees[GasPriorityFast]
+	assert.Equal(t, "3000000000", fast.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "25500000002", fast.MaxFeePerGas.String())
+
+	// 2. a pending block without a base fee is an error
+	pendingBaseFee = "null"
+	_, err = provider.Fetch(context.Background())
+	assert.Error(t, err)
+
+	// 3. multipliers below 1 are rejected
+	provider.PendingBaseFeeMultiplier = 0.5
+	_, err = provider.Fetch(context.Background())
+	assert.Error(t, err)
+}
+
+func TestFeeHistoryProviderProjectBaseFee(t *testing.T) {
+	gasUsedRatio := "[1, 1, 1]"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"params":["0x3","latest",[]]`)
+		_, _ = fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 1, "result": {"oldestBlock": "0x1", `+
+			`"baseFeePerGas": ["0x2540be400", "0x28fa6ae00", "0x2cb417800", "0x306dc4200"], "gasUsedRatio": %s}}`,
+			gasUsedRatio)
+	}))
+	defer server.Close()
+	provider := &FeeHistoryProvider{URL: server.URL, Blocks: 3}
+
+	// 1. the base fee of the next block is the one reported by the node
+	fee, err := provider.ProjectBaseFee(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "13000000000", fee.String())
+
+	// 2. later blocks follow the recent use of the gas limit, up to 12.5% per block
+	fee, err = provider.ProjectBaseFee(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, "16453125000", fee.String())
+	gasUsedRatio = "[0, 0.25, 0.5]"
+	fee, err = provider.ProjectBaseFee(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "12187500000", fee.String())
+
+	// 3. blocks at the gas target leave the base fee unchanged
+	gasUsedRatio = "[0.5, 0.5, 0.5]"
+	fee, err = provider.ProjectBaseFee(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, "13000000000", fee.String())
+
+	// 4. the number of blocks must be positive and bounded
+	_, err = provider.ProjectBaseFee(context.Background(), 0)
+	assert.Error(t, err)
+	_, err = provider.ProjectBaseFee(context.Background(), 1025)
+	assert.Error(t, err)
+}
+
+func TestMedianReward(t *testing.T) {
+	rewards := [][]*big.Int{
+		{big.NewInt(1), big.NewInt(10)},
+		{big.NewInt(4), big.NewInt(30)},
+		{big.NewInt(2)},
+		nil,
+	}
+
+	// 1. the median of an odd number of rewards is the middle one
+	median, err := MedianReward(rewards, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "2", median.String())
+
+	// 2. blocks without the reward are skipped, and an even number of rewards averages the middle two
+	median, err = MedianReward(rewards, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "20", median.String())
+
+	// 3. an empty column is an error
+	_, err = MedianReward(rewards, 2)
+	assert.Error(t, err)
+}
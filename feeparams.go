@@ -0,0 +1,177 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// FeeParams holds ready-to-use fee parameters in wei for a transaction expected to confirm within a target wait, for
+// both legacy and EIP-1559 transactions.
+type FeeParams struct {
+	// GasPrice is the gas price of a legacy transaction.
+	GasPrice *big.Int
+
+	// MaxFeePerGas and MaxPriorityFeePerGas are the fee parameters of an EIP-1559 transaction.
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+
+	// EstimatedWait is the estimated time for a transaction at GasPrice to be mined.
+	EstimatedWait time.Duration
+}
+
+// FeeParamsForWait returns the cheapest fee parameters expected to confirm within maxWait. The gas price is taken
+// from the prediction table if there is one, like PriceForMaxWait, and otherwise from the cheapest priority level whose
+// wait time is at or under maxWait, like PriorityForTargetWait.
+//
+// The EIP-1559 fees are those reported for that priority level if there are any. Otherwise the priority fee is the
+// gas price above the base fee, and the max fee covers the base fee rising for six full blocks as computed by
+// MaxFeePerGas, or both are the gas price if the base fee isn't reported either, which pays the same as a legacy
+// transaction. The returned values are copies and may be modified freely.
+//
+// An error is returned if there are neither predictions nor wait times, or no price is expected to confirm in time.
+func (p GasPrices) FeeParamsForWait(maxWait time.Duration) (FeeParams, error) {
+	var (
+		params   FeeParams
+		priority GasPriority
+	)
+	switch {
+	case len(p.Predictions) > 0:
+		for _, prediction := range p.Predictions {
+			if prediction.Wait <= maxWait && (params.GasPrice == nil || prediction.Price.Cmp(params.GasPrice) < 0) {
+				params.GasPrice, params.EstimatedWait = prediction.Price, prediction.Wait
+			}
+		}
+		if params.GasPrice == nil {
+			return FeeParams{}, errors.New("eth: no gas price is expected to confirm within the max wait")
+		}
+	case len(p.Waits) > 0:
+		var err error
+		if priority, err = p.PriorityForTargetWait(maxWait); err != nil {
+			return FeeParams{}, err
+		}
+		if params.GasPrice, err = p.price(priority); err != nil {
+			return FeeParams{}, err
+		}
+		params.EstimatedWait = p.Waits[priority]
+	default:
+		return FeeParams{}, errors.New("eth: response does not include a prediction table or wait times")
+	}
+	params.GasPrice = new(big.Int).Set(params.GasPrice)
+	return p.withFees(params, priority)
+}
+
+// FeeParams returns the legacy and EIP-1559 fee parameters of priority, with its wait estimate. The EIP-1559 fees are
+// those reported for the priority level if there are any, and are otherwise derived from its price and the base fee
+// as described for FeeParamsForWait. The returned values are copies and may be modified freely.
+func (p GasPrices) FeeParams(priority GasPriority) (FeeParams, error) {
+	price, err := p.Price(priority)
+	if err != nil {
+		return FeeParams{}, err
+	}
+	return p.withFees(FeeParams{GasPrice: price, EstimatedWait: p.Waits[priority]}, priority)
+}
+
+// withFees sets the EIP-1559 fees of params, whose gas price is that of priority, or of no priority level if it is
+// taken from the prediction table
+func (p GasPrices) withFees(params FeeParams, priority GasPriority) (FeeParams, error) {
+	fee, ok := p.Fees[priority]
+	switch {
+	case ok && fee.MaxFeePerGas != nil && fee.MaxPriorityFeePerGas != nil:
+		params.MaxFeePerGas = new(big.Int).Set(fee.MaxFeePerGas)
+		params.MaxPriorityFeePerGas = new(big.Int).Set(fee.MaxPriorityFeePerGas)
+	case p.BaseFee != nil:
+		tip := new(big.Int).Sub(params.GasPrice, p.BaseFee)
+		if tip.Sign() < 0 {
+			tip.SetInt64(0)
+		}
+		maxFee, err := MaxFeePerGas(p.BaseFee, defaultBaseFeeBlocks, tip)
+		if err != nil {
+			return FeeParams{}, err
+		}
+		params.MaxFeePerGas, params.MaxPriorityFeePerGas = maxFee, tip
+	default:
+		params.MaxFeePerGas = new(big.Int).Set(params.GasPrice)
+		params.MaxPriorityFeePerGas = new(big.Int).Set(params.GasPrice)
+	}
+	return params, nil
+}
+
+// FeeParamsForWait returns the cheapest legacy and EIP-1559 fee parameters expected to confirm within maxWait, based on
+// the prices reported by the provider, as described for GasPrices.FeeParamsForWait. Fees derived from the base fee are
+// rounded like the prices if the client was configured with WithRoundTo, and wait times are estimated by the model set
+// with WithCustomConfirmationModel if any.
+func (c *Client) FeeParamsForWait(maxWait time.Duration) (FeeParams, error) {
+	prices, err := c.loadWaits()
+	if err != nil {
+		return FeeParams{}, err
+	}
+	params, err := prices.FeeParamsForWait(maxWait)
+	if err != nil {
+		return FeeParams{}, err
+	}
+	return c.roundFees(params), nil
+}
+
+// SuggestFees returns the legacy and EIP-1559 fee parameters of priority with its wait estimate, as described for
+// GasPrices.FeeParams, ready for the GasFeeCap and GasTipCap of a dynamic fee transaction. Fees derived from the base
+// fee are rounded like the prices if the client was configured with WithRoundTo, and the wait is estimated by the
+// model set with WithCustomConfirmationModel if any.
+func (c *Client) SuggestFees(priority GasPriority) (FeeParams, error) {
+	prices, err := c.loadWaits()
+	if err != nil {
+		return FeeParams{}, err
+	}
+	params, err := prices.FeeParams(priority)
+	if err != nil {
+		return FeeParams{}, err
+	}
+	return c.roundFees(params), nil
+}
+
+// FeeSuggester is a function that returns the fee parameters of a priority level, as returned by NewFeeSuggester.
+type FeeSuggester func(GasPriority) (FeeParams, error)
+
+// NewFeeSuggester is like NewGasPriceSuggester, but the returned function suggests the fee parameters of a priority
+// level, as SuggestFees does, from prices cached until they are older than maxResultAge.
+func (c *Client) NewFeeSuggester(maxResultAge time.Duration) (FeeSuggester, error) {
+	if maxResultAge < 0 {
+		return nil, errors.New("eth: max result age must not be negative")
+	}
+	prices, err := c.fetch(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	m := gasPriceManager{
+		latestPrices: prices,
+		fetchedAt:    c.clock(),
+		now:          c.now,
+		maxResultAge: maxResultAge,
+		fetch:        c.fetch,
+	}
+	return func(priority GasPriority) (FeeParams, error) {
+		prices, err := m.latest(context.Background())
+		if err != nil {
+			return FeeParams{}, err
+		}
+		if c.confirmationModel != nil {
+			prices = prices.withConfirmationModel(c.confirmationModel)
+		}
+		params, err := prices.FeeParams(priority)
+		if err != nil {
+			return FeeParams{}, err
+		}
+		return c.roundFees(params), nil
+	}, nil
+}
+
+// roundFees rounds the EIP-1559 fees of params like the prices, if the client was configured with WithRoundTo
+func (c *Client) roundFees(params FeeParams) FeeParams {
+	if c.roundTo != nil {
+		params.MaxFeePerGas = roundTo(params.MaxFeePerGas, c.roundTo, c.roundingMode)
+		params.MaxPriorityFeePerGas = roundTo(params.MaxPriorityFeePerGas, c.roundTo, c.roundingMode)
+	}
+	return params
+}
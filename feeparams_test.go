@@ -0,0 +1,147 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeParamsForWait(t *testing.T) {
+	prices := GasPrices{
+		SafeLow: big.NewInt(10e9),
+		Average: big.NewInt(15e9),
+		Fast:    big.NewInt(20e9),
+		Fastest: big.NewInt(25e9),
+		Waits: map[GasPriority]time.Duration{
+			GasPrioritySafeLow: 10 * time.Minute,
+			GasPriorityAverage: 5 * time.Minute,
+			GasPriorityFast:    2 * time.Minute,
+			GasPriorityFastest: 30 * time.Second,
+		},
+	}
+
+	// 1. without a base fee, both EIP-1559 fees are the gas price of the cheapest level confirming in time
+	params, err := prices.FeeParamsForWait(3 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", params.GasPrice.String())
+	assert.Equal(t, "20000000000", params.MaxFeePerGas.String())
+	assert.Equal(t, "20000000000", params.MaxPriorityFeePerGas.String())
+	assert.Equal(t, 2*time.Minute, params.EstimatedWait)
+
+	// 2. the fees reported for the level are used if there are any
+	prices.Fees = map[GasPriority]FeeSuggestion{
+		GasPriorityFast: {MaxFeePerGas: big.NewInt(30e9), MaxPriorityFeePerGas: big.NewInt(2e9)},
+	}
+	params, err = prices.FeeParamsForWait(3 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "30000000000", params.MaxFeePerGas.String())
+	assert.Equal(t, "2000000000", params.MaxPriorityFeePerGas.String())
+
+	// 3. otherwise they are derived from the base fee, with a tip of the gas price above it
+	prices.BaseFee = big.NewInt(9e9)
+	params, err = prices.FeeParamsForWait(10 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "10000000000", params.GasPrice.String())
+	assert.Equal(t, "1000000000", params.MaxPriorityFeePerGas.String())
+	expected, err := MaxFeePerGas(big.NewInt(9e9), 6, big.NewInt(1e9))
+	require.NoError(t, err)
+	assert.Equal(t, expected, params.MaxFeePerGas)
+
+	// 4. the prediction table is preferred, and a price below the base fee has no tip
+	prices.Predictions = []PricePrediction{
+		{Price: big.NewInt(8e9), Wait: 20 * time.Minute},
+		{Price: big.NewInt(12e9), Wait: 4 * time.Minute},
+		{Price: big.NewInt(18e9), Wait: time.Minute},
+	}
+	params, err = prices.FeeParamsForWait(5 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "12000000000", params.GasPrice.String())
+	assert.Equal(t, 4*time.Minute, params.EstimatedWait)
+	params, err = prices.FeeParamsForWait(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "8000000000", params.GasPrice.String())
+	assert.Equal(t, "0", params.MaxPriorityFeePerGas.String())
+
+	// 5. no price confirming in time, and no estimates at all, are errors
+	_, err = prices.FeeParamsForWait(time.Second)
+	assert.Error(t, err)
+	_, err = GasPrices{Fast: big.NewInt(20e9)}.FeeParamsForWait(time.Minute)
+	assert.Error(t, err)
+}
+
+func TestClientFeeParamsForWait(t *testing.T) {
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{
+			SafeLow: big.NewInt(10e9),
+			Average: big.NewInt(15e9),
+			Fast:    big.NewInt(20e9),
+			Fastest: big.NewInt(25e9),
+			Waits:   map[GasPriority]time.Duration{GasPriorityFast: time.Minute},
+			BaseFee: big.NewInt(18500000001),
+		}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithRoundTo(1), WithRoundingMode(RoundUp))
+	require.NoError(t, err)
+
+	// 1. fees derived from the base fee are rounded like the prices
+	params, err := c.FeeParamsForWait(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", params.GasPrice.String())
+	assert.Equal(t, "2000000000", params.MaxPriorityFeePerGas.String())
+	assert.Equal(t, int64(0), new(big.Int).Mod(params.MaxFeePerGas, big.NewInt(1e9)).Int64())
+	assert.Equal(t, time.Minute, params.EstimatedWait)
+}
+
+func TestSuggestFees(t *testing.T) {
+	var calls int32
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		atomic.AddInt32(&calls, 1)
+		return GasPrices{
+			SafeLow: big.NewInt(10e9),
+			Fast:    big.NewInt(20e9),
+			Waits:   map[GasPriority]time.Duration{GasPriorityFast: time.Minute},
+			BaseFee: big.NewInt(16e9),
+			Fees: map[GasPriority]FeeSuggestion{
+				GasPrioritySafeLow: {MaxFeePerGas: big.NewInt(30e9), MaxPriorityFeePerGas: big.NewInt(1e9)},
+			},
+		}, nil
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	// 1. the reported fees of a level are used as is
+	params, err := c.SuggestFees(GasPrioritySafeLow)
+	require.NoError(t, err)
+	assert.Equal(t, "30000000000", params.MaxFeePerGas.String())
+	assert.Equal(t, "1000000000", params.MaxPriorityFeePerGas.String())
+
+	// 2. otherwise they are derived from its price and the base fee, along with its wait
+	params, err = c.SuggestFees(GasPriorityFast)
+	require.NoError(t, err)
+	expected, err := MaxFeePerGas(big.NewInt(16e9), 6, big.NewInt(4e9))
+	require.NoError(t, err)
+	assert.Equal(t, expected, params.MaxFeePerGas)
+	assert.Equal(t, "4000000000", params.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "20000000000", params.GasPrice.String())
+	assert.Equal(t, time.Minute, params.EstimatedWait)
+	_, err = c.SuggestFees(GasPriorityAverage)
+	assert.Error(t, err)
+
+	// 3. a fee suggester serves fees from its cache
+	atomic.StoreInt32(&calls, 0)
+	suggest, err := c.NewFeeSuggester(time.Minute)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		params, err = suggest(GasPriorityFast)
+		require.NoError(t, err)
+		assert.Equal(t, "4000000000", params.MaxPriorityFeePerGas.String())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	_, err = c.NewFeeSuggester(-time.Second)
+	assert.Error(t, err)
+}
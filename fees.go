@@ -0,0 +1,35 @@
+package gas
+
+import (
+	"errors"
+	"math/big"
+)
+
+// MaxFeePerGas returns an EIP-1559 maxFeePerGas in wei that still covers the base fee after it rises by the maximum
+// amount for the given number of blocks, plus the tip. This lets a transaction stay includable if it waits that many
+// blocks while blocks are full.
+//
+// The base fee rises by at most 12.5% per block, rounded down, and by at least 1 wei if it rises at all, so the result
+// is exact rather than an approximation of 1.125 to the power of blocks.
+func MaxFeePerGas(baseFee *big.Int, blocks int, tip *big.Int) (*big.Int, error) {
+	if baseFee == nil || baseFee.Sign() < 0 {
+		return nil, errors.New("eth: base fee must not be negative")
+	}
+	if tip == nil || tip.Sign() < 0 {
+		return nil, errors.New("eth: tip must not be negative")
+	}
+	if blocks < 0 {
+		return nil, errors.New("eth: number of blocks must not be negative")
+	}
+
+	fee := new(big.Int).Set(baseFee)
+	increase := new(big.Int)
+	for i := 0; i < blocks; i++ {
+		increase.Rsh(fee, 3)
+		if increase.Sign() == 0 {
+			increase.SetInt64(1)
+		}
+		fee.Add(fee, increase)
+	}
+	return fee.Add(fee, tip), nil
+}
@@ -0,0 +1,48 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxFeePerGas(t *testing.T) {
+	cases := []struct {
+		baseFee  int64
+		blocks   int
+		tip      int64
+		expected int64
+	}{
+		// 1. no buffer only adds the tip
+		{100e9, 0, 2e9, 102e9},
+		// 2. 12.5% per block
+		{100e9, 1, 2e9, 114500000000},
+		{100e9, 2, 0, 126562500000},
+		// 3. increases are rounded down
+		{9, 1, 0, 10},
+		// and are at least 1 wei
+		{7, 2, 0, 9},
+	}
+
+	for _, c := range cases {
+		fee, err := MaxFeePerGas(big.NewInt(c.baseFee), c.blocks, big.NewInt(c.tip))
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, fee.Int64(), "base fee %d over %d blocks", c.baseFee, c.blocks)
+	}
+
+	// 4. the arguments are not modified
+	baseFee := big.NewInt(100)
+	_, err := MaxFeePerGas(baseFee, 3, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), baseFee.Int64())
+
+	// 5. invalid arguments are rejected
+	_, err = MaxFeePerGas(big.NewInt(-1), 1, big.NewInt(0))
+	assert.Error(t, err)
+	_, err = MaxFeePerGas(big.NewInt(1), -1, big.NewInt(0))
+	assert.Error(t, err)
+	_, err = MaxFeePerGas(big.NewInt(1), 1, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package gas
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func FuzzParseGasPriceToWei(f *testing.F) {
+	for _, seed := range []float64{0, 1, 1.1, 200.5, -1, 1e-300, 1e300, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw float64) {
+		wei, err := parseGasPriceToWei(raw)
+		if err != nil {
+			return
+		}
+
+		// a converted price is never negative, and converts back to the raw value exactly
+		if wei.Sign() < 0 {
+			t.Fatalf("negative price %s for %v", wei, raw)
+		}
+		tenths, _ := new(big.Rat).Quo(new(big.Rat).SetInt(wei), conversionFactor).Float64()
+		if tenths != raw && !(raw == 0 && tenths == 0) {
+			t.Fatalf("%v converted to %s wei, which is %v", raw, wei, tenths)
+		}
+	})
+}
+
+func FuzzNewGasPrices(f *testing.F) {
+	f.Add([]byte(testResponse))
+	f.Add([]byte(`{"fast": 200, "fastest": 250, "safeLow": 100, "average": 150, "fastWait": 0.5,
+		"gasPriceRange": {"4": 200, "NaN": 1, "Inf": 2, "1e400": 3}}`))
+	f.Add([]byte(`{"fast": -1e308, "avgWait": 1e308, "gasPriceRange": {"10": 1e308}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var response ethGasStationResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return
+		}
+		for _, scale := range []InputScale{InputScaleTenthsOfGwei, InputScaleGwei, InputScaleWei} {
+			prices, err := newGasPrices(response, scale)
+			if err != nil {
+				continue
+			}
+
+			// every converted value is valid
+			if err := validatePrices(prices, false); err != nil {
+				t.Fatalf("invalid prices from %q: %v", data, err)
+			}
+			for _, prediction := range prices.Predictions {
+				if prediction.Wait < 0 {
+					t.Fatalf("negative wait from %q", data)
+				}
+			}
+			for _, wait := range prices.Waits {
+				if wait <= 0 {
+					t.Fatalf("non-positive wait from %q", data)
+				}
+			}
+		}
+	})
+}
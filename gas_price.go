@@ -5,10 +5,20 @@
 package gas
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"math/big"
+	"math/bits"
+	"mime"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -22,8 +32,25 @@ const ETHGasStationURL = "https://ethgasstation.info/json/ethgasAPI.json"
 type GasPriority string
 
 // GasPriceSuggester is type alias  for a function that returns a reccomended gas price in base units for a given priority level.
+//
+// The prices are converted once per refresh, and a cache hit returns the converted value without allocating. The
+// returned value is shared between calls and must not be modified, use new(big.Int).Set to get a copy that can be.
+//
+// Each call may see a different response if the cache is refreshed in between. Use Client.Snapshot to read several
+// priority levels from the same response.
 type GasPriceSuggester func(GasPriority) (*big.Int, error)
 
+// SuggestGasPrice calls s(priority), so a GasPriceSuggester can be used as a Suggester.
+func (s GasPriceSuggester) SuggestGasPrice(priority GasPriority) (*big.Int, error) {
+	return s(priority)
+}
+
+// Suggester suggests a gas price in wei for a priority level. It is implemented by Client, Refresher and
+// GasPriceSuggester, so callers can depend on it rather than on one of them, and substitute a fake in their tests.
+type Suggester interface {
+	SuggestGasPrice(priority GasPriority) (*big.Int, error)
+}
+
 const (
 	// GasPriorityFast is the recommended gas price for a transaction to be mined in less than 2 minutes.
 	GasPriorityFast = GasPriority("fast")
@@ -36,23 +63,103 @@ const (
 
 	// GasPriorityAverage is the recommended average gas price for a transaction to be mined in less than 5 minutes.
 	GasPriorityAverage = GasPriority("average")
+
+	// GasPriorityTurbo is a derived level between fast and fastest, for transactions that should be faster than fast
+	// without paying the premium of fastest. It is not reported by providers, its price is the midpoint of the fast and
+	// fastest prices rounded down, after any transform or rounding configured on the client.
+	GasPriorityTurbo = GasPriority("turbo")
 )
 
 // SuggestGasPrice returns a suggested gas price value in wei (base units) for timely transaction execution. It always
-// makes a new call to the ETH Gas Station API. Use NewGasPriceSuggester to leverage cached results.
+// makes a new call to the ETH Gas Station API, unless SetPreferCached is in effect. Use NewGasPriceSuggester to
+// leverage cached results.
 //
 // The returned price depends on the priority specified, and supports all priorities supported by the ETH Gas Station API.
 func SuggestGasPrice(priority GasPriority) (*big.Int, error) {
-	prices, err := loadGasPrices()
-	if err != nil {
-		return nil, err
-	}
-	return parseSuggestedGasPrice(priority, prices)
+	return packageClient().SuggestGasPrice(priority)
+}
+
+// SuggestGasPriceContext is like SuggestGasPrice, but the request made to the API is bound to ctx, so it can be
+// canceled or given a deadline from the scope of the caller.
+func SuggestGasPriceContext(ctx context.Context, priority GasPriority) (*big.Int, error) {
+	return packageClient().SuggestGasPriceContext(ctx, priority)
+}
+
+// SuggestGasPriceRat returns a suggested gas price in gwei as an exact rational number, e.g. 241/2 for 120.5 gwei. It
+// always makes a new call to the ETH Gas Station API, unless SetPreferCached is in effect.
+//
+// Unlike SuggestGasPrice, no rounding or conversion to wei is applied, leaving full control of rounding to the caller.
+func SuggestGasPriceRat(priority GasPriority) (*big.Rat, error) {
+	return packageClient().SuggestGasPriceRat(priority)
+}
+
+// PriceForMaxWait returns the cheapest gas price in wei that is expected to confirm within maxWait, based on the
+// prediction table included in the ETH Gas Station response. It always makes a new call to the ETH Gas Station API,
+// unless SetPreferCached is in effect.
+//
+// An error is returned if the response does not include a prediction table or no price is expected to confirm in time.
+func PriceForMaxWait(maxWait time.Duration) (*big.Int, error) {
+	return packageClient().PriceForMaxWait(maxWait)
+}
+
+// FeeParamsForWait returns the cheapest legacy and EIP-1559 fee parameters expected to confirm within maxWait, based on
+// the prediction table included in the ETH Gas Station response. It always makes a new call to the ETH Gas Station API,
+// unless SetPreferCached is in effect.
+func FeeParamsForWait(maxWait time.Duration) (FeeParams, error) {
+	return packageClient().FeeParamsForWait(maxWait)
+}
+
+// EstimateWait returns the estimated time for a transaction paying price in wei to be mined, interpolated from the
+// prediction table or wait times included in the ETH Gas Station response. It always makes a new call to the ETH Gas
+// Station API, unless SetPreferCached is in effect.
+func EstimateWait(price *big.Int) (time.Duration, error) {
+	return packageClient().EstimateWait(price)
+}
+
+// GasPriceOptions returns every priority level as an option with its price, wait estimate and confidence, sorted by
+// ascending price. It always makes a new call to the ETH Gas Station API, unless SetPreferCached is in effect.
+func GasPriceOptions() ([]GasPriceOption, error) {
+	return packageClient().GasPriceOptions()
+}
+
+// SuggestGasPriceDetails returns the suggested gas price of priority along with its wait estimate and confidence, as
+// reported in the ETH Gas Station response. It always makes a new call to the ETH Gas Station API, unless
+// SetPreferCached is in effect. Use GasPriceOptions for every level of a single response at once.
+func SuggestGasPriceDetails(priority GasPriority) (GasPriceOption, error) {
+	return packageClient().SuggestGasPriceDetails(priority)
+}
+
+// SuggestFees returns the legacy and EIP-1559 fee parameters of priority with its wait estimate, as described for
+// GasPrices.FeeParams. It always makes a new call to the ETH Gas Station API, unless SetPreferCached is in effect.
+// Since the ETH Gas Station API doesn't report EIP-1559 fees, they are derived from the gas price, use a Client with a
+// provider that reports them, such as the FeeHistoryProvider, for better fees.
+func SuggestFees(priority GasPriority) (FeeParams, error) {
+	return packageClient().SuggestFees(priority)
+}
+
+// NewFeeSuggester is like NewGasPriceSuggester, but the returned function suggests the fee parameters of a priority
+// level, as SuggestFees does.
+func NewFeeSuggester(maxResultAge time.Duration) (FeeSuggester, error) {
+	return newPackageClient().NewFeeSuggester(maxResultAge)
+}
+
+// GasEstimates returns all the prices of a single response in wei along with their estimated confirmation times, as
+// described for Client.GasEstimates. It always makes a new call to the ETH Gas Station API, unless SetPreferCached is
+// in effect. Use NewGasEstimator to leverage cached results.
+func GasEstimates() (GasPrices, error) {
+	return packageClient().GasEstimates()
+}
+
+// NewGasEstimator is like NewGasPriceSuggester, but the returned function returns all the prices and wait estimates,
+// as GasEstimates does.
+func NewGasEstimator(maxResultAge time.Duration) (GasEstimator, error) {
+	return newPackageClient().NewGasEstimator(maxResultAge)
 }
 
 // SuggestFastGasPrice is a helper method that calls SuggestGasPrice with GasPriorityFast
 //
-// It always makes a new call to the ETH Gas Station API. Use NewGasPriceSuggester to leverage cached results.
+// It always makes a new call to the ETH Gas Station API, unless SetPreferCached is in effect. Use NewGasPriceSuggester
+// to leverage cached results.
 func SuggestFastGasPrice() (*big.Int, error) {
 	return SuggestGasPrice(GasPriorityFast)
 }
@@ -61,21 +168,29 @@ func SuggestFastGasPrice() (*big.Int, error) {
 // response if it is within the age range defined by maxResultAge.
 //
 // The returned function loads from the cache or pulls a new response if the stored result is older than maxResultAge.
+// A zero maxResultAge pulls a new response on every call, and a negative maxResultAge is rejected.
 func NewGasPriceSuggester(maxResultAge time.Duration) (GasPriceSuggester, error) {
-	prices, err := loadGasPrices()
-	if err != nil {
-		return nil, err
-	}
+	return newPackageClient().NewGasPriceSuggester(maxResultAge)
+}
 
-	m := gasPriceManager{
-		latestResponse: prices,
-		fetchedAt:      time.Now(),
-		maxResultAge:   maxResultAge,
-	}
+// ContextGasPriceSuggester is like GasPriceSuggester, but any request made to the API is bound to ctx.
+type ContextGasPriceSuggester func(ctx context.Context, priority GasPriority) (*big.Int, error)
+
+// SuggestGasPriceContext calls s(ctx, priority).
+func (s ContextGasPriceSuggester) SuggestGasPriceContext(ctx context.Context, priority GasPriority) (*big.Int, error) {
+	return s(ctx, priority)
+}
+
+// NewGasPriceSuggesterContext is like NewGasPriceSuggester, but the first response is loaded with ctx, and the
+// returned function binds the request of each new response to the context it is given.
+func NewGasPriceSuggesterContext(ctx context.Context, maxResultAge time.Duration) (ContextGasPriceSuggester, error) {
+	return newPackageClient().NewGasPriceSuggesterContext(ctx, maxResultAge)
+}
 
-	return func(priority GasPriority) (*big.Int, error) {
-		return m.suggestCachedGasPrice(priority)
-	}, nil
+// WatchGasPrices returns a Watcher that calls the ETH Gas Station API every interval until ctx is done or it is
+// stopped, and sends the prices on a channel whenever they change, as described for Client.WatchGasPrices.
+func WatchGasPrices(ctx context.Context, interval time.Duration) (*Watcher, error) {
+	return newPackageClient().WatchGasPrices(ctx, interval)
 }
 
 type gasPriceManager struct {
@@ -84,100 +199,946 @@ type gasPriceManager struct {
 	fetchedAt    time.Time
 	maxResultAge time.Duration
 
-	latestResponse ethGasStationResponse
+	// capToBlockTime lowers the max result age to the block time reported with the cached prices, if it is shorter
+	capToBlockTime bool
+
+	// maxStaleness is the age up to which a result is served while it is refreshed in the background, it has no effect
+	// unless it is greater than maxResultAge
+	maxStaleness time.Duration
+	refreshing   bool
+
+	// maxServeAge is the age beyond which cached prices are never served, even while they are refreshed in the
+	// background, if set
+	maxServeAge time.Duration
+
+	// waiters receive the prices of the background refresh in progress, and are closed once it is done
+	waiters []chan GasPrices
+
+	// invalidated expires the cached prices until new prices are stored, generation counts invalidations so a
+	// background refresh started before one is discarded
+	invalidated bool
+	generation  int
+
+	// fetch loads new prices, it defaults to the ETH Gas Station API with the default configuration
+	fetch func(context.Context) (GasPrices, error)
+
+	// now returns the current time, it defaults to time.Now
+	now func() time.Time
+
+	// latestPrices holds the prices of every priority level, converted to wei once when they are stored so reads only
+	// return a stored value, or an error for a level the provider didn't report or whose price failed validation
+	latestPrices GasPrices
+
+	// previousPrices are the prices replaced by the most recent refresh that changed them
+	previousPrices GasPrices
+
+	// equal reports whether a refresh left the prices unchanged, it defaults to samePrices
+	equal func(previous, next GasPrices) bool
+
+	// emit reports cache hits and background refreshes, if set
+	emit func(Event)
+}
+
+// cacheState is the freshness of the prices held by a gasPriceManager, which determines how a call is served
+type cacheState int
+
+const (
+	// cacheEmpty holds no prices, calls wait for prices to be fetched
+	cacheEmpty cacheState = iota
+
+	// cacheFresh holds prices within the max result age, which are served as is
+	cacheFresh
+
+	// cacheStale holds prices older than the max result age but within the max staleness, which are served while new
+	// prices are fetched in the background
+	cacheStale
+
+	// cacheExpired holds prices that are too old to be served, calls wait for new prices to be fetched
+	cacheExpired
+)
+
+func (m *gasPriceManager) suggestCachedGasPrice(ctx context.Context, priority GasPriority) (*big.Int, error) {
+	prices, err := m.latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// prices are never modified once cached, so they can be shared with the caller
+	return prices.price(priority)
+}
+
+// latest returns the cached prices, fetching new prices if the stored result is older than the maximum age. If a
+// maximum staleness is set, a result between the two ages is returned while new prices are fetched in the background.
+func (m *gasPriceManager) latest(ctx context.Context) (GasPrices, error) {
+	r, err := m.read(ctx)
+	return r.prices, err
+}
+
+// cacheRead is the outcome of reading the cache
+type cacheRead struct {
+	prices    GasPrices
+	fetchedAt time.Time
+
+	// cached is set if the prices were served from the cache rather than fetched, stale if they were served while
+	// being refreshed in the background
+	cached bool
+	stale  bool
 }
 
-func (m *gasPriceManager) suggestCachedGasPrice(priority GasPriority) (*big.Int, error) {
+// read is like latest, but also reports when the prices were fetched and whether they came from the cache
+func (m *gasPriceManager) read(ctx context.Context) (cacheRead, error) {
+	r, _, err := m.readNotify(ctx, false)
+	return r, err
+}
+
+// readNotify is like read, and if notify is set and stale prices are served, also returns a channel that receives the
+// prices of the background refresh once they are stored. The channel is closed once the refresh is done, without
+// receiving prices if it fails. It is nil unless stale prices are served.
+func (m *gasPriceManager) readNotify(ctx context.Context, notify bool) (cacheRead, <-chan GasPrices, error) {
 	m.Lock()
 	defer m.Unlock()
 
+	switch m.state() {
+	case cacheFresh:
+		m.report(Event{Type: EventCacheHit, Prices: m.latestPrices})
+		return cacheRead{prices: m.latestPrices, fetchedAt: m.fetchedAt, cached: true}, nil, nil
+	case cacheStale:
+		// prices past the max serve age are still refreshed, so the next read can be served again
+		tooOld := checkServeAge(m.clock().Sub(m.fetchedAt), m.maxServeAge)
+		if tooOld == nil {
+			m.report(Event{Type: EventCacheHit, Prices: m.latestPrices, Stale: true})
+		}
+		if !m.refreshing {
+			m.refreshing = true
+			m.report(Event{Type: EventRefresh})
+			go m.refreshInBackground(detach(ctx), m.generation)
+		}
+		if tooOld != nil {
+			return cacheRead{}, nil, tooOld
+		}
+		var refreshed chan GasPrices
+		if notify {
+			refreshed = make(chan GasPrices, 1)
+			m.waiters = append(m.waiters, refreshed)
+		}
+		return cacheRead{prices: m.latestPrices, fetchedAt: m.fetchedAt, cached: true, stale: true}, refreshed, nil
+	}
+
 	// fetch new values if stored result is older than the maximum age
-	if time.Since(m.fetchedAt) > m.maxResultAge {
-		prices, err := loadGasPrices()
-		if err != nil {
-			return nil, err
+	prices, err := m.fetcher()(ctx)
+	if err != nil {
+		return cacheRead{prices: prices}, nil, err
+	}
+	m.store(prices)
+	return cacheRead{prices: m.latestPrices, fetchedAt: m.fetchedAt}, nil, nil
+}
+
+// latestOrStale is like latest, but always fetches new prices unless the cached prices are fresh, and returns the cached
+// prices with stale set if fetching fails, regardless of their age
+func (m *gasPriceManager) latestOrStale(ctx context.Context) (prices GasPrices, stale bool, err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	state := m.state()
+	if state == cacheFresh {
+		return m.latestPrices, false, nil
+	}
+
+	prices, err = m.fetcher()(ctx)
+	if err != nil {
+		if state == cacheEmpty || errors.Is(err, ErrClientClosed) {
+			return prices, false, err
+		}
+		if err := checkServeAge(m.clock().Sub(m.fetchedAt), m.maxServeAge); err != nil {
+			return GasPrices{}, false, err
+		}
+		return m.latestPrices, true, nil
+	}
+	m.store(prices)
+	return m.latestPrices, false, nil
+}
+
+// state returns the freshness of the cached prices, it must be called with the lock held
+func (m *gasPriceManager) state() cacheState {
+	if m.fetchedAt.IsZero() {
+		return cacheEmpty
+	}
+	if m.invalidated {
+		return cacheExpired
+	}
+
+	// fetchedAt carries a monotonic clock reading so this is robust to wall clock jumps, a negative age can only come
+	// from a wall clock time and is treated as expired. A zero max age is never fresh, even if the clock hasn't advanced
+	// since the prices were fetched.
+	age, maxAge := m.clock().Sub(m.fetchedAt), m.maxAge()
+	switch {
+	case age < 0:
+		return cacheExpired
+	case maxAge > 0 && age <= maxAge:
+		return cacheFresh
+	case age <= m.maxStaleness && m.maxStaleness > maxAge:
+		return cacheStale
+	}
+	return cacheExpired
+}
+
+// checkServeAge returns an error wrapping ErrValueTooOld if prices of age can't be served under maxServeAge, which has
+// no limit if it is zero
+func checkServeAge(age, maxServeAge time.Duration) error {
+	if maxServeAge > 0 && age > maxServeAge {
+		return fmt.Errorf("%w: fetched %v ago", ErrValueTooOld, age)
+	}
+	return nil
+}
+
+// maxAge returns the age up to which the cached prices are fresh, it must be called with the lock held
+func (m *gasPriceManager) maxAge() time.Duration {
+	if blockTime := m.latestPrices.BlockTime; m.capToBlockTime && blockTime > 0 && blockTime < m.maxResultAge {
+		return blockTime
+	}
+	return m.maxResultAge
+}
+
+// refreshInBackground fetches new prices with ctx, a detached context of the call that found the prices stale. The
+// prices are only stored if the cache wasn't invalidated since generation.
+func (m *gasPriceManager) refreshInBackground(ctx context.Context, generation int) {
+	prices, err := m.fetcher()(contextWithBackgroundRefresh(ctx))
+
+	m.Lock()
+	defer m.Unlock()
+	m.refreshing = false
+	stored := err == nil && generation == m.generation
+	if stored {
+		m.store(prices)
+	}
+	for _, waiter := range m.waiters {
+		if stored {
+			waiter <- m.latestPrices.copy()
 		}
-		m.latestResponse = prices
-		m.fetchedAt = time.Now()
+		close(waiter)
+	}
+	m.waiters = nil
+}
+
+// invalidate expires the cached prices, so the next read fetches new prices
+func (m *gasPriceManager) invalidate() {
+	m.Lock()
+	defer m.Unlock()
+	m.invalidated = true
+	m.generation++
+}
+
+func (m *gasPriceManager) clock() time.Time {
+	if m.now == nil {
+		return time.Now()
 	}
+	return m.now()
+}
 
-	return parseSuggestedGasPrice(priority, m.latestResponse)
+func (m *gasPriceManager) report(event Event) {
+	if m.emit != nil {
+		m.emit(event)
+	}
 }
 
+func (m *gasPriceManager) comparator() func(previous, next GasPrices) bool {
+	if m.equal == nil {
+		return samePrices
+	}
+	return m.equal
+}
+
+func (m *gasPriceManager) fetcher() func(context.Context) (GasPrices, error) {
+	if m.fetch == nil {
+		return newPackageClient().fetch
+	}
+	return m.fetch
+}
+
+// store replaces the cached prices, it must be called with the lock held. The previous prices are kept if the new
+// prices are unchanged, so an unchanged refresh only makes the prices fresh again.
+func (m *gasPriceManager) store(prices GasPrices) {
+	if m.fetchedAt.IsZero() || !m.comparator()(m.latestPrices, prices) {
+		m.previousPrices = m.latestPrices
+	}
+	m.latestPrices = prices
+	m.fetchedAt = m.clock()
+	m.invalidated = false
+}
+
+// cached returns copies of the cached prices without refreshing them, ok is false if nothing has been cached yet
+func (m *gasPriceManager) cached() (current, previous GasPrices, ok bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.fetchedAt.IsZero() {
+		return GasPrices{}, GasPrices{}, false
+	}
+	return m.latestPrices.copy(), m.previousPrices.copy(), true
+}
+
+// InputScale is the unit of the raw prices returned by the ETH Gas Station API.
+type InputScale int
+
+const (
+	// InputScaleTenthsOfGwei indicates raw prices are expressed in tenths of gwei. This is the documented unit of the
+	// ETH Gas Station API and the default.
+	InputScaleTenthsOfGwei InputScale = iota
+
+	// InputScaleGwei indicates raw prices are already expressed in gwei.
+	InputScaleGwei
+
+	// InputScaleWei indicates raw prices are expressed in wei, for endpoints reporting prices more precise than tenths
+	// of gwei.
+	InputScaleWei
+)
+
 // conversion factor to go from (gwei * 10) to wei
 // equal to: (raw / 10) => gwei => gwei * 1e9 => wei
 // simplifies to: raw * 1e8 => wei
-var conversionFactor = big.NewFloat(100000000)
+var conversionFactor = big.NewRat(100000000, 1)
+
+// conversion factor to go from gwei to wei
+var gweiConversionFactor = big.NewRat(1000000000, 1)
+
+// conversion factor of prices already in wei
+var weiConversionFactor = big.NewRat(1, 1)
+
+func (s InputScale) valid() bool {
+	return s == InputScaleTenthsOfGwei || s == InputScaleGwei || s == InputScaleWei
+}
+
+func (s InputScale) conversionFactor() *big.Rat {
+	switch s {
+	case InputScaleGwei:
+		return gweiConversionFactor
+	case InputScaleWei:
+		return weiConversionFactor
+	}
+	return conversionFactor
+}
+
+// exponent returns the conversion factor of the scale as a power of ten
+func (s InputScale) exponent() int {
+	switch s {
+	case InputScaleGwei:
+		return 9
+	case InputScaleWei:
+		return 0
+	}
+	return 8
+}
 
 type ethGasStationResponse struct {
-	Fast    float64 `json:"fast"`
-	Fastest float64 `json:"fastest"`
-	SafeLow float64 `json:"safeLow"`
-	Average float64 `json:"average"`
+	// prices are decoded as the decimal text of the response, so they are converted exactly
+	Fast    priceNumber `json:"fast"`
+	Fastest priceNumber `json:"fastest"`
+	SafeLow priceNumber `json:"safeLow"`
+	Average priceNumber `json:"average"`
+
+	// estimated wait times in tenths of a minute, as reported by the raw feed
+	FastWait    float64 `json:"fastWait"`
+	FastestWait float64 `json:"fastestWait"`
+	SafeLowWait float64 `json:"safeLowWait"`
+	AvgWait     float64 `json:"avgWait"`
+
+	// GasPriceRange maps a raw gas price to its expected wait time in minutes
+	GasPriceRange map[string]float64 `json:"gasPriceRange"`
+
+	// BlockTime is the average block time in seconds
+	BlockTime float64 `json:"block_time"`
+}
+
+// priceNumber is the decimal text of a price in a response, like json.Number, except that a null price is decoded as
+// nullPrice so it can be told apart from a missing price, which is empty
+type priceNumber string
+
+// nullPrice is the priceNumber of a price that is null in a response, such as a level a gateway has no price for
+const nullPrice = priceNumber("null")
+
+func (n *priceNumber) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*n = nullPrice
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*n = priceNumber(number)
+	return nil
+}
+
+func (n priceNumber) MarshalJSON() ([]byte, error) {
+	if n == nullPrice {
+		return []byte("null"), nil
+	}
+	return json.Marshal(json.Number(n))
+}
+
+func (n priceNumber) String() string {
+	return string(n)
+}
+
+// ETHGasStationProvider is a Provider that loads prices from the ETH Gas Station API. It is the default provider of a
+// Client, and the zero value is ready to use.
+//
+// If a key was set with SetKey, the keyed endpoint is used instead of the free endpoint. A price that is null in a
+// response, as some gateways report a level they have no price for, leaves that level without a price, so only
+// requesting it fails. A response whose prices are all null is an error.
+type ETHGasStationProvider struct {
+	// URL replaces the ETH Gas Station endpoint, e.g. to use a proxy or a mirror that serves the same response format.
+	URL string
+
+	// APIKey is sent as the api-key query parameter, on the keyed endpoint unless URL is set. It takes precedence over a
+	// key set with SetKey.
+	APIKey string
+
+	// InputScale is the unit of the raw prices in the response, it defaults to InputScaleTenthsOfGwei.
+	InputScale InputScale
+
+	// HTTPClient is used to make requests, it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// CharsetReader, if set, converts a response body in the charset declared by its Content-Type header to UTF-8 before
+	// it is decoded. It is not called for UTF-8 and US-ASCII bodies, or if no charset is declared. It has the signature
+	// of charset.NewReaderLabel from golang.org/x/net/html/charset, which supports most charsets.
+	//
+	// If it is nil, the body is decoded as UTF-8 whatever charset is declared.
+	CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+
+	// EnvelopePath is the dotted path of the gas object in a response wrapped in an envelope, such as "data" for
+	// {"status": "ok", "data": {...}}. The gas object is the whole response if it is empty.
+	EnvelopePath string
+
+	// FieldMapping replaces the name of the field of the gas object that the price of a priority level is decoded from,
+	// such as "standard" for GasPriorityAverage, for gateways that rename fields. Priority levels that aren't mapped are
+	// decoded from the field of the ETH Gas Station API, and a mapped field missing from a response is an error.
+	FieldMapping map[GasPriority]string
+
+	// OnSchemaDrift, if set, is called with the drift of each response whose gas object has unknown or missing fields,
+	// as detected by DetectSchemaDrift, and StrictSchema fails the fetch of such a response with an error wrapping
+	// ErrSchemaDrift. Fields in AllowedFields, such as harmless fields added by a gateway, are not unknown.
+	OnSchemaDrift func(SchemaDrift)
+	StrictSchema  bool
+	AllowedFields []string
+}
+
+// Fetch loads the latest prices from the ETH Gas Station API.
+func (p *ETHGasStationProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	response, err := fetchGasPrices(ctx, p.client(), p.url(), p.CharsetReader, p.EnvelopePath, p.FieldMapping,
+		p.checkSchema())
+	if err != nil {
+		return GasPrices{}, err
+	}
+	return newGasPrices(response, p.InputScale)
 }
 
-var keybased bool
+// CloseIdleConnections closes idle connections of the underlying HTTP client.
+func (p *ETHGasStationProvider) CloseIdleConnections() {
+	p.client().CloseIdleConnections()
+}
+
+func (p *ETHGasStationProvider) url() string {
+	if p.URL == "" {
+		if p.APIKey != "" {
+			return keylink + url.QueryEscape(p.APIKey)
+		}
+		return defaultURL()
+	}
+	if p.APIKey == "" {
+		return p.URL
+	}
+
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		// the invalid URL fails the request with a clear error
+		return p.URL
+	}
+	query := u.Query()
+	query.Set("api-key", p.APIKey)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func (p *ETHGasStationProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
 
-var key string
+var (
+	// keyMu guards key and keybased, which are read by every request to the default endpoint while SetKey may be
+	// called at any time
+	keyMu    sync.RWMutex
+	keybased bool
+	key      string
+)
 
 var keylink = "https://data-api.defipulse.com/api/v1/egs/api/ethgasAPI.json?api-key="
 
+// SetKey sets the API key used by every client on the keyed endpoint, unless a client has its own key. A malformed key
+// is reported when a client is created with NewClient, see ValidateKey.
 func SetKey(k string) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
 	key = k
 	keybased = true
 }
 
-func loadGasPrices() (ethGasStationResponse, error) {
+// globalKey returns the key set with SetKey, and whether one was set
+func globalKey() (string, bool) {
+	keyMu.RLock()
+	defer keyMu.RUnlock()
+	return key, keybased
+}
+
+// FetchError is returned when a response could not be loaded from the API, either because the request failed or because
+// the API responded with an unexpected HTTP status code.
+type FetchError struct {
+	// StatusCode is the HTTP status code of the response, or zero if no response was received or the response body
+	// was cut short.
+	StatusCode int
+
+	// Err is the underlying error if the request failed.
+	Err error
+
+	// Attempt is the number of the attempt that failed, starting at 1, and Duration how long it took to fail. They are
+	// only set by a client configured with WithErrorTiming.
+	Attempt  int
+	Duration time.Duration
+}
+
+func (e *FetchError) Error() string {
+	var s string
+	if e.Err != nil {
+		s = "eth: unable to load gas prices: " + e.Err.Error()
+	} else {
+		s = fmt.Sprintf("eth: unexpected response status: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.Attempt > 0 {
+		s += fmt.Sprintf(" (attempt %d failed after %s)", e.Attempt, e.Duration)
+	}
+	return s
+}
+
+// Unwrap returns the underlying error.
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+func loadGasPrices(ctx context.Context) (ethGasStationResponse, error) {
+	return fetchGasPrices(ctx, packageHTTPClient(), defaultURL(), nil, "", nil, nil)
+}
+
+// defaultURL returns the keyed endpoint if a key was set with SetKey, and the free endpoint otherwise
+func defaultURL() string {
+	if key, ok := globalKey(); ok {
+		return keylink + key
+	}
+	return ETHGasStationURL
+}
+
+func fetchGasPrices(
+	ctx context.Context,
+	client *http.Client,
+	endpoint string,
+	charsetReader func(string, io.Reader) (io.Reader, error),
+	envelopePath string,
+	fieldMapping map[GasPriority]string,
+	checkSchema func(gasObject json.RawMessage) error,
+) (ethGasStationResponse, error) {
 	var prices ethGasStationResponse
-	if keybased {
 
-		res, err := http.Get(keylink + key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return prices, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return prices, &FetchError{Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return prices, &FetchError{StatusCode: res.StatusCode}
+	}
 
-		if err != nil {
-			return prices, err
+	body := io.Reader(res.Body)
+	if charsetReader != nil {
+		if charset := responseCharset(res.Header.Get("Content-Type")); charset != "" {
+			if body, err = charsetReader(charset, body); err != nil {
+				return prices, fmt.Errorf("eth: unable to decode response charset %q: %w", charset, err)
+			}
 		}
-		if err := json.NewDecoder(res.Body).Decode(&prices); err != nil {
-			return prices, err
+	}
+
+	if envelopePath == "" && len(fieldMapping) == 0 && checkSchema == nil {
+		if err := decodeResponse(body, &prices); err != nil {
+			return ethGasStationResponse{}, err
 		}
 		return prices, nil
+	}
 
-	} else {
-		res, err := http.Get(ETHGasStationURL)
+	var payload json.RawMessage
+	if err := decodeResponse(body, &payload); err != nil {
+		return ethGasStationResponse{}, err
+	}
+	if envelopePath != "" {
+		if payload, err = unwrapEnvelope(payload, envelopePath); err != nil {
+			return ethGasStationResponse{}, err
+		}
+	}
+	if checkSchema != nil {
+		if err := checkSchema(payload); err != nil {
+			return ethGasStationResponse{}, err
+		}
+	}
+	if err := json.Unmarshal(payload, &prices); err != nil {
+		return ethGasStationResponse{}, err
+	}
+	if len(fieldMapping) > 0 {
+		if err := remapPriceFields(payload, fieldMapping, &prices); err != nil {
+			return ethGasStationResponse{}, err
+		}
+	}
+	return prices, nil
+}
+
+// remapPriceFields replaces the prices of the mapped priority levels with the fields of the gas object they are mapped
+// to
+func remapPriceFields(
+	payload json.RawMessage,
+	fieldMapping map[GasPriority]string,
+	prices *ethGasStationResponse,
+) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return err
+	}
+	for priority, name := range fieldMapping {
+		value, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("eth: response has no field %q for priority %q", name, priority)
+		}
+		var price priceNumber
+		if err := json.Unmarshal(value, &price); err != nil {
+			return fmt.Errorf("eth: field %q for priority %q is not a number", name, priority)
+		}
+
+		switch priority {
+		case GasPriorityFast:
+			prices.Fast = price
+		case GasPriorityFastest:
+			prices.Fastest = price
+		case GasPrioritySafeLow:
+			prices.SafeLow = price
+		case GasPriorityAverage:
+			prices.Average = price
+		default:
+			return fmt.Errorf("eth: field mapping for unsupported priority %q", priority)
+		}
+	}
+	return nil
+}
+
+// unwrapEnvelope returns the value at the dotted path in the JSON object envelope
+func unwrapEnvelope(envelope json.RawMessage, path string) (json.RawMessage, error) {
+	for _, key := range strings.Split(path, ".") {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(envelope, &fields); err != nil || fields == nil {
+			return nil, fmt.Errorf("eth: response envelope has no object containing %q", key)
+		}
+		value, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("eth: response envelope has no field %q", key)
+		}
+		envelope = value
+	}
+	return envelope, nil
+}
+
+// decodeResponse decodes a JSON response body into v. A body that can't be read to the end, such as one cut short by
+// a dropped connection, is a *FetchError so it can be retried, while a complete body that isn't valid is not.
+func decodeResponse(body io.Reader, v interface{}) error {
+	reader := &readErrorRecorder{r: body}
+	err := json.NewDecoder(reader).Decode(v)
+	if err == nil {
+		return nil
+	}
+	if reader.err != nil || err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &FetchError{Err: err}
+	}
+	return err
+}
+
+// readErrorRecorder records the first error other than io.EOF returned by reading r
+type readErrorRecorder struct {
+	r   io.Reader
+	err error
+}
+
+func (r *readErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// responseCharset returns the charset declared by a Content-Type header, or an empty string if none is declared or it
+// is compatible with UTF-8
+func responseCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	charset := strings.ToLower(strings.TrimSpace(params["charset"]))
+	switch charset {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return ""
+	}
+	return charset
+}
+
+// convert every priority in the response to wei
+func newGasPrices(prices ethGasStationResponse, scale InputScale) (GasPrices, error) {
+	var (
+		result GasPrices
+		err    error
+	)
+	levels := []struct {
+		priority GasPriority
+		raw      priceNumber
+		price    **big.Int
+	}{
+		{GasPriorityFast, prices.Fast, &result.Fast},
+		{GasPriorityFastest, prices.Fastest, &result.Fastest},
+		{GasPrioritySafeLow, prices.SafeLow, &result.SafeLow},
+		{GasPriorityAverage, prices.Average, &result.Average},
+	}
+	result.Raw = make(map[GasPriority]string, len(levels))
+	var valid int
+	for _, level := range levels {
+		// a null price leaves the level without a price, so only requesting that level fails
+		if level.raw == nullPrice {
+			continue
+		}
+		result.Raw[level.priority] = level.raw.String()
+		price, err := parseScaledDecimalToWei(level.raw.String(), scale)
 		if err != nil {
-			return prices, err
+			// an invalid price only fails requests for its level, which return its error
+			if result.errs == nil {
+				result.errs = make(map[GasPriority]error, len(levels))
+			}
+			result.errs[level.priority] = err
+			continue
 		}
-		if err := json.NewDecoder(res.Body).Decode(&prices); err != nil {
-			return prices, err
+		*level.price = price
+		valid++
+	}
+	if valid == 0 {
+		for _, level := range levels {
+			if err, ok := result.errs[level.priority]; ok {
+				return GasPrices{}, err
+			}
 		}
-		return prices, nil
+		return GasPrices{}, errors.New("eth: response has no gas price for any priority")
 	}
+	if result.Predictions, err = parsePredictions(prices.GasPriceRange, scale); err != nil {
+		return GasPrices{}, err
+	}
+	result.Waits = parseWaits(map[GasPriority]float64{
+		GasPriorityFast:    prices.FastWait,
+		GasPriorityFastest: prices.FastestWait,
+		GasPrioritySafeLow: prices.SafeLowWait,
+		GasPriorityAverage: prices.AvgWait,
+	})
+	result.BlockTime, _ = secondsToDuration(prices.BlockTime)
+	return result, nil
+}
 
+// convert wait times in tenths of a minute to durations, a missing wait time is reported as zero and left out
+func parseWaits(waitTenths map[GasPriority]float64) map[GasPriority]time.Duration {
+	var waits map[GasPriority]time.Duration
+	for priority, tenths := range waitTenths {
+		wait, ok := minutesToDuration(tenths / 10)
+		if !ok || wait == 0 {
+			continue
+		}
+		if waits == nil {
+			waits = make(map[GasPriority]time.Duration, len(waitTenths))
+		}
+		waits[priority] = wait
+	}
+	return waits
 }
 
-func parseSuggestedGasPrice(priority GasPriority, prices ethGasStationResponse) (*big.Int, error) {
-	switch priority {
-	case GasPriorityFast:
-		return parseGasPriceToWei(prices.Fast)
-	case GasPriorityFastest:
-		return parseGasPriceToWei(prices.Fastest)
-	case GasPrioritySafeLow:
-		return parseGasPriceToWei(prices.SafeLow)
-	case GasPriorityAverage:
-		return parseGasPriceToWei(prices.Average)
-	default:
-		return nil, errors.New("eth: unknown/unsupported gas priority")
+// convert a wait time in minutes to a duration, ok is false if it is negative, NaN, or too long to represent, since
+// converting an out of range float to an integer produces an arbitrary value
+func minutesToDuration(minutes float64) (wait time.Duration, ok bool) {
+	nanoseconds := minutes * float64(time.Minute)
+	if !(nanoseconds >= 0 && nanoseconds < math.MaxInt64) {
+		return 0, false
 	}
+	return time.Duration(nanoseconds), true
+}
+
+// convert a duration in seconds to a duration, ok is false if it is out of range as for minutesToDuration
+func secondsToDuration(seconds float64) (d time.Duration, ok bool) {
+	return minutesToDuration(seconds / 60)
+}
+
+// convert the prediction table to wei and wait times, sorted by ascending price
+func parsePredictions(gasPriceRange map[string]float64, scale InputScale) ([]PricePrediction, error) {
+	if len(gasPriceRange) == 0 {
+		return nil, nil
+	}
+
+	predictions := make([]PricePrediction, 0, len(gasPriceRange))
+	for rawPrice, waitMinutes := range gasPriceRange {
+		if rawPrice == "" {
+			return nil, errors.New("eth: unable to parse gas price in prediction table")
+		}
+		price, err := parseScaledDecimalToWei(rawPrice, scale)
+		if err != nil {
+			return nil, err
+		}
+		wait, ok := minutesToDuration(waitMinutes)
+		if !ok {
+			return nil, errors.New("eth: invalid wait time in prediction table")
+		}
+		predictions = append(predictions, PricePrediction{Price: price, Wait: wait})
+	}
+
+	sort.Slice(predictions, func(i, j int) bool {
+		return predictions[i].Price.Cmp(predictions[j].Price) < 0
+	})
+	return predictions, nil
 }
 
 // convert eth gas station units to wei
 // (raw result / 10) * 1e9 = base units (wei)
 func parseGasPriceToWei(raw float64) (*big.Int, error) {
-	gwei := new(big.Float).Mul(big.NewFloat(raw), conversionFactor)
-	if !gwei.IsInt() {
+	return parseScaledGasPriceToWei(raw, InputScaleTenthsOfGwei)
+}
+
+// convert a raw price in the given scale to wei
+// the conversion is exact, so it only fails if the price has a fractional number of wei
+func parseScaledGasPriceToWei(raw float64, scale InputScale) (*big.Int, error) {
+	return parseScaledDecimalToWei(strconv.FormatFloat(raw, 'f', -1, 64), scale)
+}
+
+// convert a raw price in the given scale, as the decimal text of the response, to wei
+// a missing price is zero, as it was when prices were decoded as floats
+func parseScaledDecimalToWei(raw string, scale InputScale) (*big.Int, error) {
+	if raw == "" {
+		raw = "0"
+	}
+	if wei, ok := plainDecimalToWei(raw, scale); ok {
+		return new(big.Int).SetUint64(wei), nil
+	}
+	exact, err := parseDecimalGasPrice(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	wei := exact.Mul(exact, scale.conversionFactor())
+	if !wei.IsInt() {
 		return nil, errors.New("eth: unable to represent gas price as integer")
 	}
+	return new(big.Int).Set(wei.Num()), nil
+}
+
+// plainDecimalToWei converts a price in the given scale written as plain digits with an optional fraction, such as
+// "120.5", to wei with integer arithmetic, which is the common case of a response. ok is false for any other number,
+// including one with more than 19 digits or a fractional or overflowing number of wei, which is left to the exact
+// conversion of parseDecimalGasPrice.
+func plainDecimalToWei(raw string, scale InputScale) (wei uint64, ok bool) {
+	exponent := scale.exponent()
+
+	digits, fraction := 0, -1
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; {
+		case c >= '0' && c <= '9':
+			if digits == 19 {
+				return 0, false
+			}
+			wei = wei*10 + uint64(c-'0')
+			digits++
+			if fraction >= 0 {
+				fraction++
+			}
+		case c == '.' && fraction < 0 && digits > 0 && i < len(raw)-1:
+			fraction = 0
+		default:
+			return 0, false
+		}
+	}
+	if digits == 0 {
+		return 0, false
+	}
+	if fraction < 0 {
+		fraction = 0
+	}
 
-	// we can skip the accuracy check because we know from above that gwei is an integer
-	wei, _ := gwei.Int(new(big.Int))
-	return wei, nil
+	for ; fraction > exponent; fraction-- {
+		if wei%10 != 0 {
+			return 0, false
+		}
+		wei /= 10
+	}
+	for ; fraction < exponent; fraction++ {
+		hi, lo := bits.Mul64(wei, 10)
+		if hi != 0 {
+			return 0, false
+		}
+		wei = lo
+	}
+	return wei, true
+}
+
+// convert a raw price in the given scale to an exact number of gwei
+func parseGasPriceToGwei(raw float64, scale InputScale) (*big.Rat, error) {
+	gwei, err := parseExactGasPrice(raw)
+	if err != nil {
+		return nil, err
+	}
+	return gwei.Quo(gwei.Mul(gwei, scale.conversionFactor()), gweiConversionFactor), nil
+}
+
+// the shortest decimal representation of the float is used, which is the value as it appeared in the response
+func parseExactGasPrice(raw float64) (*big.Rat, error) {
+	return parseDecimalGasPrice(strconv.FormatFloat(raw, 'f', -1, 64))
+}
+
+// maxDecimalExponent bounds the exponent of a decimal price, so a response can't make its conversion arbitrarily
+// expensive, it is well beyond any real price
+const maxDecimalExponent = 400
+
+// errInvalidDecimal is returned for a raw price that isn't a decimal number
+var errInvalidDecimal = errors.New("eth: unable to represent gas price as rational")
+
+// parseDecimalGasPrice converts a decimal number, such as "120.5" or "1.205e2", to an exact rational
+// a negative gas price is always invalid, so it is rejected here rather than passed on to a transaction
+func parseDecimalGasPrice(raw string) (*big.Rat, error) {
+	// big.Rat also accepts fractions such as "1/3" and hexadecimal numbers, which are not decimal numbers
+	if strings.IndexFunc(raw, func(r rune) bool { return !strings.ContainsRune("0123456789.eE+-", r) }) >= 0 {
+		return nil, errInvalidDecimal
+	}
+	if i := strings.IndexAny(raw, "eE"); i >= 0 {
+		exponent, err := strconv.Atoi(raw[i+1:])
+		if err != nil || exponent > maxDecimalExponent || exponent < -maxDecimalExponent {
+			return nil, errInvalidDecimal
+		}
+	}
+
+	exact, ok := new(big.Rat).SetString(raw)
+	if !ok {
+		return nil, errInvalidDecimal
+	}
+	if exact.Sign() < 0 {
+		return nil, errNegativePrice
+	}
+	return exact, nil
 }
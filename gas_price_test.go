@@ -1,7 +1,16 @@
 package gas
 
 import (
+	"context"
+	"encoding/json"
+	"math"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,40 +41,449 @@ func TestParseGasPriceToWei(t *testing.T) {
 	assert.Equal(t, 0, oneGweiInBaseUnits.Cmp(parsed))
 }
 
+func TestParseGasPriceToGwei(t *testing.T) {
+	// 1. tenths of gwei are converted exactly
+	parsed, err := parseGasPriceToGwei(1205.0, InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	assert.Equal(t, 0, big.NewRat(1205, 10).Cmp(parsed))
+
+	// 2. values that are not exactly representable as binary floats are still exact
+	parsed, err = parseGasPriceToGwei(0.3, InputScaleGwei)
+	require.NoError(t, err)
+	assert.Equal(t, "3/10", parsed.String())
+
+	// 3. wei are converted to fractions of gwei
+	parsed, err = parseGasPriceToGwei(12012345678, InputScaleWei)
+	require.NoError(t, err)
+	assert.Equal(t, "6006172839/500000000", parsed.String())
+}
+
+func TestParseScaledGasPriceToWei(t *testing.T) {
+	// 1. decimal prices that can't be represented exactly as binary floats are converted exactly
+	parsed, err := parseScaledGasPriceToWei(1.1, InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	assert.Equal(t, "110000000", parsed.String())
+
+	parsed, err = parseScaledGasPriceToWei(45.3, InputScaleGwei)
+	require.NoError(t, err)
+	assert.Equal(t, "45300000000", parsed.String())
+
+	// 2. fractional wei can't be represented
+	_, err = parseScaledGasPriceToWei(0.0000000001, InputScaleGwei)
+	assert.Error(t, err)
+
+	// 3. negative prices are rejected
+	_, err = parseScaledGasPriceToWei(-10, InputScaleTenthsOfGwei)
+	assert.Error(t, err)
+
+	// 4. NaN and infinite prices are rejected
+	for _, raw := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err = parseScaledGasPriceToWei(raw, InputScaleTenthsOfGwei)
+		assert.Error(t, err)
+	}
+}
+
+func TestParseScaledDecimalToWei(t *testing.T) {
+	// 1. decimal text is converted exactly, without going through a float
+	for raw, expected := range map[string]string{
+		"120.5":                 "12050000000",
+		"1205e-1":               "12050000000",
+		"0.1":                   "10000000",
+		"12345678901234567.891": "1234567890123456789100000",
+		"":                      "0",
+	} {
+		wei, err := parseScaledDecimalToWei(raw, InputScaleTenthsOfGwei)
+		require.NoError(t, err, raw)
+		assert.Equal(t, expected, wei.String(), raw)
+	}
+
+	// 2. values that aren't decimal numbers, or have an unreasonable exponent, are rejected
+	for _, raw := range []string{"foo", "1/3", "0x10", "0x1p3", "NaN", "Inf", "1e401", "1e-999999999", "1_000"} {
+		_, err := parseScaledDecimalToWei(raw, InputScaleTenthsOfGwei)
+		assert.Error(t, err, raw)
+	}
+
+	// 3. plain decimals converted with integer arithmetic match the exact conversion, which handles what they can't
+	for _, raw := range []string{"0", "007.50", "120.5", "1.000000001", "0.000000001", "184467440737", "5.", ".5",
+		"9999999999999999999", "99999999999999999999", "1.00000000000000000000"} {
+		for _, scale := range []InputScale{InputScaleTenthsOfGwei, InputScaleGwei, InputScaleWei} {
+			var expected *big.Int
+			exact, err := parseDecimalGasPrice(raw)
+			if err == nil && exact.Mul(exact, scale.conversionFactor()).IsInt() {
+				expected = exact.Num()
+			}
+			wei, err := parseScaledDecimalToWei(raw, scale)
+			if expected == nil {
+				assert.Error(t, err, raw)
+				continue
+			}
+			require.NoError(t, err, raw)
+			assert.Equal(t, expected.String(), wei.String(), raw)
+		}
+	}
+
+	// 4. prices finer than tenths of gwei keep every wei, in any scale
+	for _, test := range []struct {
+		raw      string
+		scale    InputScale
+		expected string
+	}{
+		{"120.12345678", InputScaleTenthsOfGwei, "12012345678"},
+		{"12.012345678", InputScaleGwei, "12012345678"},
+		{"1.2012345678e1", InputScaleGwei, "12012345678"},
+		{"12012345678", InputScaleWei, "12012345678"},
+		{"120123456789012345678901", InputScaleWei, "120123456789012345678901"},
+		{"1.5e3", InputScaleWei, "1500"},
+	} {
+		wei, err := parseScaledDecimalToWei(test.raw, test.scale)
+		require.NoError(t, err, test.raw)
+		assert.Equal(t, test.expected, wei.String(), test.raw)
+	}
+	_, err := parseScaledDecimalToWei("12012345678.9", InputScaleWei)
+	assert.Error(t, err, "fractional wei can't be represented")
+}
+
+func BenchmarkParseScaledDecimalToWei(b *testing.B) {
+	for _, raw := range []string{"200", "120.5", "1205e-1", "12345678901234567.891"} {
+		b.Run(raw, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := parseScaledDecimalToWei(raw, InputScaleTenthsOfGwei); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseGasPriceToWei(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseGasPriceToWei(120.5); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewGasPrices(b *testing.B) {
+	var response ethGasStationResponse
+	require.NoError(b, json.Unmarshal([]byte(testResponse), &response))
+
+	b.Run("Convert", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := newGasPrices(response, InputScaleTenthsOfGwei); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("DecodeAndConvert", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var response ethGasStationResponse
+			if err := decodeResponse(strings.NewReader(testResponse), &response); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := newGasPrices(response, InputScaleTenthsOfGwei); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestNewGasPricesNegative(t *testing.T) {
+	valid := ethGasStationResponse{Fast: "200", Fastest: "250", SafeLow: "100", Average: "150"}
+
+	// 1. a negative price only fails its level, which returns its error, while the other levels are served
+	prices, err := newGasPrices(ethGasStationResponse{Fast: "-200", Fastest: "250", SafeLow: "100", Average: "150"},
+		InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	_, err = prices.Price(GasPriorityFast)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fast")
+	_, err = prices.Price(GasPriorityTurbo)
+	assert.Error(t, err)
+	assert.Equal(t, "-200", prices.Raw[GasPriorityFast])
+	price, err := prices.Price(GasPriorityFastest)
+	require.NoError(t, err)
+	assert.Equal(t, "25000000000", price.String())
+	assert.NoError(t, validatePrices(prices, true))
+
+	// 2. the errors of the levels are kept by copies
+	_, err = prices.copy().Price(GasPriorityFast)
+	assert.Contains(t, err.Error(), "invalid gas price")
+
+	// 3. a response without any valid price, or with negative predictions, fails as a whole
+	for _, response := range []ethGasStationResponse{
+		{Fast: "-200", Fastest: "-250", SafeLow: "-100", Average: "-150"},
+		{Fast: "200", Fastest: "250", SafeLow: "100", Average: "150", GasPriceRange: map[string]float64{"-10": 5}},
+	} {
+		_, err := newGasPrices(response, InputScaleTenthsOfGwei)
+		assert.Error(t, err)
+	}
+
+	_, err = newGasPrices(valid, InputScaleTenthsOfGwei)
+	assert.NoError(t, err)
+}
+
+func TestSuggester(t *testing.T) {
+	c, err := NewClient(WithProvider(countingProvider(0, nil, new(int32))))
+	require.NoError(t, err)
+	suggest, err := c.NewGasPriceSuggester(time.Minute)
+	require.NoError(t, err)
+	refresher, err := c.NewRefresher(context.Background(), time.Minute, WithWarmOnStart(true))
+	require.NoError(t, err)
+	defer refresher.Stop()
+
+	// 1. the client, the refresher and the func type are all suggesters
+	for _, suggester := range []Suggester{c, refresher, suggest} {
+		price, err := suggester.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+		assert.Equal(t, "20000000000", price.String())
+	}
+}
+
+func TestNullPrices(t *testing.T) {
+	serveBody := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}
+	}
+	partial := `{"fast": null, "fastest": 250.0, "safeLow": "100", "average": null, "fastWait": null, "avgWait": 30, ` +
+		`"gasPriceRange": null, "block_time": null}`
+
+	// 1. null fields leave their level without a price, and the other levels are served
+	c, closeServer := newTestClient(t, serveBody(partial))
+	defer closeServer()
+	prices, err := c.Snapshot()
+	require.NoError(t, err)
+	assert.Nil(t, prices.Fast)
+	assert.Nil(t, prices.Average)
+	assert.Equal(t, "25000000000", prices.Fastest.String())
+	assert.Equal(t, "10000000000", prices.SafeLow.String())
+	assert.Equal(t, map[GasPriority]string{GasPriorityFastest: "250.0", GasPrioritySafeLow: "100"}, prices.Raw)
+	assert.Equal(t, map[GasPriority]time.Duration{GasPriorityAverage: 3 * time.Minute}, prices.Waits)
+
+	// 2. only requesting a level that is null fails
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+	price, err := c.SuggestGasPrice(GasPriorityFastest)
+	require.NoError(t, err)
+	assert.Equal(t, "25000000000", price.String())
+
+	// 3. a null mapped field is a level without a price too
+	remapped := `{"rapid": null, "fastest": 250.0, "safeLow": 100.0, "average": 150.0}`
+	mapping := map[GasPriority]string{GasPriorityFast: "rapid"}
+	mapped, closeMapped := newTestClient(t, serveBody(remapped), WithFieldMapping(mapping))
+	defer closeMapped()
+	prices, err = mapped.Snapshot()
+	require.NoError(t, err)
+	assert.Nil(t, prices.Fast)
+	assert.Equal(t, "15000000000", prices.Average.String())
+
+	// 4. a response with no price at all is an error
+	empty, closeEmpty := newTestClient(t, serveBody(`{"fast": null, "fastest": null, "safeLow": null, "average": null}`))
+	defer closeEmpty()
+	_, err = empty.Snapshot()
+	assert.Error(t, err)
+}
+
+func TestParsePredictions(t *testing.T) {
+	gasPriceRange := map[string]float64{
+		"200": 0.5,
+		"40":  30.0,
+		"120": 3.0,
+	}
+
+	// 1. predictions are converted to wei and sorted by price
+	predictions, err := parsePredictions(gasPriceRange, InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	require.Len(t, predictions, 3)
+	assert.Equal(t, "4000000000", predictions[0].Price.String())
+	assert.Equal(t, 30*time.Minute, predictions[0].Wait)
+	assert.Equal(t, "12000000000", predictions[1].Price.String())
+	assert.Equal(t, 3*time.Minute, predictions[1].Wait)
+	assert.Equal(t, "20000000000", predictions[2].Price.String())
+	assert.Equal(t, 30*time.Second, predictions[2].Wait)
+
+	// 2. a missing table has no predictions
+	predictions, err = parsePredictions(nil, InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	assert.Empty(t, predictions)
+
+	// 3. invalid prices are rejected
+	_, err = parsePredictions(map[string]float64{"foo": 1.0}, InputScaleTenthsOfGwei)
+	assert.Error(t, err)
+	_, err = parsePredictions(map[string]float64{"NaN": 1.0}, InputScaleTenthsOfGwei)
+	assert.Error(t, err)
+	_, err = parsePredictions(map[string]float64{"Inf": 1.0}, InputScaleTenthsOfGwei)
+	assert.Error(t, err)
+
+	// 4. wait times that can't be represented are rejected rather than overflowing
+	_, err = parsePredictions(map[string]float64{"200": 1e308}, InputScaleTenthsOfGwei)
+	assert.Error(t, err)
+	_, err = parsePredictions(map[string]float64{"200": -1}, InputScaleTenthsOfGwei)
+	assert.Error(t, err)
+}
+
 func TestLoadGasPrices(t *testing.T) {
-	rawPrices, err := loadGasPrices()
+	rawPrices, err := loadGasPrices(context.Background())
 	require.NoError(t, err)
 
-	require.GreaterOrEqual(t, rawPrices.Fastest, rawPrices.Fast)
-	require.GreaterOrEqual(t, rawPrices.Fast, rawPrices.Average)
-	require.GreaterOrEqual(t, rawPrices.Average, rawPrices.SafeLow)
-	require.GreaterOrEqual(t, rawPrices.SafeLow, 0.0)
+	prices, err := newGasPrices(rawPrices, InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, prices.Fastest.Cmp(prices.Fast), 0)
+	require.GreaterOrEqual(t, prices.Fast.Cmp(prices.Average), 0)
+	require.GreaterOrEqual(t, prices.Average.Cmp(prices.SafeLow), 0)
+	require.GreaterOrEqual(t, prices.SafeLow.Sign(), 0)
 }
 
 func TestGasPriceManager(t *testing.T) {
 	// create "phony" negative price result so we know the cache is being used
-	prices := ethGasStationResponse{
-		Fast:    -1.0,
-		Fastest: -1.0,
-		SafeLow: -1.0,
-		Average: -1.0,
+	prices := GasPrices{
+		Fast:    big.NewInt(-100000000),
+		Fastest: big.NewInt(-100000000),
+		SafeLow: big.NewInt(-100000000),
+		Average: big.NewInt(-100000000),
 	}
 
 	mgr := gasPriceManager{
-		latestResponse: prices,
-		fetchedAt:      time.Now(),
-		maxResultAge:   50 * time.Millisecond,
+		latestPrices: prices,
+		fetchedAt:    time.Now(),
+		maxResultAge: 50 * time.Millisecond,
 	}
 
 	// 1. should use a cached result up til duration has passed
 	// - we can ensure a cached result is used by manually setting a cached result as -1
-	cachedResult, err := mgr.suggestCachedGasPrice(GasPriorityFast)
+	cachedResult, err := mgr.suggestCachedGasPrice(context.Background(), GasPriorityFast)
 	require.NoError(t, err)
 	assert.Equal(t, "-100000000", cachedResult.String(), "cached result should be negative since we manually set the result")
 
 	// 2. should fetch a new result after duration has passed
 	time.Sleep(51 * time.Millisecond)
-	newResult, err := mgr.suggestCachedGasPrice(GasPriorityFast)
+	newResult, err := mgr.suggestCachedGasPrice(context.Background(), GasPriorityFast)
 	require.NoError(t, err)
 	assert.Equal(t, newResult.Cmp(big.NewInt(0)), 1, "new result should be greater than 0")
 }
+
+func BenchmarkGasPriceManagerCacheHit(b *testing.B) {
+	mgr := gasPriceManager{
+		latestPrices: GasPrices{Fast: big.NewInt(20000000000)},
+		fetchedAt:    time.Now(),
+		maxResultAge: time.Hour,
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := mgr.suggestCachedGasPrice(context.Background(), GasPriorityFast); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGasPriceManagerPartialPrices(t *testing.T) {
+	var calls int32
+	mgr := gasPriceManager{
+		maxResultAge: time.Minute,
+		fetch:        countingProvider(0, nil, &calls).Fetch,
+	}
+
+	// 1. the reported level is served from the stored value
+	first, err := mgr.suggestCachedGasPrice(context.Background(), GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", first.String())
+	second, err := mgr.suggestCachedGasPrice(context.Background(), GasPriorityFast)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	// 2. missing levels return an error without loading the response again
+	_, err = mgr.suggestCachedGasPrice(context.Background(), GasPrioritySafeLow)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 3. levels that failed validation return their stored error from the cache, the others their stored value
+	response := ethGasStationResponse{Fast: "200", Fastest: "250", SafeLow: "-100", Average: "150"}
+	mgr = gasPriceManager{
+		maxResultAge: time.Minute,
+		fetch: func(context.Context) (GasPrices, error) {
+			atomic.AddInt32(&calls, 1)
+			return newGasPrices(response, InputScaleTenthsOfGwei)
+		},
+	}
+	for i := 0; i < 2; i++ {
+		_, err = mgr.suggestCachedGasPrice(context.Background(), GasPrioritySafeLow)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid gas price for safeLow")
+		price, err := mgr.suggestCachedGasPrice(context.Background(), GasPriorityAverage)
+		require.NoError(t, err)
+		assert.Equal(t, "15000000000", price.String())
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestGasPriceManagerClockSkew(t *testing.T) {
+	var calls int32
+	mgr := gasPriceManager{
+		// a wall clock time without a monotonic reading, as if the clock jumped backward after it was read
+		fetchedAt:    time.Now().Add(time.Hour).Round(0),
+		maxResultAge: time.Minute,
+		fetch:        countingProvider(0, nil, &calls).Fetch,
+	}
+
+	// 1. a result from the future is treated as expired rather than fresh forever
+	_, err := mgr.latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 2. the refreshed result carries a monotonic reading
+	_, err = mgr.latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestConcurrentSuggestAndSetKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(serveTestResponse))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// the package-level functions use the default HTTP client, which is pointed at the test server for the test
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = testTransport{server: serverURL}
+	defer func() { http.DefaultClient.Transport = transport }()
+	defer resetKey()
+
+	c, err := NewClient(WithHTTPClient(&http.Client{Transport: testTransport{server: serverURL}}))
+	require.NoError(t, err)
+	suggest, err := c.NewGasPriceSuggester(time.Microsecond)
+	require.NoError(t, err)
+	cached, err := NewGasPriceSuggester(time.Microsecond)
+	require.NoError(t, err)
+
+	// 1. prices are loaded while the package-level and client keys change, which the race detector checks
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				switch (i + j) % 5 {
+				case 0:
+					SetKey("key")
+				case 1:
+					assert.NoError(t, c.SetKey("rotated"))
+				case 2:
+					_, err := SuggestGasPrice(GasPriorityFast)
+					assert.NoError(t, err)
+				case 3:
+					_, err := suggest(GasPriorityFast)
+					assert.NoError(t, err)
+				case 4:
+					_, err := cached(GasPrioritySafeLow)
+					assert.NoError(t, err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
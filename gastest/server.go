@@ -0,0 +1,114 @@
+// Package gastest provides a mock ETH Gas Station server for testing code that uses the gas package, without reaching
+// the real API.
+package gastest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/18dew/go-gas"
+)
+
+// Server is a mock ETH Gas Station API serving configurable prices in the real wire format, as encoded by
+// GasPrices.ToETHGasStationJSON. Pass its URL to gas.WithURL to point a client at it, with gas.WithRequireTLS(false)
+// for a client with an API key. Its settings may be changed while it is serving, and apply to the next requests.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	prices   gas.GasPrices
+	status   int
+	body     []byte
+	delay    time.Duration
+	failures int
+	failWith int
+	requests int
+}
+
+// NewServer starts a server serving prices, which must have a price for each priority level. The caller should call
+// Close when done.
+func NewServer(prices gas.GasPrices) *Server {
+	s := &Server{prices: prices, status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// SetPrices replaces the prices served.
+func (s *Server) SetPrices(prices gas.GasPrices) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices = prices
+}
+
+// SetStatus makes the server respond with the status code, without prices unless it is 200 OK.
+func (s *Server) SetStatus(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = code
+}
+
+// SetBody replaces the response body with body, such as malformed JSON, until it is reset with a nil body.
+func (s *Server) SetBody(body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+}
+
+// SetDelay delays each response by delay, or until the request is canceled.
+func (s *Server) SetDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = delay
+}
+
+// FailNext makes the next n requests fail with the status code, before serving as configured again, for exercising
+// retries.
+func (s *Server) FailNext(n, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+	s.failWith = code
+}
+
+// Requests returns the number of requests the server has received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	prices, status, body, delay := s.prices, s.status, s.body, s.delay
+	if s.failures > 0 {
+		s.failures--
+		status = s.failWith
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	if body == nil {
+		var err error
+		if body, err = prices.ToETHGasStationJSON(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
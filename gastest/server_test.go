@@ -0,0 +1,75 @@
+package gastest
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/18dew/go-gas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPrices(fast int64) gas.GasPrices {
+	return gas.GasPrices{
+		SafeLow: big.NewInt(10e9),
+		Average: big.NewInt(15e9),
+		Fast:    big.NewInt(fast),
+		Fastest: big.NewInt(25e9),
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer(testPrices(20e9))
+	defer server.Close()
+	client, err := gas.NewClient(gas.WithURL(server.URL))
+	require.NoError(t, err)
+	defer client.Close()
+
+	// 1. the prices are served in the wire format of the ETH Gas Station API
+	price, err := client.SuggestGasPrice(gas.GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, 1, server.Requests())
+
+	// 2. the prices can be replaced while serving
+	server.SetPrices(testPrices(22e9))
+	price, err = client.SuggestGasPrice(gas.GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "22000000000", price.String())
+
+	// 3. status codes are reported as fetch errors
+	server.SetStatus(http.StatusServiceUnavailable)
+	_, err = client.SuggestGasPrice(gas.GasPriorityFast)
+	var fetchErr *gas.FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusServiceUnavailable, fetchErr.StatusCode)
+	server.SetStatus(http.StatusOK)
+
+	// 4. a malformed body fails to decode
+	server.SetBody([]byte(`{"fast": `))
+	_, err = client.SuggestGasPrice(gas.GasPriorityFast)
+	assert.Error(t, err)
+	server.SetBody(nil)
+
+	// 5. failing the next requests exercises retries
+	retrying, err := gas.NewClient(gas.WithURL(server.URL), gas.WithRetry(2, time.Millisecond))
+	require.NoError(t, err)
+	defer retrying.Close()
+	server.FailNext(2, http.StatusBadGateway)
+	requests := server.Requests()
+	price, err = retrying.SuggestGasPrice(gas.GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "22000000000", price.String())
+	assert.Equal(t, requests+3, server.Requests())
+
+	// 6. delayed responses are bounded by the timeout of the client
+	server.SetDelay(time.Second)
+	slow, err := gas.NewClient(gas.WithURL(server.URL), gas.WithTimeout(10*time.Millisecond))
+	require.NoError(t, err)
+	defer slow.Close()
+	_, err = slow.SuggestGasPrice(gas.GasPriorityFast)
+	assert.Error(t, err)
+}
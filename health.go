@@ -0,0 +1,40 @@
+package gas
+
+import "context"
+
+// Ping checks that prices can be loaded from the provider, bypassing any cache, with the retries and timeout the
+// client is configured with. The prices are checked for invalid values like any response, but are otherwise
+// discarded, and are not compared to the last accepted prices of WithMaxPriceChange.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.failFast {
+		ctx = ContextWithFailFast(ctx)
+	}
+	ctx, done, err := c.begin(ctx)
+	if err != nil {
+		return c.wrapError(err)
+	}
+	defer done()
+
+	prices, err := c.retry(ctx, c.source().Fetch)
+	if err != nil {
+		if c.isClosed() {
+			err = ErrClientClosed
+		}
+		return c.wrapError(err)
+	}
+	if err := validatePrices(prices, c.rejectZero); err != nil {
+		return c.wrapError(err)
+	}
+	return nil
+}
+
+// startupHealthCheck pings the provider once the client is configured, bounded by the configured timeout
+func (c *Client) startupHealthCheck() error {
+	ctx := context.Background()
+	if c.startupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.startupTimeout)
+		defer cancel()
+	}
+	return c.Ping(ctx)
+}
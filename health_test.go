@@ -0,0 +1,62 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientPing(t *testing.T) {
+	// 1. a reachable provider is healthy, and the cache is bypassed
+	var requests int32
+	c, stop := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 1, &requests), WithMaxResultAge(time.Hour))
+	defer stop()
+
+	assert.Error(t, c.Ping(context.Background()))
+	assert.NoError(t, c.Ping(context.Background()))
+	assert.NoError(t, c.Ping(context.Background()))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	_, _, ok := c.CachedPrices()
+	assert.False(t, ok)
+
+	// 2. a closed client is not healthy
+	require.NoError(t, c.Close())
+	assert.True(t, errors.Is(c.Ping(context.Background()), ErrClientClosed))
+}
+
+func TestWithStartupHealthCheck(t *testing.T) {
+	// 1. construction fails if the provider can't be reached
+	var requests int32
+	base, stop := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 1, &requests))
+	defer stop()
+	httpClient := base.httpClient
+
+	_, err := NewClient(WithHTTPClient(httpClient), WithStartupHealthCheck(time.Second))
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusServiceUnavailable, fetchErr.StatusCode)
+
+	// 2. and succeeds once it can
+	_, err = NewClient(WithHTTPClient(httpClient), WithStartupHealthCheck(time.Second))
+	assert.NoError(t, err)
+
+	// 3. the check is bounded by its timeout
+	slow := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		<-ctx.Done()
+		return GasPrices{}, &FetchError{Err: ctx.Err()}
+	})
+	start := time.Now()
+	_, err = NewClient(WithProvider(slow), WithStartupHealthCheck(10*time.Millisecond))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// 4. negative timeouts are rejected
+	_, err = NewClient(WithStartupHealthCheck(-time.Second))
+	assert.Error(t, err)
+}
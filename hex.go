@@ -0,0 +1,37 @@
+package gas
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// SuggestGasPriceHex is like SuggestGasPrice, but returns the price encoded as an Ethereum JSON-RPC quantity, such as
+// "0x4a817c800", for use in raw transaction objects.
+func (c *Client) SuggestGasPriceHex(priority GasPriority) (string, error) {
+	price, err := c.SuggestGasPrice(priority)
+	if err != nil {
+		return "", err
+	}
+	return encodeQuantity(price)
+}
+
+// encodeQuantity encodes x as a 0x-prefixed hex quantity without leading zeros, where zero is encoded as "0x0"
+func encodeQuantity(x *big.Int) (string, error) {
+	if x.Sign() < 0 {
+		return "", errors.New("eth: quantity must not be negative")
+	}
+	return "0x" + x.Text(16), nil
+}
+
+// decodeQuantity decodes a 0x-prefixed hex quantity
+func decodeQuantity(s string) (*big.Int, error) {
+	if !strings.HasPrefix(s, "0x") || len(s) == 2 {
+		return nil, errors.New("eth: invalid hex quantity")
+	}
+	x, ok := new(big.Int).SetString(s[2:], 16)
+	if !ok || x.Sign() < 0 {
+		return nil, errors.New("eth: invalid hex quantity")
+	}
+	return x, nil
+}
@@ -0,0 +1,59 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeQuantity(t *testing.T) {
+	// the expected values match the output of go-ethereum's hexutil.EncodeBig
+	cases := []struct {
+		value    *big.Int
+		expected string
+	}{
+		{big.NewInt(0), "0x0"},
+		{big.NewInt(1), "0x1"},
+		{big.NewInt(0xff), "0xff"},
+		{big.NewInt(0x1122334455667788), "0x1122334455667788"},
+		{big.NewInt(20000000000), "0x4a817c800"},
+		{new(big.Int).Lsh(big.NewInt(1), 64), "0x10000000000000000"},
+	}
+
+	for _, c := range cases {
+		encoded, err := encodeQuantity(c.value)
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, encoded)
+	}
+
+	_, err := encodeQuantity(big.NewInt(-1))
+	assert.Error(t, err)
+}
+
+func TestDecodeQuantity(t *testing.T) {
+	// 1. quantities round trip through encodeQuantity
+	for _, value := range []int64{0, 1, 20000000000} {
+		encoded, err := encodeQuantity(big.NewInt(value))
+		require.NoError(t, err)
+		decoded, err := decodeQuantity(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, value, decoded.Int64())
+	}
+
+	// 2. malformed quantities are rejected
+	for _, raw := range []string{"", "0x", "12", "0xzz", "0x-1"} {
+		_, err := decodeQuantity(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestClientSuggestGasPriceHex(t *testing.T) {
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+
+	price, err := c.SuggestGasPriceHex(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "0x4a817c800", price)
+}
@@ -0,0 +1,36 @@
+package gas
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// keyValidator is implemented by providers that can check the format of their API key before making requests
+type keyValidator interface {
+	validateKey() error
+}
+
+// ValidateKey returns an error if key is obviously malformed, because it is empty or contains whitespace or control
+// characters, which usually comes from a misconfiguration such as a trailing newline in a secret file. It does not
+// check the length or alphabet of the key, so valid keys are not rejected when a provider changes its key format.
+func ValidateKey(key string) error {
+	if key == "" {
+		return errors.New("eth: api key must not be empty")
+	}
+	if strings.IndexFunc(key, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		return errors.New("eth: api key must not contain whitespace or control characters")
+	}
+	return nil
+}
+
+// validateKey checks the key of the provider, or the key set with SetKey if it has none, since both are optional
+func (p *ETHGasStationProvider) validateKey() error {
+	if p.APIKey != "" {
+		return ValidateKey(p.APIKey)
+	}
+	if key, ok := globalKey(); ok {
+		return ValidateKey(key)
+	}
+	return nil
+}
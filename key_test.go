@@ -0,0 +1,50 @@
+package gas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateKey(t *testing.T) {
+	// 1. keys of any length and alphabet are accepted
+	for _, key := range []string{"abc", "d3f1-9a2b_XYZ.=", "0123456789abcdef0123456789abcdef"} {
+		assert.NoError(t, ValidateKey(key), key)
+	}
+
+	// 2. obviously malformed keys are rejected
+	for _, key := range []string{"", " ", "key\n", "my key", "key\x00"} {
+		assert.Error(t, ValidateKey(key), key)
+	}
+}
+
+func TestClientKeyValidation(t *testing.T) {
+	// 1. malformed keys are rejected when the client is configured
+	_, err := NewClient(WithAPIKey("key\n"))
+	assert.Error(t, err)
+	_, err = NewClient(WithProvider(&BlocknativeProvider{}))
+	assert.Error(t, err)
+	_, err = NewClient(WithProvider(&BlocknativeProvider{APIKey: "key"}))
+	assert.NoError(t, err)
+
+	// 2. a malformed package-level key is reported by NewClient
+	defer resetKey()
+	SetKey(" ")
+	_, err = NewClient()
+	assert.Error(t, err)
+
+	// 3. changing the key of a running client is validated, while an empty key reverts to the package-level key
+	SetKey("key")
+	c, err := NewClient()
+	assert.NoError(t, err)
+	assert.Error(t, c.SetKey("new key"))
+	assert.NoError(t, c.SetKey(""))
+}
+
+// resetKey clears the key set with SetKey
+func resetKey() {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	key = ""
+	keybased = false
+}
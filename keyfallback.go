@@ -0,0 +1,49 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// withFreeEndpointFallback returns fetch, retried once against the free endpoint if the key of the default provider
+// is rejected with a 401 or 403 status code
+func (c *Client) withFreeEndpointFallback(
+	fetch func(context.Context) (GasPrices, error),
+) func(context.Context) (GasPrices, error) {
+	return func(ctx context.Context) (GasPrices, error) {
+		prices, err := fetch(ctx)
+		var fetchErr *FetchError
+		if !errors.As(err, &fetchErr) ||
+			(fetchErr.StatusCode != http.StatusUnauthorized && fetchErr.StatusCode != http.StatusForbidden) {
+			return prices, err
+		}
+		if c.warn != nil {
+			c.warn(fmt.Sprintf("eth: api key was rejected with status %d, falling back to the free ETH Gas Station "+
+				"endpoint", fetchErr.StatusCode))
+		}
+
+		free := c.defaultProvider()
+		free.APIKey = ""
+		if free.URL == "" {
+			// the default URL would use a key set with SetKey
+			free.URL = ETHGasStationURL
+		}
+		if prices, err = free.Fetch(ctx); err != nil {
+			return GasPrices{}, fmt.Errorf("eth: api key was rejected and the free endpoint failed: %w", err)
+		}
+		return prices, nil
+	}
+}
+
+// usesKey reports whether the default provider sends an API key, of its own, from a key pool or set with SetKey
+func (c *Client) usesKey() bool {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	if c.apiKey != "" || c.keys != nil {
+		return true
+	}
+	_, keyed := globalKey()
+	return c.url == "" && keyed
+}
@@ -0,0 +1,56 @@
+package gas
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFreeEndpointFallback(t *testing.T) {
+	status := http.StatusForbidden
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-key") != "" {
+			w.WriteHeader(status)
+			return
+		}
+		serveTestResponse(w, r)
+	}))
+	defer server.Close()
+	var warnings []string
+	warn := func(msg string) {
+		warnings = append(warnings, msg)
+	}
+
+	// 1. a rejected key fails the call by default
+	c, err := NewClient(WithURL(server.URL), WithAPIKey("revoked"), WithRequireTLS(false))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusForbidden, fetchErr.StatusCode)
+
+	// 2. with the fallback, the request is retried without the key and a warning is logged
+	c, err = NewClient(WithURL(server.URL), WithAPIKey("revoked"), WithRequireTLS(false), WithFreeEndpointFallback(),
+		WithWarningLogger(warn))
+	require.NoError(t, err)
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "403")
+
+	// 3. other failures don't fall back
+	status = http.StatusInternalServerError
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusInternalServerError, fetchErr.StatusCode)
+	assert.Len(t, warnings, 1)
+
+	// 4. the fallback requires the default provider
+	_, err = NewClient(WithProvider(countingProvider(0, nil, new(int32))), WithFreeEndpointFallback())
+	assert.Error(t, err)
+}
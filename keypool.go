@@ -0,0 +1,94 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultKeyCooldown is how long a key of a client configured with WithKeys is parked after it is rate limited or
+// rejected, unless configured otherwise with WithKeyCooldown.
+const DefaultKeyCooldown = time.Minute
+
+// keyPool rotates through API keys round-robin, skipping keys that are parked
+type keyPool struct {
+	mu          sync.Mutex
+	keys        []string
+	parkedUntil []time.Time
+	next        int
+	cooldown    time.Duration
+
+	// now returns the current time, it defaults to time.Now
+	now func() time.Time
+}
+
+func newKeyPool(keys []string) *keyPool {
+	return &keyPool{
+		keys:        append([]string(nil), keys...),
+		parkedUntil: make([]time.Time, len(keys)),
+		cooldown:    DefaultKeyCooldown,
+	}
+}
+
+// acquire returns the index of the next key that isn't parked. If every key is parked, the key whose cooldown ends
+// first is used rather than failing the request.
+func (p *keyPool) acquire() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock()
+	soonest := -1
+	for i := range p.keys {
+		index := (p.next + i) % len(p.keys)
+		if !now.Before(p.parkedUntil[index]) {
+			soonest = index
+			break
+		}
+		if soonest < 0 || p.parkedUntil[index].Before(p.parkedUntil[soonest]) {
+			soonest = index
+		}
+	}
+	p.next = (soonest + 1) % len(p.keys)
+	return soonest
+}
+
+// park stops using the key at index until its cooldown has passed
+func (p *keyPool) park(index int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.parkedUntil[index] = p.clock().Add(p.cooldown)
+}
+
+func (p *keyPool) clock() time.Time {
+	if p.now == nil {
+		return time.Now()
+	}
+	return p.now()
+}
+
+// keyPoolProvider makes each request of provider with the next key of pool, parking keys that are rate limited or
+// rejected
+type keyPoolProvider struct {
+	pool     *keyPool
+	provider ETHGasStationProvider
+}
+
+func (p *keyPoolProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	index := p.pool.acquire()
+	provider := p.provider
+	provider.APIKey = p.pool.keys[index]
+
+	prices, err := provider.Fetch(ctx)
+	var fetchErr *FetchError
+	if errors.As(err, &fetchErr) &&
+		(fetchErr.StatusCode == http.StatusTooManyRequests || fetchErr.StatusCode == http.StatusForbidden) {
+		p.pool.park(index)
+	}
+	return prices, err
+}
+
+func (p *keyPoolProvider) CloseIdleConnections() {
+	p.provider.CloseIdleConnections()
+}
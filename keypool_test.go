@@ -0,0 +1,97 @@
+package gas
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithKeys(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		used []string
+	)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("api-key")
+		mu.Lock()
+		used = append(used, key)
+		mu.Unlock()
+		if key == "limited" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		serveTestResponse(w, r)
+	}
+	usedKeys := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		keys := used
+		used = nil
+		return keys
+	}
+
+	c, stop := newTestClient(t, handler, WithKeys([]string{"a", "limited", "b"}), WithRetry(1, time.Millisecond))
+	defer stop()
+
+	// 1. keys are used round-robin, and a rate limited request is retried with the next key
+	for i := 0; i < 2; i++ {
+		_, err := c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"a", "limited", "b"}, usedKeys())
+
+	// 2. the rate limited key is parked
+	for i := 0; i < 3; i++ {
+		_, err := c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"a", "b", "a"}, usedKeys())
+
+	// 3. the key is used again after its cooldown
+	now := time.Now().Add(DefaultKeyCooldown)
+	c.keys.now = func() time.Time { return now }
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"limited", "b"}, usedKeys())
+
+	// 4. a key pool can't be changed like a single key
+	assert.Error(t, c.SetKey("other"))
+}
+
+func TestKeyPoolAllParked(t *testing.T) {
+	now := time.Now()
+	pool := newKeyPool([]string{"a", "b"})
+	pool.now = func() time.Time { return now }
+
+	// 1. if every key is parked, the key whose cooldown ends first is used
+	pool.park(1)
+	now = now.Add(time.Second)
+	pool.park(0)
+	assert.Equal(t, 1, pool.acquire())
+	assert.Equal(t, 1, pool.acquire())
+}
+
+func TestWithKeysInvalid(t *testing.T) {
+	// 1. empty pools and malformed keys are rejected
+	_, err := NewClient(WithKeys(nil))
+	assert.Error(t, err)
+	_, err = NewClient(WithKeys([]string{"a", "b c"}))
+	assert.Error(t, err)
+
+	// 2. a pool can't be combined with a single key, and a cooldown requires a pool
+	_, err = NewClient(WithKeys([]string{"a"}), WithAPIKey("b"))
+	assert.Error(t, err)
+	_, err = NewClient(WithKeyCooldown(time.Second))
+	assert.Error(t, err)
+	_, err = NewClient(WithKeyCooldown(0))
+	assert.Error(t, err)
+
+	// 3. the cooldown is configurable
+	c, err := NewClient(WithKeys([]string{"a"}), WithKeyCooldown(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, c.keys.cooldown)
+}
@@ -0,0 +1,47 @@
+package gas
+
+import "time"
+
+// FetchInfo describes a successful fetch of prices by a client, as returned by Client.LastFetch.
+type FetchInfo struct {
+	// Prices are the prices the fetch loaded, after any transform or rounding configured on the client. They are a
+	// copy and may be modified freely.
+	Prices GasPrices
+
+	// FetchedAt is when the fetch completed, and Duration how long it took, including retries.
+	FetchedAt time.Time
+	Duration  time.Duration
+
+	// Source is the name of the provider the prices were loaded from, as reported by ProbeAll.
+	Source string
+}
+
+// LastFetch returns what is known about the most recent successful fetch of the client, whether it was made by a call,
+// a background refresh or a Refresher, for dashboards and debugging. It returns false if no fetch has succeeded yet.
+// Prices served from a cache or computed by WithLastResort are not fetches.
+func (c *Client) LastFetch() (FetchInfo, bool) {
+	c.lastFetchMu.Lock()
+	defer c.lastFetchMu.Unlock()
+
+	if c.lastFetch.FetchedAt.IsZero() {
+		return FetchInfo{}, false
+	}
+	info := c.lastFetch
+	info.Prices = info.Prices.copy()
+	return info, true
+}
+
+// recordFetch stores prices as the most recent successful fetch, which started at start
+func (c *Client) recordFetch(prices GasPrices, start time.Time) {
+	now := time.Now()
+	info := FetchInfo{
+		Prices:    prices.copy(),
+		FetchedAt: now,
+		Duration:  now.Sub(start),
+		Source:    providerName(c.source()),
+	}
+
+	c.lastFetchMu.Lock()
+	defer c.lastFetchMu.Unlock()
+	c.lastFetch = info
+}
@@ -0,0 +1,44 @@
+package gas
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastFetch(t *testing.T) {
+	var calls int32
+	c, err := NewClient(WithProvider(NamedProvider("primary", countingProvider(1, errors.New("down"), &calls))),
+		WithMaxResultAge(time.Minute))
+	require.NoError(t, err)
+
+	// 1. nothing is reported before a successful fetch
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+	_, ok := c.LastFetch()
+	assert.False(t, ok)
+
+	// 2. a successful fetch is reported with its prices, time and source
+	start := time.Now()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	info, ok := c.LastFetch()
+	require.True(t, ok)
+	assert.Equal(t, "20000000000", info.Prices.Fast.String())
+	assert.False(t, info.FetchedAt.Before(start))
+	assert.GreaterOrEqual(t, int64(info.Duration), int64(0))
+	assert.Equal(t, "primary", info.Source)
+
+	// 3. cache hits are not fetches, and the returned prices are a copy
+	info.Prices.Fast.SetInt64(1)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	again, ok := c.LastFetch()
+	require.True(t, ok)
+	assert.Equal(t, info.FetchedAt, again.FetchedAt)
+	assert.Equal(t, "20000000000", again.Prices.Fast.String())
+	assert.Equal(t, int32(2), calls)
+}
@@ -0,0 +1,156 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// MempoolProvider is a Provider that computes prices from the gas prices of the pending transactions in the mempool of
+// an Ethereum node, using the txpool_content JSON-RPC method. It is more forward looking than prices computed from
+// recent blocks, but the node must expose the txpool namespace and have a well-connected mempool.
+//
+// Each priority level is served from a percentile of the pending gas prices, the percentile returned by
+// PriorityToPercentile unless overridden. The gas price of an EIP-1559 transaction is its max fee per gas.
+type MempoolProvider struct {
+	// URL is the JSON-RPC endpoint of the node.
+	URL string
+
+	// Method replaces the txpool_content method, for nodes that serve the same result from a custom method.
+	Method string
+
+	// Percentiles overrides the percentile, between 0 and 100, that priority levels are served from.
+	Percentiles map[GasPriority]float64
+
+	// HTTPClient is used to make requests, it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// BlockTime is the block time of the chain of the node, such as 12 seconds for Ethereum, which is reported in
+	// GasPrices.BlockTime since the node doesn't report it.
+	BlockTime time.Duration
+}
+
+type mempoolTransaction struct {
+	GasPrice     string `json:"gasPrice"`
+	MaxFeePerGas string `json:"maxFeePerGas"`
+}
+
+type txpoolContent struct {
+	// Pending maps sender addresses to their pending transactions by nonce
+	Pending map[string]map[string]mempoolTransaction `json:"pending"`
+}
+
+// Fetch loads the pending transactions from the node and computes prices from their gas prices.
+func (p *MempoolProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	prices, err := p.pendingPrices(ctx)
+	if err != nil {
+		return GasPrices{}, err
+	}
+	return p.newGasPrices(prices)
+}
+
+// FetchPercentile loads the pending transactions from the node and returns the given percentile of their gas prices.
+func (p *MempoolProvider) FetchPercentile(ctx context.Context, percentile float64) (*big.Int, error) {
+	if !(percentile >= 0 && percentile <= 100) {
+		return nil, errors.New("eth: percentile must be between 0 and 100")
+	}
+	prices, err := p.pendingPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) == 0 {
+		return nil, errors.New("eth: no pending transactions in mempool")
+	}
+	return new(big.Int).Set(nearestRank(prices, percentile)), nil
+}
+
+// pendingPrices loads the gas prices of the pending transactions from the node, sorted in ascending order
+func (p *MempoolProvider) pendingPrices(ctx context.Context) ([]*big.Int, error) {
+	method := p.Method
+	if method == "" {
+		method = "txpool_content"
+	}
+	var content txpoolContent
+	if err := callRPC(ctx, p.client(), p.URL, method, nil, &content); err != nil {
+		return nil, err
+	}
+
+	var prices []*big.Int
+	for _, transactions := range content.Pending {
+		for _, transaction := range transactions {
+			raw := transaction.GasPrice
+			if raw == "" {
+				raw = transaction.MaxFeePerGas
+			}
+			price, err := decodeQuantity(raw)
+			if err != nil {
+				return nil, err
+			}
+			prices = append(prices, price)
+		}
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	return prices, nil
+}
+
+// CloseIdleConnections closes idle connections of the underlying HTTP client.
+func (p *MempoolProvider) CloseIdleConnections() {
+	p.client().CloseIdleConnections()
+}
+
+func (p *MempoolProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
+
+func (p *MempoolProvider) percentile(priority GasPriority) float64 {
+	if percentile, ok := p.Percentiles[priority]; ok {
+		return percentile
+	}
+	return PriorityToPercentile(priority)
+}
+
+// newGasPrices serves each priority level from its percentile of the sorted pending gas prices
+func (p *MempoolProvider) newGasPrices(prices []*big.Int) (GasPrices, error) {
+	if len(prices) == 0 {
+		return GasPrices{}, errors.New("eth: no pending transactions in mempool")
+	}
+
+	result := GasPrices{BlockTime: p.BlockTime}
+	for _, priority := range priorityOrder {
+		percentile := p.percentile(priority)
+		if !(percentile >= 0 && percentile <= 100) {
+			return GasPrices{}, errors.New("eth: percentile must be between 0 and 100")
+		}
+		price := new(big.Int).Set(nearestRank(prices, percentile))
+
+		switch priority {
+		case GasPriorityFast:
+			result.Fast = price
+		case GasPriorityFastest:
+			result.Fastest = price
+		case GasPrioritySafeLow:
+			result.SafeLow = price
+		case GasPriorityAverage:
+			result.Average = price
+		}
+	}
+	return result, nil
+}
+
+// nearestRank returns the percentile of sorted values using the nearest-rank method
+func nearestRank(sorted []*big.Int, percentile float64) *big.Int {
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
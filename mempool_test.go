@@ -0,0 +1,92 @@
+package gas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveTxpool responds to JSON-RPC requests for method with a mempool of one transaction per gas price in gwei
+func serveTxpool(t *testing.T, method string, gwei ...int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if request.Method != method {
+			_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "error": {"code": -32601, "message": "method not found"}}`))
+			return
+		}
+
+		var senders []string
+		for i, price := range gwei {
+			wei := new(big.Int).Mul(big.NewInt(price), big.NewInt(1e9))
+			field := "gasPrice"
+			if i%2 == 1 {
+				// EIP-1559 transactions only have a max fee
+				field = "maxFeePerGas"
+			}
+			senders = append(senders, fmt.Sprintf(`"0x%040x": {"0": {%q: "0x%s"}}`, i, field, wei.Text(16)))
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 1, "result": {"pending": {%s}, "queued": {}}}`,
+			strings.Join(senders, ","))
+	}
+}
+
+func TestMempoolProvider(t *testing.T) {
+	server := httptest.NewServer(serveTxpool(t, "txpool_content", 7, 3, 10, 1, 5, 2, 9, 4, 8, 6))
+	defer server.Close()
+
+	// 1. priority levels are served from percentiles of the pending gas prices
+	provider := &MempoolProvider{URL: server.URL}
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "4000000000", prices.SafeLow.String())
+	assert.Equal(t, "6000000000", prices.Average.String())
+	assert.Equal(t, "9000000000", prices.Fast.String())
+	assert.Equal(t, "10000000000", prices.Fastest.String())
+
+	// 2. the percentiles are configurable
+	provider.Percentiles = map[GasPriority]float64{GasPriorityFast: 50}
+	prices, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "5000000000", prices.Fast.String())
+
+	// 3. any percentile can be loaded on its own
+	price, err := provider.FetchPercentile(context.Background(), 20)
+	require.NoError(t, err)
+	assert.Equal(t, "2000000000", price.String())
+	_, err = provider.FetchPercentile(context.Background(), 101)
+	assert.Error(t, err)
+
+	// 4. rpc errors are returned
+	provider.Method = "custom_pendingPrices"
+	_, err = provider.Fetch(context.Background())
+	assert.EqualError(t, err, "eth: rpc error: method not found")
+}
+
+func TestMempoolProviderCustomMethod(t *testing.T) {
+	server := httptest.NewServer(serveTxpool(t, "custom_pendingPrices", 1))
+	defer server.Close()
+
+	// 1. a custom method is called instead of txpool_content
+	provider := &MempoolProvider{URL: server.URL, Method: "custom_pendingPrices"}
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1000000000", prices.SafeLow.String())
+	assert.Equal(t, "1000000000", prices.Fastest.String())
+
+	// 2. an empty mempool is an error
+	server = httptest.NewServer(serveTxpool(t, "txpool_content"))
+	defer server.Close()
+	_, err = (&MempoolProvider{URL: server.URL}).Fetch(context.Background())
+	assert.Error(t, err)
+}
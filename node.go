@@ -0,0 +1,82 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"net/http"
+)
+
+// NodeProvider is a Provider that loads prices from the eth_gasPrice JSON-RPC method of an Ethereum node, such as the
+// caller's own node, which reports the price in wei directly so no unit conversion applies.
+//
+// The node suggests a single price, which every priority level is served from. Multipliers scale it per level, such as
+// 0.9 for GasPrioritySafeLow and 1.25 for GasPriorityFastest, to spread the levels around the suggestion.
+type NodeProvider struct {
+	// URL is the JSON-RPC endpoint of the node.
+	URL string
+
+	// Multipliers scales the price of the node for a priority level, rounded to the nearest wei. Levels without a
+	// multiplier are served the price of the node. Multipliers must be positive and finite, which NewClient and
+	// SetProvider check.
+	Multipliers map[GasPriority]float64
+
+	// HTTPClient is used to make requests, it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Fetch loads the gas price suggested by the node.
+func (p *NodeProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	var raw string
+	if err := callRPC(ctx, p.client(), p.URL, "eth_gasPrice", nil, &raw); err != nil {
+		return GasPrices{}, err
+	}
+	suggested, err := decodeQuantity(raw)
+	if err != nil {
+		return GasPrices{}, err
+	}
+
+	var prices GasPrices
+	for _, priority := range priorityOrder {
+		price := new(big.Int).Set(suggested)
+		if multiplier, ok := p.Multipliers[priority]; ok {
+			scaled := new(big.Rat).SetInt(suggested)
+			price = roundRat(scaled.Mul(scaled, new(big.Rat).SetFloat64(multiplier)))
+		}
+
+		switch priority {
+		case GasPriorityFast:
+			prices.Fast = price
+		case GasPriorityFastest:
+			prices.Fastest = price
+		case GasPrioritySafeLow:
+			prices.SafeLow = price
+		case GasPriorityAverage:
+			prices.Average = price
+		}
+	}
+	return prices, nil
+}
+
+// validateConfig checks the multipliers of the provider
+func (p *NodeProvider) validateConfig() error {
+	for _, multiplier := range p.Multipliers {
+		if !(multiplier > 0) || math.IsInf(multiplier, 0) {
+			return errors.New("eth: gas price multiplier must be positive")
+		}
+	}
+	return nil
+}
+
+// CloseIdleConnections closes idle connections of the underlying HTTP client.
+func (p *NodeProvider) CloseIdleConnections() {
+	p.client().CloseIdleConnections()
+}
+
+func (p *NodeProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
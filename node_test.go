@@ -0,0 +1,61 @@
+package gas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeProvider(t *testing.T) {
+	result := `"0x4a817c800"` // 20 gwei
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_gasPrice", request.Method)
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": ` + result + `}`))
+	}))
+	defer server.Close()
+
+	// 1. every level is served the price of the node, in wei
+	provider := &NodeProvider{URL: server.URL}
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	for _, priority := range Priorities() {
+		price, err := prices.Price(priority)
+		require.NoError(t, err)
+		assert.Equal(t, "20000000000", price.String(), priority)
+	}
+
+	// 2. multipliers spread the levels around it
+	provider.Multipliers = map[GasPriority]float64{GasPrioritySafeLow: 0.9, GasPriorityFastest: 1.25}
+	prices, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "18000000000", prices.SafeLow.String())
+	assert.Equal(t, "20000000000", prices.Average.String())
+	assert.Equal(t, "25000000000", prices.Fastest.String())
+
+	// 3. a client caches and serves it like any provider
+	c, err := NewClient(WithProvider(provider), WithMaxResultAge(time.Minute))
+	require.NoError(t, err)
+	price, err := c.SuggestGasPrice(GasPriorityTurbo)
+	require.NoError(t, err)
+	assert.Equal(t, "22500000000", price.String())
+
+	// 4. invalid multipliers are rejected by the client, and invalid results by the provider
+	invalid := &NodeProvider{URL: server.URL, Multipliers: map[GasPriority]float64{GasPriorityFast: -1}}
+	_, err = NewClient(WithProvider(invalid))
+	assert.Error(t, err)
+	assert.Error(t, c.SetProvider(invalid))
+	provider.Multipliers = nil
+	result = `"20"`
+	_, err = provider.Fetch(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,950 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures a Client created with NewClient.
+type Option func(*Client) error
+
+// WithName sets a name that identifies the client when several are in use, such as one per chain or API key. Errors
+// that occur while loading prices are prefixed with the name, and still match their cause with errors.Is and errors.As.
+func WithName(name string) Option {
+	return func(c *Client) error {
+		c.name = name
+		return nil
+	}
+}
+
+// WithProvider sets the source of gas prices, replacing the default ETHGasStationProvider. Options that configure the
+// default provider, such as WithInputScale and WithHTTPClient, have no effect on a custom provider.
+func WithProvider(provider Provider) Option {
+	return func(c *Client) error {
+		if provider == nil {
+			return errors.New("eth: provider must not be nil")
+		}
+		c.provider = provider
+		return nil
+	}
+}
+
+// WithChainID configures the client for the chain with chainID. Unless configured with WithProvider, prices are loaded
+// from the provider registered for the chain with RegisterChainProvider, and costs are estimated in the native token
+// of the chain registered with RegisterNativeToken, such as MATIC on Polygon. Mainnet keeps the default provider
+// configured by the client's options, unless another provider was registered for it. NewClient returns an error
+// matching ErrUnknownChain if the chain has no provider or native token registered.
+func WithChainID(chainID uint64) Option {
+	return func(c *Client) error {
+		if chainID == 0 {
+			return errors.New("eth: chain id must not be zero")
+		}
+		c.chainID = chainID
+		return nil
+	}
+}
+
+// WithNativeTokenDecimals sets the number of decimals of the native token gas is paid in, for chains whose native token
+// doesn't have the 18 decimals of ether. Prices are then taken to be in the smallest unit of the token, and the gwei
+// and ether helpers of the client, SuggestGasPriceRat and the cost and savings estimates convert them with these
+// decimals, so a gwei is a billionth of a whole token. It defaults to the decimals registered for the chain set with
+// WithChainID, see RegisterNativeTokenDecimals, or 18.
+//
+// decimals must be between 0 and 36.
+func WithNativeTokenDecimals(decimals int) Option {
+	return func(c *Client) error {
+		if err := validateTokenDecimals(decimals); err != nil {
+			return err
+		}
+		c.tokenDecimals = &decimals
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the default provider. Idle connections of the client are closed when
+// the Client is closed. It defaults to http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return errors.New("eth: http client must not be nil")
+		}
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithLocalAddr makes the requests of the default provider originate from the local IP address ip, such as to use an
+// address that is allow-listed by a firewall on a host with several network interfaces. It builds the HTTP client of
+// the default provider, so it can't be combined with WithHTTPClient.
+func WithLocalAddr(ip string) Option {
+	return func(c *Client) error {
+		addr := net.ParseIP(ip)
+		if addr == nil {
+			return errors.New("eth: local address must be an ip address")
+		}
+		c.localAddr = addr
+		return nil
+	}
+}
+
+// WithIPv4Only makes the requests of the default provider only connect over IPv4. It builds the HTTP client of the
+// default provider, so it can't be combined with WithHTTPClient.
+func WithIPv4Only() Option {
+	return func(c *Client) error {
+		c.ipv4Only = true
+		return nil
+	}
+}
+
+// WithSystemProxy makes the requests of the default provider go through the proxy configured by the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables, as http.DefaultTransport does, so a custom HTTP client can't bypass
+// it by accident. The transport of a client set with WithHTTPClient, or built by WithLocalAddr or WithIPv4Only, is
+// cloned with its proxy replaced by http.ProxyFromEnvironment, and the client set with WithHTTPClient is left
+// unchanged. A client whose transport is not an *http.Transport is rejected, since its proxy can't be set.
+//
+// The environment is read once, on the first request through any proxy configured this way.
+func WithSystemProxy() Option {
+	return func(c *Client) error {
+		c.systemProxy = true
+		return nil
+	}
+}
+
+// WithDisableKeepAlives closes the connection of each request of the default provider once it is done, rather than
+// keeping it open for the next request, so short-lived processes such as CLIs that make a single request exit without
+// lingering connections. Like WithSystemProxy, it clones the transport of a custom HTTP client, which must be an
+// *http.Transport.
+func WithDisableKeepAlives() Option {
+	return func(c *Client) error {
+		c.disableKeepAlives = true
+		return nil
+	}
+}
+
+// WithURL replaces the ETH Gas Station endpoint used by the default provider, e.g. to use a proxy or a mirror that
+// serves the same response format.
+func WithURL(rawURL string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("eth: url must be absolute")
+		}
+		c.url = rawURL
+		return nil
+	}
+}
+
+// WithRequireTLS controls whether the URL set with WithURL must use https, so an API key is never sent in plaintext by
+// a misconfigured http:// URL. NewClient returns an error for a plaintext URL if TLS is required, which it is by
+// default for a client configured with an API key or a key pool, and so does SetKey. Disable it to test against a local
+// http mock with a key. The default endpoints always use https.
+func WithRequireTLS(require bool) Option {
+	return func(c *Client) error {
+		c.requireTLS = &require
+		return nil
+	}
+}
+
+// WithAPIKey sets the API key used by the default provider, on the keyed endpoint unless configured with WithURL.
+// Unlike SetKey, it only applies to the client it is passed to. Keys that are rejected by ValidateKey are an error.
+func WithAPIKey(key string) Option {
+	return func(c *Client) error {
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+		c.apiKey = key
+		return nil
+	}
+}
+
+// WithKeys makes the default provider spread its requests over a pool of API keys, using the next key round-robin on
+// each request, retries included. A key that is rate limited or rejected, with status 429 or 403, is parked for
+// DefaultKeyCooldown, or the cooldown set with WithKeyCooldown, and skipped until then. If every key is parked, the
+// one whose cooldown ends first is used. Keys that are rejected by ValidateKey are an error, and it can't be combined
+// with WithAPIKey.
+func WithKeys(keys []string) Option {
+	return func(c *Client) error {
+		if len(keys) == 0 {
+			return errors.New("eth: key pool must not be empty")
+		}
+		for _, key := range keys {
+			if err := ValidateKey(key); err != nil {
+				return err
+			}
+		}
+		c.keys = newKeyPool(keys)
+		return nil
+	}
+}
+
+// WithFreeEndpointFallback makes the default provider retry a request against the free ETH Gas Station endpoint if its
+// API key is rejected with a 401 or 403 status code, so a revoked or expired key degrades to the rate-limited free
+// endpoint rather than failing every call. Each fallback is logged with the logger set with WithWarningLogger. A URL
+// set with WithURL is retried without the key instead. It requires the default provider.
+func WithFreeEndpointFallback() Option {
+	return func(c *Client) error {
+		c.freeFallback = true
+		return nil
+	}
+}
+
+// WithKeyCooldown sets how long a key of the pool configured with WithKeys is parked after it is rate limited or
+// rejected. It defaults to DefaultKeyCooldown.
+func WithKeyCooldown(cooldown time.Duration) Option {
+	return func(c *Client) error {
+		if cooldown <= 0 {
+			return errors.New("eth: key cooldown must be positive")
+		}
+		c.keyCooldown = cooldown
+		return nil
+	}
+}
+
+// WithResponseEnvelopePath makes the default provider decode the gas object at the dotted path within the response,
+// for endpoints behind gateways that wrap the payload in an envelope, such as "data" for
+// {"status": "ok", "data": {...}} or "result.gas" for one nested deeper. By default, or if path is empty, the whole
+// response is decoded.
+func WithResponseEnvelopePath(path string) Option {
+	return func(c *Client) error {
+		if path == "" {
+			c.envelopePath = ""
+			return nil
+		}
+		for _, key := range strings.Split(path, ".") {
+			if key == "" {
+				return errors.New("eth: envelope path must be dot-separated field names")
+			}
+		}
+		c.envelopePath = path
+		return nil
+	}
+}
+
+// WithFieldMapping makes the default provider decode the price of each mapped priority level from another field of the
+// gas object, for gateways that rename or remap the fields of the ETH Gas Station API, such as
+// {GasPriorityAverage: "standard"}. Priority levels that aren't mapped keep the field names of the ETH Gas Station API.
+// It can be combined with WithResponseEnvelopePath, the fields are those of the gas object within the envelope.
+func WithFieldMapping(mapping map[GasPriority]string) Option {
+	return func(c *Client) error {
+		fieldMapping := make(map[GasPriority]string, len(mapping))
+		for priority, name := range mapping {
+			if _, ok := defaultPercentiles[priority]; !ok {
+				return errors.New("eth: unknown/unsupported gas priority")
+			}
+			if name == "" {
+				return errors.New("eth: mapped field names must not be empty")
+			}
+			fieldMapping[priority] = name
+		}
+		c.fieldMapping = fieldMapping
+		return nil
+	}
+}
+
+// WithSchemaDriftHandler makes the default provider call handler with the drift of each response whose gas object has
+// fields it doesn't decode or lacks fields it decodes, as described for DetectSchemaDrift, for early warning of an
+// upstream API change, such as by logging drift.String(). The fetch still succeeds, unless WithStrictSchema is set.
+func WithSchemaDriftHandler(handler func(SchemaDrift)) Option {
+	return func(c *Client) error {
+		if handler == nil {
+			return errors.New("eth: schema drift handler must not be nil")
+		}
+		c.schemaDrift = handler
+		return nil
+	}
+}
+
+// WithStrictSchema makes the default provider fail the fetch of a response whose gas object has unknown or missing
+// fields with an error wrapping ErrSchemaDrift, rather than decoding the fields it knows, so an upstream change can't
+// cause silently wrong prices. It is not retried.
+func WithStrictSchema() Option {
+	return func(c *Client) error {
+		c.strictSchema = true
+		return nil
+	}
+}
+
+// WithAllowedFields makes WithSchemaDriftHandler and WithStrictSchema accept the given fields of the gas object, such
+// as harmless fields added by a gateway, rather than treating them as unknown.
+func WithAllowedFields(fields ...string) Option {
+	return func(c *Client) error {
+		for _, field := range fields {
+			if field == "" {
+				return errors.New("eth: allowed field names must not be empty")
+			}
+		}
+		c.allowedFields = append([]string(nil), fields...)
+		return nil
+	}
+}
+
+// WithResponseCharsetHandling makes the default provider convert responses that declare a charset other than UTF-8 in
+// their Content-Type header to UTF-8 before decoding them, using charsetReader. Use it with charset.NewReaderLabel from
+// golang.org/x/net/html/charset, or a function that only handles the charsets of the endpoint in use. Responses that
+// don't declare a charset are decoded as UTF-8.
+func WithResponseCharsetHandling(charsetReader func(charset string, input io.Reader) (io.Reader, error)) Option {
+	return func(c *Client) error {
+		if charsetReader == nil {
+			return errors.New("eth: charset reader must not be nil")
+		}
+		c.charsetReader = charsetReader
+		return nil
+	}
+}
+
+// WithInputScale sets the unit of the raw prices returned by the ETH Gas Station API. It defaults to
+// InputScaleTenthsOfGwei, the unit documented by ETH Gas Station.
+//
+// Use InputScaleGwei if the endpoint in use returns prices already scaled to gwei, or InputScaleWei if it returns
+// prices in wei; a mismatched scale results in prices that are off by a power of ten. Prices are converted from the
+// decimal text of the response, so fractions of tenths of gwei are kept down to a single wei.
+func WithInputScale(scale InputScale) Option {
+	return func(c *Client) error {
+		if !scale.valid() {
+			return errors.New("eth: unknown/unsupported input scale")
+		}
+		c.inputScale = scale
+		return nil
+	}
+}
+
+// WithMaxResultAge enables caching on the client. Responses are reused until they are older than maxResultAge, after
+// which the next call loads a new response. The first response is loaded lazily on first use.
+//
+// A zero maxResultAge never reuses a response, every call loads a new one unless a stale window is configured with
+// WithMaxStaleness or WithAsyncRefresh. A negative maxResultAge is rejected.
+func WithMaxResultAge(maxResultAge time.Duration) Option {
+	return func(c *Client) error {
+		if maxResultAge < 0 {
+			return errors.New("eth: max result age must not be negative")
+		}
+		c.cache = &gasPriceManager{maxResultAge: maxResultAge}
+		return nil
+	}
+}
+
+// WithResultTTLJitter varies the max result age set with WithMaxResultAge by a random fraction of up to fraction in
+// either direction, such as 0.1 for ±10%, to spread the refreshes of a fleet of clients that would otherwise expire
+// their caches at the same moment. The jitter is drawn once when the client is created, so each client keeps a fixed
+// max result age. It never exceeds a max staleness set with WithMaxStaleness.
+//
+// It requires WithMaxResultAge, and fraction must be at least 0 and less than 1.
+func WithResultTTLJitter(fraction float64) Option {
+	return func(c *Client) error {
+		if !(fraction >= 0 && fraction < 1) {
+			return errors.New("eth: result ttl jitter must be at least 0 and less than 1")
+		}
+		c.ttlJitter = fraction
+		return nil
+	}
+}
+
+// WithResultTransform registers a function that is applied to prices loaded from the provider, before they are cached
+// or returned. Use it to apply a pricing policy, such as a fixed markup, consistently across all call sites.
+//
+// The transform receives freshly loaded prices that it may modify in place.
+func WithResultTransform(transform func(GasPrices) GasPrices) Option {
+	return func(c *Client) error {
+		c.transform = transform
+		return nil
+	}
+}
+
+// WithRetry retries failed requests up to retries times after the initial attempt. The client waits backoff before the
+// first retry, doubling the wait for each subsequent retry, use WithBackoff to wait differently.
+//
+// Requests that fail without a response are retried, as are responses with a status code that is retryable. By default
+// 429 Too Many Requests and all 5xx status codes are retryable, use WithRetryableStatus to change this. DNS errors are
+// retried if the resolver failed or timed out, as with SERVFAIL, including when returned by a custom provider, but not
+// if the host doesn't exist.
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(c *Client) error {
+		if retries < 0 || backoff < 0 {
+			return errors.New("eth: retries and backoff must not be negative")
+		}
+		c.retries = retries
+		c.retryBackoff = backoff
+		return nil
+	}
+}
+
+// WithRetryableStatus overrides which HTTP status codes are considered transient and retried when retries are enabled
+// with WithRetry. Use RetryableStatusCodes to retry a fixed set of status codes.
+func WithRetryableStatus(retryable func(status int) bool) Option {
+	return func(c *Client) error {
+		if retryable == nil {
+			return errors.New("eth: retryable status function must not be nil")
+		}
+		c.retryableStatus = retryable
+		return nil
+	}
+}
+
+// WithBackoff replaces the exponential backoff of WithRetry, such as with ConstantBackoff or a custom Backoff. The
+// backoff set with WithRetry is ignored, while its number of retries still applies.
+func WithBackoff(backoff Backoff) Option {
+	return func(c *Client) error {
+		if backoff == nil {
+			return errors.New("eth: backoff must not be nil")
+		}
+		c.backoff = backoff
+		return nil
+	}
+}
+
+// WithMaxRetryElapsed caps the total time spent loading prices with retries enabled by WithRetry, including the
+// requests and the backoff between them, so the latency of a call is bounded regardless of the number of retries. Once
+// the budget is spent, or the next backoff would exceed it, the last error is returned. If a call is made with a
+// context that has a sooner deadline, the deadline of the context is used instead.
+func WithMaxRetryElapsed(maxElapsed time.Duration) Option {
+	return func(c *Client) error {
+		if maxElapsed < 0 {
+			return errors.New("eth: max retry elapsed time must not be negative")
+		}
+		c.maxRetryElapsed = maxElapsed
+		return nil
+	}
+}
+
+// WithQuotaTracker counts each request to the provider that succeeds in tracker, whether it is the first attempt or
+// a retry, so its RequestCount can be compared to the quota of a metered API. Prices served from a cache are not
+// counted.
+func WithQuotaTracker(tracker *QuotaTracker) Option {
+	return func(c *Client) error {
+		if tracker == nil {
+			return errors.New("eth: quota tracker must not be nil")
+		}
+		c.quota = tracker
+		return nil
+	}
+}
+
+// WithMaxConcurrentFetches limits the requests to the provider that are in flight at once to n, retries included, to
+// protect the network and the provider when many calls load prices at the same time, such as with distinct providers
+// or without WithFetchDedupWindow. Further requests wait for one to finish, or until the context of their call is
+// done. Time spent waiting doesn't count towards the timeout set with WithTimeout.
+func WithMaxConcurrentFetches(n int) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			return errors.New("eth: max concurrent fetches must be positive")
+		}
+		c.fetchSlots = make(chan struct{}, n)
+		return nil
+	}
+}
+
+// WithErrorTiming sets the Attempt and Duration of each *FetchError returned by the provider to the number of the
+// attempt that failed and how long it took, to tell a connection that was refused at once from a request that timed
+// out, and adds them to the error message. Errors that are not a *FetchError are returned unchanged.
+func WithErrorTiming() Option {
+	return func(c *Client) error {
+		c.errorTiming = true
+		return nil
+	}
+}
+
+// WithClientTrace attaches trace to the context of each request to the provider, including retries, so the time spent
+// resolving DNS, connecting and in the TLS handshake can be observed to tell provider-side slowness from network-side
+// slowness. Prices served from a cache make no request and are not traced.
+func WithClientTrace(trace *httptrace.ClientTrace) Option {
+	return func(c *Client) error {
+		if trace == nil {
+			return errors.New("eth: client trace must not be nil")
+		}
+		c.trace = trace
+		return nil
+	}
+}
+
+// WithRequestLogger calls log with the method and URL of each request made by the default provider, with its API key
+// redacted by RedactURL, so debug logs can show which endpoint was hit without leaking the key. Wrap the transport of
+// a custom provider's HTTP client with LogRequests for the same effect.
+func WithRequestLogger(log func(method, redactedURL string)) Option {
+	return func(c *Client) error {
+		if log == nil {
+			return errors.New("eth: request logger must not be nil")
+		}
+		c.requestLog = func(_ context.Context, method, redactedURL string) {
+			log(method, redactedURL)
+		}
+		return nil
+	}
+}
+
+// WithRequestContextLogger is like WithRequestLogger, but also passes log the context of each request, which carries
+// the values of the context of the call that made it, such as a request ID or tenant, to correlate requests with the
+// calls that caused them. It replaces a logger set with WithRequestLogger.
+func WithRequestContextLogger(log func(ctx context.Context, method, redactedURL string)) Option {
+	return func(c *Client) error {
+		if log == nil {
+			return errors.New("eth: request logger must not be nil")
+		}
+		c.requestLog = log
+		return nil
+	}
+}
+
+// WithRequestIDGenerator sets the function generating the request ID of each fetch from the provider, which is shared
+// by all of its retries and returned by RequestIDFromContext for the context of its requests, and set on its events,
+// to correlate the log lines of a fetch such as the requests logged by WithRequestContextLogger. It defaults to a
+// counter of the fetches of the client, starting at "1". generate must be safe for concurrent use.
+func WithRequestIDGenerator(generate func() string) Option {
+	return func(c *Client) error {
+		if generate == nil {
+			return errors.New("eth: request ID generator must not be nil")
+		}
+		c.requestIDs = generate
+		return nil
+	}
+}
+
+// WithResponseHook calls hook with each response received by the default provider, including retries and responses
+// with an error status, to inspect headers such as X-RateLimit-Remaining, Date and Cache-Control. The hook is given a
+// copy without a body, as described for HookResponses, so it doesn't interfere with decoding the response. Wrap the
+// transport of a custom provider's HTTP client with HookResponses for the same effect.
+func WithResponseHook(hook func(*http.Response)) Option {
+	return func(c *Client) error {
+		if hook == nil {
+			return errors.New("eth: response hook must not be nil")
+		}
+		c.responseHook = hook
+		return nil
+	}
+}
+
+// WithStartupHealthCheck makes NewClient call Ping once the client is configured, and fail with its error if prices
+// can't be loaded, for deployments that should refuse to start rather than discover an unreachable provider on first
+// use. The check is bounded by timeout, unless it is zero, as well as by the timeout of each request set with
+// WithTimeout.
+func WithStartupHealthCheck(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return errors.New("eth: startup health check timeout must not be negative")
+		}
+		c.startupCheck = true
+		c.startupTimeout = timeout
+		return nil
+	}
+}
+
+// WithTimeout bounds each request made to the API by timeout. If a call is made with a context that has a sooner
+// deadline, the deadline of the context is used instead.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return errors.New("eth: timeout must not be negative")
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithRefreshTimeout bounds each request made by a background refresh by timeout instead of the timeout set with
+// WithTimeout, so a refresh can take longer than latency-sensitive calls, or be bounded even though calls aren't.
+// Background refreshes are the refreshes of a Refresher, including a warm start, and those made by a cache configured
+// with WithMaxStaleness or WithAsyncRefresh while it serves stale prices. It defaults to the timeout of WithTimeout.
+func WithRefreshTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return errors.New("eth: refresh timeout must be positive")
+		}
+		c.refreshTimeout = timeout
+		return nil
+	}
+}
+
+// WithRoundTo rounds every price to a multiple of gwei, after any transform registered with WithResultTransform. Prices
+// are rounded with the mode set by WithRoundingMode, and a positive price is never rounded down to zero.
+func WithRoundTo(gwei uint64) Option {
+	return func(c *Client) error {
+		if gwei == 0 {
+			return errors.New("eth: rounding grid must be positive")
+		}
+		c.roundTo = new(big.Int).Mul(new(big.Int).SetUint64(gwei), big.NewInt(1e9))
+		return nil
+	}
+}
+
+// WithRoundingMode sets how prices are rounded when rounding is enabled with WithRoundTo. It defaults to RoundNearest.
+func WithRoundingMode(mode RoundingMode) Option {
+	return func(c *Client) error {
+		if !mode.valid() {
+			return errors.New("eth: unknown/unsupported rounding mode")
+		}
+		c.roundingMode = mode
+		return nil
+	}
+}
+
+// WithFailFast makes every call on the client fail fast, as if made with a context from ContextWithFailFast. A single
+// attempt is made to load prices, bypassing the retries configured with WithRetry, any Retry middleware, and falling
+// back on other providers.
+func WithFailFast() Option {
+	return func(c *Client) error {
+		c.failFast = true
+		return nil
+	}
+}
+
+// WithPriorityPercentiles overrides the percentile the client maps each of the given priority levels to, for users who
+// define the levels differently. Priorities that are not included keep their canonical percentile.
+func WithPriorityPercentiles(percentiles map[GasPriority]float64) Option {
+	return func(c *Client) error {
+		overrides := make(map[GasPriority]float64, len(percentiles))
+		for priority, percentile := range percentiles {
+			if _, ok := defaultPercentiles[priority]; !ok {
+				return errors.New("eth: unknown/unsupported gas priority")
+			}
+			if !(percentile >= 0 && percentile <= 100) {
+				return errors.New("eth: percentile must be between 0 and 100")
+			}
+			overrides[priority] = percentile
+		}
+		c.percentiles = overrides
+		return nil
+	}
+}
+
+// WithDefaultPriority sets the priority used by Suggest, so the priority a deployment normally uses is part of its
+// configuration. It defaults to GasPriorityFast, and may be GasPriorityTurbo.
+func WithDefaultPriority(priority GasPriority) Option {
+	return func(c *Client) error {
+		if _, ok := defaultPercentiles[priority]; !ok && priority != GasPriorityTurbo {
+			return errors.New("eth: unknown/unsupported gas priority")
+		}
+		c.defaultPriority = priority
+		return nil
+	}
+}
+
+// WithMaxPriceChange rejects a refresh if any price moved more than percent from the last accepted prices, to guard
+// against a provider glitching and returning a wildly different price. Rejected refreshes return
+// ErrUnexpectedPriceChange and are not retried, and a caching client keeps its previous prices. Real gas prices move
+// fast, so the threshold should be generous, such as 200.
+//
+// Prices are compared as returned by the provider, before any result transform or rounding. The first refresh is
+// always accepted, since there is nothing to compare it to.
+func WithMaxPriceChange(percent float64) Option {
+	return func(c *Client) error {
+		if !(percent > 0) || math.IsInf(percent, 1) {
+			return errors.New("eth: max price change must be a positive percentage")
+		}
+		c.maxPriceChange = new(big.Rat).SetFloat64(percent)
+		return nil
+	}
+}
+
+// WithFetchDedupWindow coalesces fetches of a client that doesn't cache, so concurrent calls share a single request to
+// the provider, and calls made within window of a successful request completing share its result. Failed requests are
+// only shared with calls that were waiting for them. A zero window only coalesces concurrent calls.
+//
+// Fetches are coalesced per client, clients with the same provider don't share requests, use WithProcessCache for that.
+// A shared request is bound to the context of the call that started it.
+func WithFetchDedupWindow(window time.Duration) Option {
+	return func(c *Client) error {
+		if window < 0 {
+			return errors.New("eth: dedup window must not be negative")
+		}
+		c.dedup = &fetchGroup{window: window}
+		return nil
+	}
+}
+
+// WithRejectZeroPrices rejects responses that report a zero price for any priority level, which usually indicates a
+// malfunctioning provider. Negative prices are always rejected.
+func WithRejectZeroPrices() Option {
+	return func(c *Client) error {
+		c.rejectZero = true
+		return nil
+	}
+}
+
+// WithRejectInvertedWaitTimes rejects responses whose wait times don't decrease from safeLow to fastest, as checked by
+// ValidateWaitTimes, with an error wrapping ErrInvertedWaitTimes. Like other invalid prices, a caching client keeps its
+// previous prices, and WithRetryInvalidPrices retries them.
+func WithRejectInvertedWaitTimes() Option {
+	return func(c *Client) error {
+		c.rejectInvertedWaits = true
+		return nil
+	}
+}
+
+// WithRetryInvalidPrices treats a response that is rejected for invalid prices, such as negative prices, zero prices
+// with WithRejectZeroPrices or implausible prices with WithRejectImplausiblePrices, as a failed request that is retried
+// with WithRetry, on the assumption that the provider returned transient garbage. Without retries, or once they are
+// exhausted, the rejection is returned as a *FetchError. Prices rejected by WithMaxPriceChange are not retried.
+//
+// To fall back on another provider when one returns invalid prices, wrap each provider with the Validate middleware.
+func WithRetryInvalidPrices() Option {
+	return func(c *Client) error {
+		c.retryInvalid = true
+		return nil
+	}
+}
+
+// WithUnitCheck calls warn with an *ImplausiblePriceError for each price of a response outside the plausible range,
+// between 0.1 and 10000 gwei unless configured otherwise with WithPlausibleRange. A price far outside the range usually
+// means the unit of the provider is misconfigured, such as a mirror serving gwei or wei rather than tenths of gwei, and
+// the error tells the power of ten it is likely off by. The prices are still served, use WithRejectImplausiblePrices to
+// reject them.
+//
+// Prices are checked as returned by the provider, before any result transform or rounding. warn is called while the
+// prices are loaded, and must not block.
+func WithUnitCheck(warn func(err error)) Option {
+	return func(c *Client) error {
+		if warn == nil {
+			return errors.New("eth: unit check warning must not be nil")
+		}
+		c.unitWarning = warn
+		return nil
+	}
+}
+
+// WithWarningLogger calls warn with warnings about the configuration of the client, such as the default provider using
+// the free ETH Gas Station endpoint, which is deprecated and unreliable. Each warning is logged once per process rather
+// than for each client. Clients without a warning logger log nothing.
+func WithWarningLogger(warn func(msg string)) Option {
+	return func(c *Client) error {
+		if warn == nil {
+			return errors.New("eth: warning logger must not be nil")
+		}
+		c.warn = warn
+		return nil
+	}
+}
+
+// WithCustomConfirmationModel replaces the wait times reported by the provider with the estimates of model, for callers
+// with their own measurements of confirmation times on their chain. It is used by EstimateWait and the wait-based
+// selectors of the client: PriceForMaxWait, PriorityForTargetWait, FeeParamsForWait, GasPriceOptions,
+// SuggestGasPriceDetails, SuggestFees, NewFeeSuggester, GasEstimates and NewGasEstimator. Other calls, and the cached
+// prices, keep the reported wait times. By default wait times are interpolated as described for GasPrices.EstimateWait.
+func WithCustomConfirmationModel(model ConfirmationModel) Option {
+	return func(c *Client) error {
+		if model == nil {
+			return errors.New("eth: confirmation model must not be nil")
+		}
+		c.confirmationModel = model
+		return nil
+	}
+}
+
+// WithRejectImplausiblePrices rejects responses with a price outside the plausible range, as checked by WithUnitCheck,
+// with an *ImplausiblePriceError. Rejected responses are not retried, and a caching client keeps its previous prices.
+func WithRejectImplausiblePrices() Option {
+	return func(c *Client) error {
+		c.rejectImplausible = true
+		return nil
+	}
+}
+
+// WithPlausibleRange replaces the range of prices in gwei considered plausible by WithUnitCheck and
+// WithRejectImplausiblePrices, for chains whose prices are usually outside the default range. It requires one of them.
+func WithPlausibleRange(minGwei, maxGwei float64) Option {
+	return func(c *Client) error {
+		if !(minGwei > 0 && minGwei < maxGwei) || math.IsInf(maxGwei, 1) {
+			return errors.New("eth: plausible range must be positive and finite, with min below max")
+		}
+		c.plausibleRange = []float64{minGwei, maxGwei}
+		return nil
+	}
+}
+
+// WithStateSerializer replaces JSON as the format of the state written by ExportState and read by ImportState. State
+// written by another serializer is rejected by ImportState, see Serializer.
+func WithStateSerializer(serializer Serializer) Option {
+	return func(c *Client) error {
+		if serializer == nil {
+			return errors.New("eth: state serializer must not be nil")
+		}
+		c.stateSerializer = serializer
+		return nil
+	}
+}
+
+// WithNowFunc replaces the clock used to determine the age of cached prices, so tests can move a caching client
+// between fresh, stale and expired prices without waiting. It defaults to time.Now.
+func WithNowFunc(now func() time.Time) Option {
+	return func(c *Client) error {
+		if now == nil {
+			return errors.New("eth: now function must not be nil")
+		}
+		c.now = now
+		return nil
+	}
+}
+
+// WithMaxStaleness sets a hard limit on the age of cached prices, for a client configured with WithMaxResultAge. Prices
+// older than the max result age but within maxStaleness are served immediately while new prices are fetched in the
+// background, and a failed background refresh keeps serving them. Prices older than maxStaleness are never served, and
+// calls wait for new prices or fail.
+//
+// maxStaleness must not be less than the max result age.
+func WithMaxStaleness(maxStaleness time.Duration) Option {
+	return func(c *Client) error {
+		if maxStaleness < 0 {
+			return errors.New("eth: max staleness must not be negative")
+		}
+		c.maxStaleness = maxStaleness
+		return nil
+	}
+}
+
+// WithMaxServeAge sets an absolute limit on the age of the prices served by the client, as a guard against serving old
+// prices when refreshing them keeps failing. Unlike WithMaxStaleness, reads never wait for new prices because of it:
+// cached prices older than maxServeAge fail with an error wrapping ErrValueTooOld while they are refreshed in the
+// background, whether they are served stale, by SuggestGasPriceOrStale or by a Refresher of the client.
+//
+// maxServeAge must be positive, and not less than the max result age of a caching client.
+func WithMaxServeAge(maxServeAge time.Duration) Option {
+	return func(c *Client) error {
+		if maxServeAge <= 0 {
+			return errors.New("eth: max serve age must be positive")
+		}
+		c.maxServeAge = maxServeAge
+		return nil
+	}
+}
+
+// WithAsyncRefresh makes a caching client always serve cached prices immediately, for read-heavy callers that need the
+// lowest latency. Once the prices are older than the max result age, the next call starts refreshing them in the
+// background and still returns the old prices, with at most one refresh running at a time. Only the first call waits
+// for prices to be loaded.
+//
+// It requires WithMaxResultAge, and is equivalent to WithMaxStaleness without a limit.
+func WithAsyncRefresh() Option {
+	return func(c *Client) error {
+		c.asyncRefresh = true
+		return nil
+	}
+}
+
+// WithEvents makes the client emit events on the channel returned by Client.Events as it loads prices, with a buffer
+// of size events. When the buffer is full, the oldest event is dropped.
+func WithEvents(size int) Option {
+	return func(c *Client) error {
+		if size <= 0 {
+			return errors.New("eth: event buffer size must be positive")
+		}
+		c.events = make(chan Event, size)
+		return nil
+	}
+}
+
+// WithResultComparator replaces how a caching client tells whether refreshed prices are unchanged, in which case the
+// refresh keeps the previous snapshot returned by CachedPrices rather than replacing it with identical prices, and only
+// makes the cached prices fresh again. By default the prices are unchanged if the price, base fee and EIP-1559 fees of
+// every priority level are equal, use equal to also compare other fields or ignore insignificant changes.
+//
+// It requires WithMaxResultAge.
+func WithResultComparator(equal func(previous, next GasPrices) bool) Option {
+	return func(c *Client) error {
+		if equal == nil {
+			return errors.New("eth: result comparator must not be nil")
+		}
+		c.resultComparator = equal
+		return nil
+	}
+}
+
+// WithLastResort sets a function that computes prices as a last resort, such as from the base fee of the latest block
+// of the caller's own node, when loading prices fails and no cached prices can be served, to keep transactions flowing
+// during an outage of every provider. It is only called once the provider, including any retries and fallback
+// providers, has failed and the cache has no fresh or stale prices left.
+//
+// The prices it returns are validated and transformed like those of the provider, but are not cached, so the next call
+// tries the provider again. If it fails too, the error of the provider is returned.
+func WithLastResort(compute func(ctx context.Context) (GasPrices, error)) Option {
+	return func(c *Client) error {
+		if compute == nil {
+			return errors.New("eth: last resort must not be nil")
+		}
+		c.lastResort = compute
+		return nil
+	}
+}
+
+// WithProviderSwapGrace keeps serving the prices last loaded from the previous provider for grace after SetProvider
+// replaces it, if loading prices from the new provider fails, so a provider that is still warming up or fails its
+// first requests doesn't cause failed calls during a live migration. The grace period ends early once a fetch from the
+// new provider succeeds. It is tried before the function set with WithLastResort.
+//
+// The prices served are those returned by LastFetch when the provider was replaced, they are not cached or recorded
+// as a fetch, and are not served if no fetch had succeeded yet.
+func WithProviderSwapGrace(grace time.Duration) Option {
+	return func(c *Client) error {
+		if grace <= 0 {
+			return errors.New("eth: provider swap grace must be positive")
+		}
+		c.swapGrace = grace
+		return nil
+	}
+}
+
+// WithBlockTimeCap caches prices for at most the block time reported with them in GasPrices.BlockTime, when it is
+// shorter than the max result age, since prices older than a block are stale. Rapid reads within a block are still
+// served from the cache. Providers that don't report a block time are cached for the max result age, set the BlockTime
+// of a MempoolProvider to the block time of its chain.
+//
+// It requires WithMaxResultAge, and takes precedence over a longer max result age. A stale window configured with
+// WithMaxStaleness or WithAsyncRefresh starts once the prices are older than the block time.
+func WithBlockTimeCap() Option {
+	return func(c *Client) error {
+		c.blockTimeCap = true
+		return nil
+	}
+}
+
+// WithErrorCache remembers a failure to load prices for ttl, and returns the same error immediately to calls made
+// within that window instead of calling the provider again. It bounds the load on a failing provider, and the latency
+// of calls during an outage, as a lighter alternative to the CircuitBreaker middleware.
+//
+// Failures caused by the context of a call, such as its deadline passing, are not remembered.
+func WithErrorCache(ttl time.Duration) Option {
+	return func(c *Client) error {
+		if ttl <= 0 {
+			return errors.New("eth: error cache ttl must be positive")
+		}
+		c.errCache = &errorCache{ttl: ttl}
+		return nil
+	}
+}
+
+// WithProcessCache shares the prices the client loads with the other clients in the process that were configured
+// with it and target the same provider, so identically configured clients don't each call the API. Clients of the
+// default provider share prices if they use the same endpoint, API key or keys, input scale, envelope path and field
+// mapping, and clients configured with WithProvider if they were given the same pointer. Each client serves shared
+// prices that are younger than maxAge, and applies its own conversions, validation and caching to them.
+//
+// Sharing is opt-in, clients without this option neither read nor populate the process cache.
+func WithProcessCache(maxAge time.Duration) Option {
+	return func(c *Client) error {
+		if maxAge <= 0 {
+			return errors.New("eth: process cache max age must be positive")
+		}
+		c.processCacheAge = maxAge
+		return nil
+	}
+}
+
+// WithPerPriorityCache caches the price of each priority level separately, with its own age, if the provider is a
+// PriorityProvider. Requesting the price of one priority level then only loads that level, which saves quota when
+// levels are requested unevenly. It requires WithMaxResultAge, which sets the maximum age of each level.
+//
+// Only SuggestGasPrice and SuggestGasPriceContext use the per-priority cache, other methods use the whole-response
+// cache, and every other provider only uses the whole-response cache. Transforms registered with WithResultTransform
+// receive prices with only the requested level set.
+func WithPerPriorityCache() Option {
+	return func(c *Client) error {
+		c.perPriority = true
+		return nil
+	}
+}
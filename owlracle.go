@@ -0,0 +1,146 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OwlracleURL is the Owlracle gas API endpoint used by the OwlracleProvider, followed by the network and "/gas".
+const OwlracleURL = "https://api.owlracle.info/v4/"
+
+// OwlracleProvider is a Provider that loads prices from the Owlracle gas API, which serves several chains and requires
+// an API key. Each priority level is requested as the percentage of recent blocks a transaction at its price would
+// have been accepted in, the percentile returned by PriorityToPercentile unless overridden, and the EIP-1559 fees and
+// base fee are populated along with the legacy gas prices.
+//
+// The legacy gas price of each priority level is its max fee.
+type OwlracleProvider struct {
+	// Network is the Owlracle name of the chain, such as "eth", "poly" or "bsc". It defaults to "eth".
+	Network string
+
+	// APIKey is sent as the apikey query parameter.
+	APIKey string
+
+	// Percentiles overrides the percentage of accepting blocks, between 0 and 100, that priority levels are served from.
+	Percentiles map[GasPriority]float64
+
+	// HTTPClient is used to make requests, it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type owlracleResponse struct {
+	Timestamp time.Time `json:"timestamp"`
+	BaseFee   float64   `json:"baseFee"`
+	Speeds    []struct {
+		Acceptance           float64 `json:"acceptance"`
+		MaxFeePerGas         float64 `json:"maxFeePerGas"`
+		MaxPriorityFeePerGas float64 `json:"maxPriorityFeePerGas"`
+	} `json:"speeds"`
+}
+
+// Fetch loads the latest prices from the Owlracle API.
+func (p *OwlracleProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url(), nil)
+	if err != nil {
+		return GasPrices{}, err
+	}
+
+	res, err := p.client().Do(req)
+	if err != nil {
+		return GasPrices{}, &FetchError{Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return GasPrices{}, &FetchError{StatusCode: res.StatusCode}
+	}
+
+	var response owlracleResponse
+	if err := decodeResponse(res.Body, &response); err != nil {
+		return GasPrices{}, err
+	}
+	return newOwlracleGasPrices(response)
+}
+
+// CloseIdleConnections closes idle connections of the underlying HTTP client.
+func (p *OwlracleProvider) CloseIdleConnections() {
+	p.client().CloseIdleConnections()
+}
+
+func (p *OwlracleProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
+
+// validateKey checks the key of the provider, which is required
+func (p *OwlracleProvider) validateKey() error {
+	return ValidateKey(p.APIKey)
+}
+
+// url requests one speed per priority level, in the order of priorityOrder
+func (p *OwlracleProvider) url() string {
+	network := p.Network
+	if network == "" {
+		network = "eth"
+	}
+
+	accept := make([]string, len(priorityOrder))
+	for i, priority := range priorityOrder {
+		percentile, ok := p.Percentiles[priority]
+		if !ok {
+			percentile = PriorityToPercentile(priority)
+		}
+		accept[i] = strconv.FormatFloat(percentile, 'f', -1, 64)
+	}
+
+	query := url.Values{}
+	query.Set("apikey", p.APIKey)
+	query.Set("accept", strings.Join(accept, ","))
+	return OwlracleURL + url.PathEscape(network) + "/gas?" + query.Encode()
+}
+
+// newOwlracleGasPrices converts the speeds in the response, which are in the order they were requested in, to wei
+func newOwlracleGasPrices(response owlracleResponse) (GasPrices, error) {
+	if len(response.Speeds) != len(priorityOrder) {
+		return GasPrices{}, errors.New("eth: unexpected number of speeds in response")
+	}
+
+	baseFee, err := parseGweiToWei(response.BaseFee)
+	if err != nil {
+		return GasPrices{}, err
+	}
+	result := GasPrices{
+		UpdatedAt:  response.Timestamp,
+		BaseFee:    baseFee,
+		Confidence: make(map[GasPriority]float64, len(priorityOrder)),
+		Fees:       make(map[GasPriority]FeeSuggestion, len(priorityOrder)),
+	}
+
+	for i, priority := range priorityOrder {
+		speed := response.Speeds[i]
+		maxFee, err := parseGweiToWei(speed.MaxFeePerGas)
+		if err != nil {
+			return GasPrices{}, err
+		}
+		maxPriorityFee, err := parseGweiToWei(speed.MaxPriorityFeePerGas)
+		if err != nil {
+			return GasPrices{}, err
+		}
+
+		result, err = result.withPrice(priority, maxFee)
+		if err != nil {
+			return GasPrices{}, err
+		}
+		result.Confidence[priority] = speed.Acceptance
+		result.Fees[priority] = FeeSuggestion{MaxFeePerGas: new(big.Int).Set(maxFee), MaxPriorityFeePerGas: maxPriorityFee}
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package gas
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOwlracleResponse = `{
+	"timestamp": "2022-06-23T13:58:28.799Z",
+	"lastBlock": 15009197,
+	"avgTime": 13.5,
+	"baseFee": 37.250000000456,
+	"speeds": [
+		{"acceptance": 0.35, "maxFeePerGas": 38.25, "maxPriorityFeePerGas": 1, "baseFee": 37.25, "estimatedFee": 2.2},
+		{"acceptance": 0.6, "maxFeePerGas": 38.75, "maxPriorityFeePerGas": 1.5, "baseFee": 37.25, "estimatedFee": 2.3},
+		{"acceptance": 0.9, "maxFeePerGas": 39.25, "maxPriorityFeePerGas": 2, "baseFee": 37.25, "estimatedFee": 2.4},
+		{"acceptance": 0.95, "maxFeePerGas": 40.25, "maxPriorityFeePerGas": 3, "baseFee": 37.25, "estimatedFee": 2.5}
+	]
+}`
+
+func TestOwlracleProvider(t *testing.T) {
+	requests := make(chan *url.URL, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.URL
+		_, _ = w.Write([]byte(testOwlracleResponse))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	provider := &OwlracleProvider{
+		Network:    "poly",
+		APIKey:     "test-key",
+		HTTPClient: &http.Client{Transport: testTransport{server: serverURL}},
+	}
+
+	// 1. the network, key and percentiles of the priority levels are requested
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	requested := <-requests
+	assert.Equal(t, "/v4/poly/gas", requested.Path)
+	assert.Equal(t, "test-key", requested.Query().Get("apikey"))
+	assert.Equal(t, "35,60,90,95", requested.Query().Get("accept"))
+
+	// 2. the speeds are mapped onto priority levels
+	assert.Equal(t, "38250000000", prices.SafeLow.String())
+	assert.Equal(t, "38750000000", prices.Average.String())
+	assert.Equal(t, "39250000000", prices.Fast.String())
+	assert.Equal(t, "40250000000", prices.Fastest.String())
+	assert.Equal(t, 0.9, prices.PriceConfidence(GasPriorityFast))
+	assert.True(t, prices.UpdatedAt.Equal(time.Date(2022, time.June, 23, 13, 58, 28, 799e6, time.UTC)))
+
+	// 3. EIP-1559 fees are populated, rounded to the nearest wei
+	assert.Equal(t, "37250000000", prices.BaseFee.String())
+	assert.Equal(t, "39250000000", prices.Fees[GasPriorityFast].MaxFeePerGas.String())
+	assert.Equal(t, "2000000000", prices.Fees[GasPriorityFast].MaxPriorityFeePerGas.String())
+
+	// 4. the percentiles are configurable
+	provider.Percentiles = map[GasPriority]float64{GasPriorityFastest: 99.5}
+	_, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "35,60,90,99.5", (<-requests).Query().Get("accept"))
+}
+
+func TestOwlracleProviderErrors(t *testing.T) {
+	// 1. a response without a speed for each priority level is rejected
+	_, err := newOwlracleGasPrices(owlracleResponse{})
+	assert.Error(t, err)
+
+	// 2. the api key is required
+	_, err = NewClient(WithProvider(&OwlracleProvider{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// defaultPercentiles is the canonical percentile of recent gas prices for each priority level
+var defaultPercentiles = map[GasPriority]float64{
+	GasPrioritySafeLow: 35,
+	GasPriorityAverage: 60,
+	GasPriorityFast:    90,
+	GasPriorityFastest: 95,
+}
+
+// PercentileProvider is implemented by providers that can serve any percentile of recent gas prices, not only the
+// percentiles of the priority levels. It is used by SuggestGasPriceAtPercentile.
+type PercentileProvider interface {
+	Provider
+
+	// FetchPercentile loads the given percentile, between 0 and 100, of recent gas prices in wei.
+	FetchPercentile(ctx context.Context, percentile float64) (*big.Int, error)
+}
+
+// PriorityToPercentile returns the canonical percentile, between 0 and 100, of recent gas prices that a priority level
+// corresponds to, so providers that compute percentiles can serve fixed priority levels uniformly. It returns NaN for
+// an unknown priority.
+//
+// The percentiles are 35 for safeLow, 60 for average, 90 for fast and 95 for fastest.
+func PriorityToPercentile(priority GasPriority) float64 {
+	percentile, ok := defaultPercentiles[priority]
+	if !ok {
+		return math.NaN()
+	}
+	return percentile
+}
+
+// PriorityToPercentile is like the package-level PriorityToPercentile, but uses the percentiles configured with
+// WithPriorityPercentiles.
+func (c *Client) PriorityToPercentile(priority GasPriority) float64 {
+	if percentile, ok := c.percentiles[priority]; ok {
+		return percentile
+	}
+	return PriorityToPercentile(priority)
+}
+
+// SuggestGasPriceAtPercentile returns a suggested gas price in wei at the given percentile, between 0 and 100, of
+// recent gas prices, for transactions whose urgency doesn't fit a priority level. The provider must implement
+// PercentileProvider.
+//
+// The price is loaded like that of a priority level: it is retried, validated, transformed and rounded as configured
+// on the client, and a caching client caches the price of each percentile for the same maximum age. WithMaxPriceChange
+// compares it to the priority level with the nearest percentile.
+func (c *Client) SuggestGasPriceAtPercentile(ctx context.Context, percentile float64) (*big.Int, error) {
+	if !(percentile >= 0 && percentile <= 100) {
+		return nil, errors.New("eth: percentile must be between 0 and 100")
+	}
+	provider, ok := c.source().(PercentileProvider)
+	if !ok {
+		return nil, errors.New("eth: provider does not support percentiles")
+	}
+
+	level := c.nearestPriority(percentile)
+	fetch := func(ctx context.Context) (*big.Int, error) {
+		prices, err := c.fetchWith(ctx, func(ctx context.Context) (GasPrices, error) {
+			return c.retry(ctx, func(ctx context.Context) (GasPrices, error) {
+				price, err := provider.FetchPercentile(ctx, percentile)
+				if err != nil {
+					return GasPrices{}, err
+				}
+				return GasPrices{}.withPrice(level, price)
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return prices.price(level)
+	}
+
+	var price *big.Int
+	var err error
+	if c.percentileCache != nil {
+		price, err = c.percentileCache.latest(ctx, strconv.FormatFloat(percentile, 'g', -1, 64), fetch)
+	} else {
+		price, err = fetch(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(price), nil
+}
+
+// SuggestGasPriceWithPercentiles is like SuggestGasPriceAtPercentile, but takes a priority level and maps it to its
+// percentile in percentiles for this call only. A priority that is not in percentiles keeps the percentile returned by
+// the client's PriorityToPercentile.
+func (c *Client) SuggestGasPriceWithPercentiles(
+	ctx context.Context,
+	priority GasPriority,
+	percentiles map[GasPriority]float64,
+) (*big.Int, error) {
+	if _, ok := defaultPercentiles[priority]; !ok {
+		return nil, errors.New("eth: unknown/unsupported gas priority")
+	}
+	percentile, ok := percentiles[priority]
+	if !ok {
+		percentile = c.PriorityToPercentile(priority)
+	}
+	return c.SuggestGasPriceAtPercentile(ctx, percentile)
+}
+
+// nearestPriority returns the priority level whose percentile is nearest to percentile
+func (c *Client) nearestPriority(percentile float64) GasPriority {
+	nearest := priorityOrder[0]
+	for _, priority := range priorityOrder[1:] {
+		if math.Abs(c.PriorityToPercentile(priority)-percentile) <
+			math.Abs(c.PriorityToPercentile(nearest)-percentile) {
+			nearest = priority
+		}
+	}
+	return nearest
+}
@@ -0,0 +1,99 @@
+package gas
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityToPercentile(t *testing.T) {
+	// 1. each priority maps to its canonical percentile
+	assert.Equal(t, 35.0, PriorityToPercentile(GasPrioritySafeLow))
+	assert.Equal(t, 60.0, PriorityToPercentile(GasPriorityAverage))
+	assert.Equal(t, 90.0, PriorityToPercentile(GasPriorityFast))
+	assert.Equal(t, 95.0, PriorityToPercentile(GasPriorityFastest))
+
+	// 2. unknown priorities are NaN
+	assert.True(t, math.IsNaN(PriorityToPercentile(GasPriority("slow"))))
+}
+
+func TestWithPriorityPercentiles(t *testing.T) {
+	// 1. configured percentiles override the canonical ones
+	c, err := NewClient(WithPriorityPercentiles(map[GasPriority]float64{GasPriorityFast: 80}))
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, c.PriorityToPercentile(GasPriorityFast))
+	assert.Equal(t, 95.0, c.PriorityToPercentile(GasPriorityFastest))
+
+	// 2. invalid priorities and percentiles are rejected
+	_, err = NewClient(WithPriorityPercentiles(map[GasPriority]float64{GasPriority("slow"): 10}))
+	assert.Error(t, err)
+	_, err = NewClient(WithPriorityPercentiles(map[GasPriority]float64{GasPriorityFast: 101}))
+	assert.Error(t, err)
+	_, err = NewClient(WithPriorityPercentiles(map[GasPriority]float64{GasPriorityFast: math.NaN()}))
+	assert.Error(t, err)
+}
+
+func TestSuggestGasPriceAtPercentile(t *testing.T) {
+	var requests int32
+	handler := serveTxpool(t, "txpool_content", 7, 3, 10, 1, 5, 2, 9, 4, 8, 6)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		handler(w, r)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(
+		WithProvider(&MempoolProvider{URL: server.URL}),
+		WithMaxResultAge(time.Minute),
+		WithRoundTo(3),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// 1. any percentile is served, with the client's configuration applied
+	price, err := c.SuggestGasPriceAtPercentile(ctx, 70)
+	require.NoError(t, err)
+	assert.Equal(t, "6000000000", price.String())
+
+	// 2. each percentile is cached on its own
+	_, err = c.SuggestGasPriceAtPercentile(ctx, 70)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	price, err = c.SuggestGasPriceAtPercentile(ctx, 20)
+	require.NoError(t, err)
+	assert.Equal(t, "3000000000", price.String())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// 3. a priority level can be mapped to another percentile for a single call
+	price, err = c.SuggestGasPriceWithPercentiles(ctx, GasPriorityFast, map[GasPriority]float64{GasPriorityFast: 50})
+	require.NoError(t, err)
+	assert.Equal(t, "6000000000", price.String())
+	price, err = c.SuggestGasPriceWithPercentiles(ctx, GasPriorityFastest, map[GasPriority]float64{GasPriorityFast: 50})
+	require.NoError(t, err)
+	assert.Equal(t, "9000000000", price.String())
+	_, err = c.SuggestGasPriceWithPercentiles(ctx, GasPriority("slow"), map[GasPriority]float64{"slow": 50})
+	assert.Error(t, err)
+
+	// 4. invalid percentiles are rejected without a request
+	requestsBefore := atomic.LoadInt32(&requests)
+	_, err = c.SuggestGasPriceAtPercentile(ctx, math.NaN())
+	assert.Error(t, err)
+	_, err = c.SuggestGasPriceAtPercentile(ctx, -1)
+	assert.Error(t, err)
+	assert.Equal(t, requestsBefore, atomic.LoadInt32(&requests))
+
+	// 5. providers that don't serve percentiles are an error
+	var calls int32
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &calls)))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPriceAtPercentile(ctx, 50)
+	assert.EqualError(t, err, "eth: provider does not support percentiles")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,73 @@
+package gas
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const (
+	// DefaultPlausibleMinGwei and DefaultPlausibleMaxGwei bound the prices considered plausible by WithUnitCheck and
+	// WithRejectImplausiblePrices, unless configured otherwise with WithPlausibleRange.
+	DefaultPlausibleMinGwei = 0.1
+	DefaultPlausibleMaxGwei = 10000
+)
+
+// ImplausiblePriceError reports a price outside the plausible range of a client configured with WithUnitCheck or
+// WithRejectImplausiblePrices, which usually means the unit of the provider is misconfigured.
+type ImplausiblePriceError struct {
+	Priority GasPriority
+
+	// Price is the implausible price in wei.
+	Price *big.Int
+
+	// Exponent is the power of ten the price is likely off by, the one that brings it closest to the middle of the
+	// plausible range, such as 9 for prices in wei decoded as gwei, or -1 for prices in gwei decoded as tenths of gwei.
+	Exponent int
+}
+
+func (e *ImplausiblePriceError) Error() string {
+	return fmt.Sprintf("eth: %s price of %s wei is implausible, it may be off by a factor of 1e%d, check the input scale",
+		e.Priority, e.Price, e.Exponent)
+}
+
+// checkPlausible reports the prices of priority levels that are outside the plausible range to the unit warning, and
+// returns the first of them if implausible prices are rejected. Missing and zero prices are not checked.
+func (c *Client) checkPlausible(prices GasPrices) error {
+	if c.plausibleMin == nil {
+		return nil
+	}
+
+	var rejected error
+	for _, priority := range priorityOrder {
+		price, err := prices.price(priority)
+		if err != nil || price.Sign() == 0 {
+			continue
+		}
+		if price.Cmp(c.plausibleMin) >= 0 && price.Cmp(c.plausibleMax) <= 0 {
+			continue
+		}
+
+		err = &ImplausiblePriceError{
+			Priority: priority,
+			Price:    new(big.Int).Set(price),
+			Exponent: implausibleExponent(price, c.plausibleMin, c.plausibleMax),
+		}
+		if c.unitWarning != nil {
+			c.unitWarning(err)
+		}
+		if c.rejectImplausible && rejected == nil {
+			rejected = err
+		}
+	}
+	return rejected
+}
+
+// implausibleExponent returns the power of ten that brings price closest to the geometric middle of the range
+func implausibleExponent(price, min, max *big.Int) int {
+	log10 := func(x *big.Int) float64 {
+		f, _ := new(big.Float).SetInt(x).Float64()
+		return math.Log10(f)
+	}
+	return int(math.Round(log10(price) - (log10(min)+log10(max))/2))
+}
@@ -0,0 +1,85 @@
+package gas
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testWeiResponse holds prices in wei, which are implausible when decoded as tenths of gwei
+const testWeiResponse = `{"fast": 20000000000, "fastest": 25000000000, "safeLow": 10000000000, "average": 15000000000}`
+
+func serveTestWeiResponse(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte(testWeiResponse))
+}
+
+func TestWithUnitCheck(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		warnings []error
+	)
+	warn := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, err)
+	}
+
+	// 1. plausible prices are not reported
+	c, stop := newTestClient(t, serveTestResponse, WithUnitCheck(warn))
+	defer stop()
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// 2. implausible prices are reported with the power of ten they are off by, and still served
+	c, stop = newTestClient(t, serveTestWeiResponse, WithUnitCheck(warn))
+	defer stop()
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "2000000000000000000", price.String())
+	mu.Lock()
+	require.Len(t, warnings, 4)
+	var implausible *ImplausiblePriceError
+	require.True(t, errors.As(warnings[0], &implausible))
+	assert.Equal(t, GasPrioritySafeLow, implausible.Priority)
+	assert.Equal(t, 8, implausible.Exponent)
+	mu.Unlock()
+
+	// 3. a custom range changes which prices are plausible
+	warnings = nil
+	c, stop = newTestClient(t, serveTestResponse, WithUnitCheck(warn), WithPlausibleRange(21, 100))
+	defer stop()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Len(t, warnings, 3)
+
+	// 4. invalid options are rejected
+	_, err = NewClient(WithUnitCheck(nil))
+	assert.Error(t, err)
+	_, err = NewClient(WithPlausibleRange(1, 100))
+	assert.Error(t, err, "a range requires a check")
+	for _, bounds := range [][2]float64{{0, 1}, {10, 1}, {1, 1}} {
+		_, err = NewClient(WithRejectImplausiblePrices(), WithPlausibleRange(bounds[0], bounds[1]))
+		assert.Error(t, err, bounds)
+	}
+}
+
+func TestWithRejectImplausiblePrices(t *testing.T) {
+	// 1. implausible prices are rejected
+	c, stop := newTestClient(t, serveTestWeiResponse, WithRejectImplausiblePrices())
+	defer stop()
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	var implausible *ImplausiblePriceError
+	require.True(t, errors.As(err, &implausible))
+	assert.Equal(t, "1000000000000000000", implausible.Price.String())
+
+	// 2. plausible prices are accepted
+	c, stop = newTestClient(t, serveTestResponse, WithRejectImplausiblePrices())
+	defer stop()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,122 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+)
+
+// PolygonGasStationURL is the Polygon gas station endpoint used by the PolygonGasStationProvider.
+const PolygonGasStationURL = "https://gasstation.polygon.technology/v2"
+
+// PolygonGasStationProvider is a Provider that loads prices for the Polygon PoS chain from the Polygon gas station, and
+// the zero value is ready to use. The gas station reports EIP-1559 fees for three levels, safeLow, standard and fast,
+// which are served as safeLow, average and fast. The fast level is also served as fastest.
+//
+// The legacy gas price of each priority level is its max fee, and the EIP-1559 fees and base fee are populated along
+// with it.
+type PolygonGasStationProvider struct {
+	// URL replaces the Polygon gas station endpoint, e.g. to use the endpoint of the Amoy testnet.
+	URL string
+
+	// HTTPClient is used to make requests, it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type polygonFees struct {
+	MaxPriorityFee float64 `json:"maxPriorityFee"`
+	MaxFee         float64 `json:"maxFee"`
+}
+
+type polygonGasStationResponse struct {
+	SafeLow          polygonFees `json:"safeLow"`
+	Standard         polygonFees `json:"standard"`
+	Fast             polygonFees `json:"fast"`
+	EstimatedBaseFee float64     `json:"estimatedBaseFee"`
+
+	// BlockTime is the average block time in seconds
+	BlockTime float64 `json:"blockTime"`
+}
+
+// Fetch loads the latest prices from the Polygon gas station.
+func (p *PolygonGasStationProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	endpoint := p.URL
+	if endpoint == "" {
+		endpoint = PolygonGasStationURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return GasPrices{}, err
+	}
+
+	res, err := p.client().Do(req)
+	if err != nil {
+		return GasPrices{}, &FetchError{Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return GasPrices{}, &FetchError{StatusCode: res.StatusCode}
+	}
+
+	var response polygonGasStationResponse
+	if err := decodeResponse(res.Body, &response); err != nil {
+		return GasPrices{}, err
+	}
+	return newPolygonGasPrices(response)
+}
+
+// CloseIdleConnections closes idle connections of the underlying HTTP client.
+func (p *PolygonGasStationProvider) CloseIdleConnections() {
+	p.client().CloseIdleConnections()
+}
+
+func (p *PolygonGasStationProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
+
+// newPolygonGasPrices converts the fees in the response to wei
+func newPolygonGasPrices(response polygonGasStationResponse) (GasPrices, error) {
+	baseFee, err := parseGweiToWei(response.EstimatedBaseFee)
+	if err != nil {
+		return GasPrices{}, err
+	}
+	result := GasPrices{
+		BaseFee: baseFee,
+		Fees:    make(map[GasPriority]FeeSuggestion, len(priorityOrder)),
+	}
+	result.BlockTime, _ = secondsToDuration(response.BlockTime)
+
+	levels := map[GasPriority]polygonFees{
+		GasPrioritySafeLow: response.SafeLow,
+		GasPriorityAverage: response.Standard,
+		GasPriorityFast:    response.Fast,
+		GasPriorityFastest: response.Fast,
+	}
+	for priority, fees := range levels {
+		maxFee, err := parseGweiToWei(fees.MaxFee)
+		if err != nil {
+			return GasPrices{}, err
+		}
+		maxPriorityFee, err := parseGweiToWei(fees.MaxPriorityFee)
+		if err != nil {
+			return GasPrices{}, err
+		}
+
+		switch priority {
+		case GasPriorityFast:
+			result.Fast = maxFee
+		case GasPriorityFastest:
+			result.Fastest = new(big.Int).Set(maxFee)
+		case GasPrioritySafeLow:
+			result.SafeLow = maxFee
+		case GasPriorityAverage:
+			result.Average = maxFee
+		}
+		result.Fees[priority] = FeeSuggestion{MaxFeePerGas: new(big.Int).Set(maxFee), MaxPriorityFeePerGas: maxPriorityFee}
+	}
+	return result, nil
+}
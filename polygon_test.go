@@ -0,0 +1,68 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolygonResponse = `{
+	"safeLow": {"maxPriorityFee": 30, "maxFee": 30.5},
+	"standard": {"maxPriorityFee": 32.25, "maxFee": 32.75},
+	"fast": {"maxPriorityFee": 40, "maxFee": 40.5},
+	"estimatedBaseFee": 0.500000000123,
+	"blockTime": 2,
+	"blockNumber": 50000000
+}`
+
+func TestPolygonGasStationProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testPolygonResponse))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	provider := &PolygonGasStationProvider{HTTPClient: &http.Client{Transport: testTransport{server: serverURL}}}
+
+	// 1. the levels are mapped onto priority levels, with fast also served as fastest
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "30500000000", prices.SafeLow.String())
+	assert.Equal(t, "32750000000", prices.Average.String())
+	assert.Equal(t, "40500000000", prices.Fast.String())
+	assert.Equal(t, "40500000000", prices.Fastest.String())
+	assert.Equal(t, 2*time.Second, prices.BlockTime)
+	assert.NoError(t, ValidateGasPrices(prices))
+
+	// 2. EIP-1559 fees are populated, rounded to the nearest wei
+	assert.Equal(t, "500000000", prices.BaseFee.String())
+	assert.Equal(t, "32750000000", prices.Fees[GasPriorityAverage].MaxFeePerGas.String())
+	assert.Equal(t, "32250000000", prices.Fees[GasPriorityAverage].MaxPriorityFeePerGas.String())
+
+	// 3. the prices don't share values
+	prices.Fast.SetInt64(0)
+	assert.Equal(t, "40500000000", prices.Fastest.String())
+	assert.Equal(t, "40500000000", prices.Fees[GasPriorityFast].MaxFeePerGas.String())
+}
+
+func TestPolygonGasStationProviderStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// the URL can be replaced, and unexpected status codes are a FetchError
+	provider := &PolygonGasStationProvider{URL: server.URL}
+	_, err := provider.Fetch(context.Background())
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusServiceUnavailable, fetchErr.StatusCode)
+}
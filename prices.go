@@ -0,0 +1,317 @@
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// GasPrices holds a suggested gas price in wei (base units) for each priority level, derived from a single response.
+type GasPrices struct {
+	Fast    *big.Int
+	Fastest *big.Int
+	SafeLow *big.Int
+	Average *big.Int
+
+	// Predictions is the table of expected wait times by gas price, sorted by ascending price. It is empty if the
+	// provider does not report predictions.
+	Predictions []PricePrediction
+
+	// Waits is the estimated time for a transaction priced at each priority level to be mined. It is nil if the provider
+	// does not report wait times.
+	Waits map[GasPriority]time.Duration
+
+	// Confidence is the probability, between 0 and 1, that a transaction priced at a priority level is mined within the
+	// target time of that level. It is nil if the provider does not report confidence.
+	Confidence map[GasPriority]float64
+
+	// UpdatedAt is when the provider computed the prices, as reported by the provider. It is distinct from when the
+	// prices were fetched, and helps tell a lagging oracle apart from a stale cache. It is zero if the provider does not
+	// report it, which is the case for the ETH Gas Station API.
+	UpdatedAt time.Time
+
+	// BlockTime is the average time between blocks of the chain the prices are for, as reported by the provider. It is
+	// zero if the provider does not report it. A client configured with WithBlockTimeCap caches the prices for at most
+	// this long.
+	BlockTime time.Duration
+
+	// BaseFee is the EIP-1559 base fee per gas in wei the fees were computed for. It is nil if the provider does not
+	// report EIP-1559 fees.
+	BaseFee *big.Int
+
+	// Fees holds the EIP-1559 fee parameters for each priority level. It is nil if the provider does not report them.
+	Fees map[GasPriority]FeeSuggestion
+
+	// Raw holds the price of each priority level as the number the provider returned, before conversion to wei, for
+	// telling data issues of the provider apart from conversion bugs. It is nil if the provider does not report it,
+	// only the ETHGasStationProvider does.
+	Raw map[GasPriority]string
+
+	// errs holds the error of each priority level whose reported price failed validation, which Price returns for that
+	// level while the other levels are served. It is not serialized, an exported level without a price has none.
+	errs map[GasPriority]error
+}
+
+// FeeSuggestion holds the EIP-1559 fee parameters in wei for a transaction.
+type FeeSuggestion struct {
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// GasPriceOption is a priority level with its price and estimates, for presenting a choice of speeds to a user.
+type GasPriceOption struct {
+	Priority GasPriority
+
+	// PriceWei is the gas price of the priority level in wei.
+	PriceWei *big.Int
+
+	// EstimatedWait is the estimated time for a transaction at the price to be mined, or zero if it is not reported.
+	EstimatedWait time.Duration
+
+	// Confidence is the probability that a transaction at the price is mined within the target time of the priority
+	// level, or NaN if it is not reported.
+	Confidence float64
+}
+
+// PricePrediction is the expected wait time for a transaction to be mined at a gas price in wei.
+type PricePrediction struct {
+	Price *big.Int
+	Wait  time.Duration
+}
+
+// Price returns the gas price in wei for the given priority. The returned value is a copy and may be modified freely.
+func (p GasPrices) Price(priority GasPriority) (*big.Int, error) {
+	price, err := p.price(priority)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(price), nil
+}
+
+// priceInto sets dst to the gas price for the given priority, computing the derived turbo level in dst without
+// allocating
+func (p GasPrices) priceInto(dst *big.Int, priority GasPriority) error {
+	if priority == GasPriorityTurbo && p.Fast != nil && p.Fastest != nil {
+		dst.Add(p.Fast, p.Fastest).Rsh(dst, 1)
+		return nil
+	}
+	price, err := p.price(priority)
+	if err != nil {
+		return err
+	}
+	dst.Set(price)
+	return nil
+}
+
+// price returns the stored gas price for the given priority without copying it
+func (p GasPrices) price(priority GasPriority) (*big.Int, error) {
+	var price *big.Int
+	switch priority {
+	case GasPriorityFast:
+		price = p.Fast
+	case GasPriorityFastest:
+		price = p.Fastest
+	case GasPrioritySafeLow:
+		price = p.SafeLow
+	case GasPriorityAverage:
+		price = p.Average
+	case GasPriorityTurbo:
+		if p.Fast == nil || p.Fastest == nil {
+			for _, level := range []GasPriority{GasPriorityFast, GasPriorityFastest} {
+				if err, ok := p.errs[level]; ok {
+					return nil, fmt.Errorf("eth: invalid gas price for %s: %w", level, err)
+				}
+			}
+			return nil, errors.New("eth: no gas price available for priority")
+		}
+		price = new(big.Int).Add(p.Fast, p.Fastest)
+		price.Rsh(price, 1)
+	default:
+		return nil, errors.New("eth: unknown/unsupported gas priority")
+	}
+
+	if price == nil {
+		if err, ok := p.errs[priority]; ok {
+			return nil, fmt.Errorf("eth: invalid gas price for %s: %w", priority, err)
+		}
+		return nil, errors.New("eth: no gas price available for priority")
+	}
+	return price, nil
+}
+
+// PriceForMaxWait returns the cheapest gas price in wei that is expected to confirm within maxWait, based on the
+// prediction table.
+//
+// An error is returned if there are no predictions or no price is expected to confirm in time.
+func (p GasPrices) PriceForMaxWait(maxWait time.Duration) (*big.Int, error) {
+	if len(p.Predictions) == 0 {
+		return nil, errors.New("eth: response does not include a gas price prediction table")
+	}
+
+	var cheapest *big.Int
+	for _, prediction := range p.Predictions {
+		if prediction.Wait > maxWait {
+			continue
+		}
+		if cheapest == nil || prediction.Price.Cmp(cheapest) < 0 {
+			cheapest = prediction.Price
+		}
+	}
+	if cheapest == nil {
+		return nil, errors.New("eth: no gas price is expected to confirm within the max wait")
+	}
+	return new(big.Int).Set(cheapest), nil
+}
+
+// priorityOrder lists the priority levels from cheapest to most expensive
+var priorityOrder = []GasPriority{GasPrioritySafeLow, GasPriorityAverage, GasPriorityFast, GasPriorityFastest}
+
+// Priorities returns the priority levels from cheapest to fastest: safeLow, average, fast and fastest. Iterate it to
+// read results keyed by priority level, such as Waits or Fees, in a deterministic order.
+func Priorities() []GasPriority {
+	return append([]GasPriority(nil), priorityOrder...)
+}
+
+// PriorityForTargetWait returns the cheapest priority level whose estimated wait time is at or under target, based on
+// the wait times reported for each level. It is a coarser alternative to PriceForMaxWait for providers that only report
+// wait times for the four priority levels.
+func (p GasPrices) PriorityForTargetWait(target time.Duration) (GasPriority, error) {
+	if len(p.Waits) == 0 {
+		return "", errors.New("eth: response does not include wait times")
+	}
+	for _, priority := range priorityOrder {
+		if wait, ok := p.Waits[priority]; ok && wait <= target {
+			return priority, nil
+		}
+	}
+	return "", errors.New("eth: no priority is expected to confirm within the target wait")
+}
+
+// PriceConfidence returns the probability that a transaction priced at the given priority is mined within the target
+// time of the priority, or NaN if the provider did not report a confidence for it.
+func (p GasPrices) PriceConfidence(priority GasPriority) float64 {
+	confidence, ok := p.Confidence[priority]
+	if !ok {
+		return math.NaN()
+	}
+	return confidence
+}
+
+// Option returns the priority level as an option with its price, wait estimate and confidence. The price is a copy
+// and may be modified freely. The derived turbo level has no wait estimate or confidence.
+func (p GasPrices) Option(priority GasPriority) (GasPriceOption, error) {
+	price, err := p.Price(priority)
+	if err != nil {
+		return GasPriceOption{}, err
+	}
+	return GasPriceOption{
+		Priority:      priority,
+		PriceWei:      price,
+		EstimatedWait: p.Waits[priority],
+		Confidence:    p.PriceConfidence(priority),
+	}, nil
+}
+
+// Options returns the priority levels that have a price as options sorted by ascending price, along with their wait
+// estimate and confidence. Levels with the same price keep their order from safeLow to fastest. The prices of the
+// options are copies and may be modified freely.
+func (p GasPrices) Options() []GasPriceOption {
+	options := make([]GasPriceOption, 0, len(priorityOrder))
+	for _, priority := range priorityOrder {
+		if option, err := p.Option(priority); err == nil {
+			options = append(options, option)
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].PriceWei.Cmp(options[j].PriceWei) < 0
+	})
+	return options
+}
+
+// copy returns a deep copy of the prices
+func (p GasPrices) copy() GasPrices {
+	c := GasPrices{
+		Fast:    copyInt(p.Fast),
+		Fastest: copyInt(p.Fastest),
+		SafeLow: copyInt(p.SafeLow),
+		Average: copyInt(p.Average),
+
+		UpdatedAt: p.UpdatedAt,
+		BlockTime: p.BlockTime,
+		BaseFee:   copyInt(p.BaseFee),
+	}
+	if p.Predictions != nil {
+		c.Predictions = make([]PricePrediction, len(p.Predictions))
+		for i, prediction := range p.Predictions {
+			c.Predictions[i] = PricePrediction{Price: copyInt(prediction.Price), Wait: prediction.Wait}
+		}
+	}
+	if p.Waits != nil {
+		c.Waits = make(map[GasPriority]time.Duration, len(p.Waits))
+		for priority, wait := range p.Waits {
+			c.Waits[priority] = wait
+		}
+	}
+	if p.Confidence != nil {
+		c.Confidence = make(map[GasPriority]float64, len(p.Confidence))
+		for priority, confidence := range p.Confidence {
+			c.Confidence[priority] = confidence
+		}
+	}
+	if p.Raw != nil {
+		c.Raw = make(map[GasPriority]string, len(p.Raw))
+		for priority, raw := range p.Raw {
+			c.Raw[priority] = raw
+		}
+	}
+	if p.errs != nil {
+		c.errs = make(map[GasPriority]error, len(p.errs))
+		for priority, err := range p.errs {
+			c.errs[priority] = err
+		}
+	}
+	if p.Fees != nil {
+		c.Fees = make(map[GasPriority]FeeSuggestion, len(p.Fees))
+		for priority, fee := range p.Fees {
+			c.Fees[priority] = FeeSuggestion{
+				MaxFeePerGas:         copyInt(fee.MaxFeePerGas),
+				MaxPriorityFeePerGas: copyInt(fee.MaxPriorityFeePerGas),
+			}
+		}
+	}
+	return c
+}
+
+// samePrices reports whether a and b have the same price, base fee and EIP-1559 fees for every priority level
+func samePrices(a, b GasPrices) bool {
+	if !sameInt(a.Fast, b.Fast) || !sameInt(a.Fastest, b.Fastest) || !sameInt(a.SafeLow, b.SafeLow) ||
+		!sameInt(a.Average, b.Average) || !sameInt(a.BaseFee, b.BaseFee) || len(a.Fees) != len(b.Fees) {
+		return false
+	}
+	for priority, fee := range a.Fees {
+		other, ok := b.Fees[priority]
+		if !ok || !sameInt(fee.MaxFeePerGas, other.MaxFeePerGas) ||
+			!sameInt(fee.MaxPriorityFeePerGas, other.MaxPriorityFeePerGas) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameInt reports whether x and y are both nil or equal
+func sameInt(x, y *big.Int) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	return x.Cmp(y) == 0
+}
+
+func copyInt(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+	return new(big.Int).Set(x)
+}
@@ -0,0 +1,195 @@
+package gas
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasPricesPrice(t *testing.T) {
+	prices := GasPrices{
+		Fast:    big.NewInt(3),
+		Fastest: big.NewInt(4),
+		SafeLow: big.NewInt(1),
+	}
+
+	// 1. each priority maps to its own price
+	price, err := prices.Price(GasPriorityFastest)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), price.Int64())
+
+	price, err = prices.Price(GasPrioritySafeLow)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), price.Int64())
+
+	// 2. missing prices and unknown priorities are errors
+	_, err = prices.Price(GasPriorityAverage)
+	assert.Error(t, err)
+	_, err = prices.Price(GasPriority("foo"))
+	assert.Error(t, err)
+
+	// 3. the returned price is a copy
+	price.SetInt64(100)
+	assert.Equal(t, int64(1), prices.SafeLow.Int64())
+
+	// 4. the turbo level is derived from fast and fastest, rounded down, and needs both
+	price, err = prices.Price(GasPriorityTurbo)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), price.Int64())
+	price, err = GasPrices{Fast: big.NewInt(20), Fastest: big.NewInt(30)}.Price(GasPriorityTurbo)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), price.Int64())
+	_, err = GasPrices{Fast: big.NewInt(3)}.Price(GasPriorityTurbo)
+	assert.Error(t, err)
+}
+
+func TestGasPricesPriceForMaxWait(t *testing.T) {
+	prices := GasPrices{
+		Predictions: []PricePrediction{
+			{Price: big.NewInt(40), Wait: 30 * time.Minute},
+			{Price: big.NewInt(100), Wait: 5 * time.Minute},
+			{Price: big.NewInt(120), Wait: 3 * time.Minute},
+			{Price: big.NewInt(150), Wait: 3 * time.Minute},
+			{Price: big.NewInt(200), Wait: 30 * time.Second},
+		},
+	}
+
+	// 1. the cheapest price within the max wait is selected
+	price, err := prices.PriceForMaxWait(3 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(120), price.Int64())
+
+	// 2. a long max wait selects the cheapest price in the table
+	price, err = prices.PriceForMaxWait(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), price.Int64())
+
+	// 3. no price confirms fast enough
+	_, err = prices.PriceForMaxWait(10 * time.Second)
+	assert.Error(t, err)
+
+	// 4. there are no predictions
+	_, err = GasPrices{}.PriceForMaxWait(time.Hour)
+	assert.Error(t, err)
+}
+
+func TestGasPricesPriceConfidence(t *testing.T) {
+	prices := GasPrices{Confidence: map[GasPriority]float64{GasPriorityFast: 0.95}}
+
+	assert.Equal(t, 0.95, prices.PriceConfidence(GasPriorityFast))
+	assert.True(t, math.IsNaN(prices.PriceConfidence(GasPrioritySafeLow)))
+	assert.True(t, math.IsNaN(GasPrices{}.PriceConfidence(GasPriorityFast)))
+
+	// the confidence table is copied with the prices
+	copied := prices.copy()
+	copied.Confidence[GasPriorityFast] = 0.5
+	assert.Equal(t, 0.95, prices.PriceConfidence(GasPriorityFast))
+}
+
+func TestGasPricesCopyFees(t *testing.T) {
+	prices := GasPrices{
+		BaseFee: big.NewInt(20e9),
+		Fees: map[GasPriority]FeeSuggestion{
+			GasPriorityFast: {MaxFeePerGas: big.NewInt(42e9), MaxPriorityFeePerGas: big.NewInt(2e9)},
+		},
+		Raw: map[GasPriority]string{GasPriorityFast: "420"},
+	}
+
+	// the base fee, fees and raw values are copied with the prices
+	copied := prices.copy()
+	copied.BaseFee.SetInt64(0)
+	copied.Fees[GasPriorityFast].MaxFeePerGas.SetInt64(0)
+	copied.Raw[GasPriorityFast] = "0"
+	assert.Equal(t, "20000000000", prices.BaseFee.String())
+	assert.Equal(t, "42000000000", prices.Fees[GasPriorityFast].MaxFeePerGas.String())
+	assert.Equal(t, "420", prices.Raw[GasPriorityFast])
+}
+
+func TestGasPricesPriorityForTargetWait(t *testing.T) {
+	prices := GasPrices{Waits: map[GasPriority]time.Duration{
+		GasPrioritySafeLow: 30 * time.Minute,
+		GasPriorityAverage: 5 * time.Minute,
+		GasPriorityFast:    2 * time.Minute,
+		GasPriorityFastest: 30 * time.Second,
+	}}
+
+	// 1. the cheapest priority at or under the target is returned
+	priority, err := prices.PriorityForTargetWait(5 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, GasPriorityAverage, priority)
+
+	priority, err = prices.PriorityForTargetWait(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, GasPrioritySafeLow, priority)
+
+	// 2. no priority meets the target
+	_, err = prices.PriorityForTargetWait(10 * time.Second)
+	assert.Error(t, err)
+
+	// 3. there are no wait times
+	_, err = GasPrices{}.PriorityForTargetWait(time.Hour)
+	assert.Error(t, err)
+}
+
+func TestGasPricesOptions(t *testing.T) {
+	prices := GasPrices{
+		Fast:    big.NewInt(30),
+		Fastest: big.NewInt(30),
+		SafeLow: big.NewInt(10),
+		Waits:   map[GasPriority]time.Duration{GasPriorityFast: 2 * time.Minute},
+		Confidence: map[GasPriority]float64{
+			GasPriorityFast:    0.9,
+			GasPriorityFastest: 0.95,
+		},
+	}
+
+	// 1. levels with a price are sorted by price, ties keep their priority order
+	options := prices.Options()
+	require.Len(t, options, 3)
+	assert.Equal(t, GasPrioritySafeLow, options[0].Priority)
+	assert.Equal(t, GasPriorityFast, options[1].Priority)
+	assert.Equal(t, GasPriorityFastest, options[2].Priority)
+
+	// 2. estimates are included when reported
+	assert.Equal(t, int64(30), options[1].PriceWei.Int64())
+	assert.Equal(t, 2*time.Minute, options[1].EstimatedWait)
+	assert.Equal(t, 0.9, options[1].Confidence)
+	assert.Zero(t, options[0].EstimatedWait)
+	assert.True(t, math.IsNaN(options[0].Confidence))
+
+	// 3. the prices are copies
+	options[0].PriceWei.SetInt64(0)
+	assert.Equal(t, int64(10), prices.SafeLow.Int64())
+}
+
+func TestPriorities(t *testing.T) {
+	// 1. levels are ordered from cheapest to fastest
+	expected := []GasPriority{GasPrioritySafeLow, GasPriorityAverage, GasPriorityFast, GasPriorityFastest}
+	assert.Equal(t, expected, Priorities())
+
+	// 2. the returned slice is a copy
+	Priorities()[0] = GasPriorityFastest
+	assert.Equal(t, expected, Priorities())
+}
+
+func TestSamePrices(t *testing.T) {
+	prices := GasPrices{
+		Fast:    big.NewInt(2),
+		BaseFee: big.NewInt(1),
+		Fees: map[GasPriority]FeeSuggestion{
+			GasPriorityFast: {MaxFeePerGas: big.NewInt(3), MaxPriorityFeePerGas: big.NewInt(1)},
+		},
+	}
+	assert.True(t, samePrices(prices, prices.copy()))
+
+	changed := prices.copy()
+	changed.Fees[GasPriorityFast].MaxPriorityFeePerGas.SetInt64(2)
+	assert.False(t, samePrices(prices, changed))
+	changed = prices.copy()
+	changed.SafeLow = big.NewInt(1)
+	assert.False(t, samePrices(prices, changed))
+}
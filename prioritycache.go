@@ -0,0 +1,115 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// PriorityProvider is implemented by providers that can load the price of a single priority level more cheaply than
+// all prices at once. A client configured with WithPerPriorityCache uses it to cache each priority level separately.
+type PriorityProvider interface {
+	Provider
+
+	// FetchPriority loads the price in wei of a single priority level.
+	FetchPriority(ctx context.Context, priority GasPriority) (*big.Int, error)
+}
+
+// priorityCache caches the price of each priority level with its own age. Prices requested at a percentile are cached
+// the same way, keyed by the percentile.
+type priorityCache struct {
+	sync.Mutex
+
+	maxResultAge time.Duration
+	entries      map[string]priorityEntry
+
+	// now returns the current time, it defaults to time.Now
+	now func() time.Time
+}
+
+type priorityEntry struct {
+	price     *big.Int
+	fetchedAt time.Time
+}
+
+// latest returns the cached price of key, calling fetch if it is older than the maximum age
+func (m *priorityCache) latest(
+	ctx context.Context,
+	key string,
+	fetch func(context.Context) (*big.Int, error),
+) (*big.Int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if entry, ok := m.entries[key]; ok {
+		if age := m.clock().Sub(entry.fetchedAt); age >= 0 && age <= m.maxResultAge && m.maxResultAge > 0 {
+			return entry.price, nil
+		}
+	}
+
+	price, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if m.entries == nil {
+		m.entries = make(map[string]priorityEntry)
+	}
+	m.entries[key] = priorityEntry{price: price, fetchedAt: m.clock()}
+	return price, nil
+}
+
+// invalidate drops every cached price
+func (m *priorityCache) invalidate() {
+	m.Lock()
+	defer m.Unlock()
+	m.entries = nil
+}
+
+func (m *priorityCache) clock() time.Time {
+	if m.now == nil {
+		return time.Now()
+	}
+	return m.now()
+}
+
+// suggestPriority returns the price of priority from the per-priority cache
+func (c *Client) suggestPriority(ctx context.Context, provider PriorityProvider, priority GasPriority) (*big.Int, error) {
+	price, err := c.priorityCache.latest(ctx, string(priority), func(ctx context.Context) (*big.Int, error) {
+		prices, err := c.fetchWith(ctx, func(ctx context.Context) (GasPrices, error) {
+			return c.retry(ctx, func(ctx context.Context) (GasPrices, error) {
+				price, err := provider.FetchPriority(ctx, priority)
+				if err != nil {
+					return GasPrices{}, err
+				}
+				return GasPrices{}.withPrice(priority, price)
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return prices.price(priority)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(price), nil
+}
+
+// withPrice returns a copy of p with the price of priority replaced
+func (p GasPrices) withPrice(priority GasPriority, price *big.Int) (GasPrices, error) {
+	switch priority {
+	case GasPriorityFast:
+		p.Fast = price
+	case GasPriorityFastest:
+		p.Fastest = price
+	case GasPrioritySafeLow:
+		p.SafeLow = price
+	case GasPriorityAverage:
+		p.Average = price
+	default:
+		return GasPrices{}, errors.New("eth: unknown/unsupported gas priority")
+	}
+	return p, nil
+}
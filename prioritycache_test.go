@@ -0,0 +1,91 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPriorityProvider counts the requests made for each priority level
+type testPriorityProvider struct {
+	mu    sync.Mutex
+	calls map[GasPriority]int
+}
+
+func (p *testPriorityProvider) Fetch(context.Context) (GasPrices, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls["all"]++
+	return GasPrices{Fast: big.NewInt(20e9), SafeLow: big.NewInt(10e9)}, nil
+}
+
+func (p *testPriorityProvider) FetchPriority(_ context.Context, priority GasPriority) (*big.Int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[priority]++
+	return big.NewInt(int64(len(priority)) * 1e9), nil
+}
+
+func (p *testPriorityProvider) count(priority GasPriority) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[priority]
+}
+
+func TestWithPerPriorityCache(t *testing.T) {
+	provider := &testPriorityProvider{calls: make(map[GasPriority]int)}
+	c, err := NewClient(
+		WithProvider(provider),
+		WithMaxResultAge(30*time.Millisecond),
+		WithPerPriorityCache(),
+		WithRoundTo(2),
+	)
+	require.NoError(t, err)
+
+	// 1. each priority is loaded and cached on its own, with the client's configuration applied
+	for i := 0; i < 2; i++ {
+		price, err := c.SuggestGasPrice(GasPrioritySafeLow)
+		require.NoError(t, err)
+		assert.Equal(t, "8000000000", price.String())
+	}
+	assert.Equal(t, 1, provider.count(GasPrioritySafeLow))
+	assert.Equal(t, 0, provider.count(GasPriorityFastest))
+	assert.Equal(t, 0, provider.count("all"))
+
+	// 2. each priority has its own age
+	time.Sleep(20 * time.Millisecond)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPrioritySafeLow)
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.count(GasPriorityFast))
+	assert.Equal(t, 2, provider.count(GasPrioritySafeLow))
+
+	// 3. methods that need every price use the whole response
+	_, _, err = c.SuggestGasPriceWithPrices(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.count("all"))
+	_, err = c.SuggestGasPrice(GasPriorityTurbo)
+	assert.Error(t, err, "the test provider doesn't report fastest")
+	assert.Equal(t, 0, provider.count(GasPriorityTurbo))
+
+	// 4. other providers use the whole-response cache
+	var calls int32
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(time.Minute), WithPerPriorityCache())
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls)
+
+	// 5. per-priority caching requires caching
+	_, err = NewClient(WithPerPriorityCache())
+	assert.Error(t, err)
+}
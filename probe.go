@@ -0,0 +1,156 @@
+package gas
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ProviderStatus is the outcome of probing a provider with Client.ProbeAll.
+type ProviderStatus struct {
+	// Name is the name set with NamedProvider, or the type of the provider if it has none.
+	Name string
+
+	// Healthy is set if the provider returned valid prices.
+	Healthy bool
+
+	// Latency is how long the provider took to respond.
+	Latency time.Duration
+
+	// Err is why the provider is unhealthy.
+	Err error
+}
+
+// NamedProvider returns a Provider that loads prices from provider, reported by name in the results of
+// Client.ProbeAll.
+func NamedProvider(name string, provider Provider) Provider {
+	return &namedProvider{name: name, next: provider}
+}
+
+type namedProvider struct {
+	name string
+	next Provider
+}
+
+func (p *namedProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	return p.next.Fetch(ctx)
+}
+
+func (p *namedProvider) CloseIdleConnections() {
+	closeIdleConnections(p.next)
+}
+
+// providerGroup is implemented by providers that load prices from other providers, which are probed individually
+type providerGroup interface {
+	children() []Provider
+}
+
+// providerWrapper is implemented by middleware, which is bypassed when probing so probes don't change its state or
+// are served from a cache
+type providerWrapper interface {
+	unwrap() Provider
+}
+
+func (p *fallbackProvider) children() []Provider {
+	return p.providers
+}
+
+func (p *retryProvider) unwrap() Provider {
+	return p.next
+}
+
+func (p *circuitBreakerProvider) unwrap() Provider {
+	return p.next
+}
+
+func (p *cacheProvider) unwrap() Provider {
+	return p.next
+}
+
+func (p *faultProvider) unwrap() Provider {
+	return p.next
+}
+
+func (p *sharedCacheProvider) unwrap() Provider {
+	return p.provider
+}
+
+func (p *TWAPProvider) unwrap() Provider {
+	return p.next
+}
+
+// ProbeAll loads prices from every provider of the client concurrently, reporting whether each one is healthy and
+// how long it took, for health dashboards that show which sources are up before a call has to fall back. The providers
+// of a fallback provider are probed individually, and middleware such as Retry, CircuitBreaker and caches is bypassed,
+// so probing doesn't affect calls. Each probe is bounded by ctx and the timeout the client is configured with.
+//
+// The statuses are returned in the order the providers are configured, and the prices are checked for invalid values
+// like any response, but are otherwise discarded.
+func (c *Client) ProbeAll(ctx context.Context) []ProviderStatus {
+	providers := probeTargets(c.source())
+	statuses := make([]ProviderStatus, len(providers))
+	for i, provider := range providers {
+		statuses[i].Name = providerName(provider)
+	}
+
+	ctx, done, err := c.begin(ctx)
+	if err != nil {
+		for i := range statuses {
+			statuses[i].Err = c.wrapError(err)
+		}
+		return statuses
+	}
+	defer done()
+
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(status *ProviderStatus, provider Provider) {
+			defer wg.Done()
+			probeCtx, cancel := c.withTimeout(ctx)
+			defer cancel()
+
+			start := time.Now()
+			prices, err := provider.Fetch(probeCtx)
+			status.Latency = time.Since(start)
+			if err == nil {
+				err = validatePrices(prices, c.rejectZero)
+			}
+			status.Healthy = err == nil
+			status.Err = err
+		}(&statuses[i], provider)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// probeTargets returns the providers that provider loads prices from, bypassing middleware
+func probeTargets(provider Provider) []Provider {
+	for {
+		switch p := provider.(type) {
+		case providerGroup:
+			var targets []Provider
+			for _, child := range p.children() {
+				targets = append(targets, probeTargets(child)...)
+			}
+			return targets
+		case providerWrapper:
+			provider = p.unwrap()
+		default:
+			return []Provider{provider}
+		}
+	}
+}
+
+// providerName returns the name of a provider wrapped with NamedProvider, or its type
+func providerName(provider Provider) string {
+	switch p := provider.(type) {
+	case *namedProvider:
+		return p.name
+	case *keyPoolProvider:
+		// the rotation of keys is an implementation detail of the default provider
+		return providerName(&p.provider)
+	}
+	return fmt.Sprintf("%T", provider)
+}
@@ -0,0 +1,60 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientProbeAll(t *testing.T) {
+	var calls, breakerCalls int32
+	down := errors.New("down")
+	slow := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		time.Sleep(20 * time.Millisecond)
+		return GasPrices{Fast: big.NewInt(1)}, nil
+	})
+	breaker := Chain(countingProvider(100, down, &breakerCalls), CircuitBreaker(1, time.Hour))
+	provider := Chain(NewFallbackProvider([]Provider{
+		NamedProvider("primary", breaker),
+		NamedProvider("backup", countingProvider(0, nil, &calls)),
+		NewFallbackProvider([]Provider{NamedProvider("slow", slow)}),
+	}), Cache(time.Hour))
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	// 1. every provider of nested fallback providers is probed, behind middleware of the client's provider
+	statuses := c.ProbeAll(context.Background())
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "primary", statuses[0].Name)
+	assert.False(t, statuses[0].Healthy)
+	assert.True(t, errors.Is(statuses[0].Err, down))
+	assert.Equal(t, "backup", statuses[1].Name)
+	assert.True(t, statuses[1].Healthy)
+	assert.NoError(t, statuses[1].Err)
+	assert.Equal(t, "slow", statuses[2].Name)
+	assert.True(t, statuses[2].Healthy)
+	assert.GreaterOrEqual(t, int64(statuses[2].Latency), int64(20*time.Millisecond))
+
+	// 2. probes bypass the cache of the client's provider
+	c.ProbeAll(context.Background())
+	assert.Equal(t, int32(2), calls)
+
+	// 3. the default provider is reported by its type
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+	statuses = c.ProbeAll(context.Background())
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "*gas.ETHGasStationProvider", statuses[0].Name)
+	assert.True(t, statuses[0].Healthy)
+
+	// 4. a closed client probes nothing
+	require.NoError(t, c.Close())
+	statuses = c.ProbeAll(context.Background())
+	require.Len(t, statuses, 1)
+	assert.True(t, errors.Is(statuses[0].Err, ErrClientClosed))
+}
@@ -0,0 +1,151 @@
+package gas
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// processCache holds the prices shared by the clients configured with WithProcessCache, keyed by the identity of
+// their provider as returned by processCacheKey. Entries are deleted once they are older than the max age they were
+// stored with, whenever another entry is stored.
+var processCache = struct {
+	sync.Mutex
+	entries map[interface{}]processCacheEntry
+}{entries: make(map[interface{}]processCacheEntry)}
+
+type processCacheEntry struct {
+	prices    GasPrices
+	fetchedAt time.Time
+	maxAge    time.Duration
+}
+
+// defaultProviderKey identifies a default provider by the configuration that determines the prices it loads
+type defaultProviderKey struct {
+	endpoint     string
+	inputScale   InputScale
+	envelopePath string
+	fieldMapping string
+	strictSchema bool
+	allowed      string
+}
+
+// customProviderKey identifies a provider configured with WithProvider
+type customProviderKey struct {
+	provider Provider
+}
+
+// fetchShared returns the prices in the process cache if they are younger than the max age of the client, as measured
+// by the clock set with WithNowFunc, and otherwise calls fetch and stores its prices for the other clients with the
+// same provider
+func (c *Client) fetchShared(ctx context.Context, fetch func(context.Context) (GasPrices, error)) (GasPrices, error) {
+	key, ok := c.processCacheKey()
+	if !ok {
+		return fetch(ctx)
+	}
+
+	processCache.Lock()
+	entry, ok := processCache.entries[key]
+	processCache.Unlock()
+	if ok && c.clock().Sub(entry.fetchedAt) < c.processCacheAge {
+		c.emit(Event{Type: EventCacheHit, Prices: entry.prices})
+		return entry.prices.copy(), nil
+	}
+
+	prices, err := fetch(ctx)
+	if err != nil {
+		return GasPrices{}, err
+	}
+	now := c.clock()
+	processCache.Lock()
+	for other, entry := range processCache.entries {
+		if now.Sub(entry.fetchedAt) >= entry.maxAge {
+			delete(processCache.entries, other)
+		}
+	}
+	processCache.entries[key] = processCacheEntry{prices: prices.copy(), fetchedAt: now, maxAge: c.processCacheAge}
+	processCache.Unlock()
+	return prices, nil
+}
+
+// clock returns the current time of the clock set with WithNowFunc, or time.Now
+func (c *Client) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
+
+// processCacheKey returns the identity of the provider of the client, and false if it can't be shared. Default
+// providers are identified by their normalized endpoint, key and decoding options, and providers set with
+// WithProvider by their pointer, so only clients given the same provider share its prices. Providers that aren't
+// pointers, and default providers with a charset reader or schema drift handler, which can't be compared, are never
+// shared.
+func (c *Client) processCacheKey() (interface{}, bool) {
+	if provider := c.customProvider(); provider != nil {
+		if reflect.TypeOf(provider).Kind() != reflect.Ptr {
+			return nil, false
+		}
+		return customProviderKey{provider: provider}, true
+	}
+	if c.charsetReader != nil || c.schemaDrift != nil {
+		return nil, false
+	}
+
+	c.configMu.RLock()
+	provider := ETHGasStationProvider{URL: c.url, APIKey: c.apiKey}
+	c.configMu.RUnlock()
+	endpoint := normalizeURL(provider.url())
+	if c.keys != nil {
+		// clients with the same keys share prices, whichever key loaded them
+		keys := append([]string(nil), c.keys.keys...)
+		sort.Strings(keys)
+		endpoint += " " + strings.Join(keys, " ")
+	}
+
+	mapping := make([]string, 0, len(c.fieldMapping))
+	for priority, field := range c.fieldMapping {
+		mapping = append(mapping, string(priority)+"="+field)
+	}
+	sort.Strings(mapping)
+	allowed := append([]string(nil), c.allowedFields...)
+	sort.Strings(allowed)
+	return defaultProviderKey{
+		endpoint:     endpoint,
+		inputScale:   c.inputScale,
+		envelopePath: c.envelopePath,
+		fieldMapping: strings.Join(mapping, ","),
+		strictSchema: c.strictSchema,
+		allowed:      strings.Join(allowed, ","),
+	}, true
+}
+
+// normalizeURL lowercases the scheme and host of rawURL, and removes a default port and a trailing slash, so
+// equivalent spellings of an endpoint share prices
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		host = "[" + host + "]"
+	}
+	u.Host = host
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
@@ -0,0 +1,128 @@
+package gas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedProvider is a provider with pointer identity, so clients given the same one share its prices
+type sharedProvider struct {
+	Provider
+}
+
+func TestWithProcessCache(t *testing.T) {
+	var calls int32
+	provider := &sharedProvider{countingProvider(0, nil, &calls)}
+	newClient := func(provider Provider, opts ...Option) *Client {
+		c, err := NewClient(append([]Option{WithProvider(provider)}, opts...)...)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	}
+
+	// 1. clients with the same provider share the prices loaded by the first one
+	first, second := newClient(provider, WithProcessCache(time.Minute)), newClient(provider, WithProcessCache(time.Minute))
+	for _, c := range []*Client{first, second, first} {
+		price, err := c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+		assert.Equal(t, "20000000000", price.String())
+	}
+	assert.Equal(t, int32(1), calls)
+
+	// 2. clients without the option, and clients with another provider, are isolated
+	_, err := newClient(provider).SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	var otherCalls int32
+	other := &sharedProvider{countingProvider(0, nil, &otherCalls)}
+	_, err = newClient(other, WithProcessCache(time.Minute)).SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls)
+	assert.Equal(t, int32(1), otherCalls)
+
+	// 3. shared prices older than the max age of a client are loaded again
+	time.Sleep(10 * time.Millisecond)
+	_, err = newClient(provider, WithProcessCache(5*time.Millisecond)).SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+
+	// 4. the max age must be positive
+	_, err = NewClient(WithProcessCache(0))
+	assert.Error(t, err)
+}
+
+func TestWithProcessCacheClock(t *testing.T) {
+	var calls int32
+	provider := &sharedProvider{countingProvider(0, nil, &calls)}
+	now := time.Now()
+	clock := func() time.Time { return now }
+	c, err := NewClient(WithProvider(provider), WithProcessCache(time.Minute), WithNowFunc(clock))
+	require.NoError(t, err)
+	defer c.Close()
+
+	// 1. the age of shared prices is measured by the clock of the client
+	for i := 0; i < 2; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), calls)
+	now = now.Add(time.Minute)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls)
+
+	// 2. expired entries are deleted when another entry is stored
+	var otherCalls int32
+	other := &sharedProvider{countingProvider(0, nil, &otherCalls)}
+	o, err := NewClient(WithProvider(other), WithProcessCache(time.Minute), WithNowFunc(clock))
+	require.NoError(t, err)
+	defer o.Close()
+	now = now.Add(time.Minute)
+	_, err = o.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	processCache.Lock()
+	_, ok := processCache.entries[customProviderKey{provider: provider}]
+	processCache.Unlock()
+	assert.False(t, ok)
+}
+
+func TestWithProcessCacheDefaultProvider(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		serveTestResponse(w, r)
+	}))
+	defer server.Close()
+	load := func(opts ...Option) {
+		c, err := NewClient(append([]Option{WithProcessCache(time.Minute)}, opts...)...)
+		require.NoError(t, err)
+		defer c.Close()
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+
+	// 1. equivalent spellings of the endpoint share prices
+	load(WithURL(server.URL + "/gas"))
+	load(WithURL(server.URL + "/gas/"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// 2. another key or input scale is another identity
+	load(WithURL(server.URL+"/gas"), WithAPIKey("0123456789abcdef"), WithRequireTLS(false))
+	load(WithURL(server.URL+"/gas"), WithInputScale(InputScaleGwei))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestNormalizeURL(t *testing.T) {
+	// 1. the scheme and host are lowercased, and a default port and trailing slash are removed
+	assert.Equal(t, "https://example.com/gas", normalizeURL("HTTPS://Example.COM:443/gas/"))
+	assert.Equal(t, "http://example.com:8080/gas", normalizeURL("http://example.com:8080/gas"))
+	assert.Equal(t, "http://[::1]/gas", normalizeURL("http://[::1]:80/gas"))
+
+	// 2. query parameters are sorted
+	assert.Equal(t, "https://example.com/gas?a=1&b=2", normalizeURL("https://example.com/gas?b=2&a=1"))
+}
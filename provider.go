@@ -0,0 +1,37 @@
+package gas
+
+import "context"
+
+// Provider is a source of gas prices. The ETHGasStationProvider is used by default, use WithProvider to configure a
+// Client with a different source. Implementations must be safe for concurrent use.
+//
+// Providers should return a *FetchError if a request fails, so that transient failures can be retried by a Client
+// configured with WithRetry.
+type Provider interface {
+	Fetch(ctx context.Context) (GasPrices, error)
+}
+
+// ProviderFunc is an adapter to allow the use of ordinary functions as a Provider.
+type ProviderFunc func(ctx context.Context) (GasPrices, error)
+
+// Fetch calls f(ctx).
+func (f ProviderFunc) Fetch(ctx context.Context) (GasPrices, error) {
+	return f(ctx)
+}
+
+// configValidator is implemented by providers whose settings can be checked before making requests, they are checked
+// once by NewClient and SetProvider rather than on every fetch
+type configValidator interface {
+	validateConfig() error
+}
+
+// idleConnectionCloser is implemented by providers that hold idle HTTP connections, which are closed by Client.Close
+type idleConnectionCloser interface {
+	CloseIdleConnections()
+}
+
+func closeIdleConnections(p Provider) {
+	if closer, ok := p.(idleConnectionCloser); ok {
+		closer.CloseIdleConnections()
+	}
+}
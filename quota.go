@@ -0,0 +1,63 @@
+package gas
+
+import (
+	"sync"
+	"time"
+)
+
+// QuotaTracker counts the successful requests made to a provider, so users of metered APIs can alert before they
+// exhaust a quota. Configure a Client with it using WithQuotaTracker, and share it between clients that use the same
+// API key. The zero value is ready to use and counts requests until it is reset.
+type QuotaTracker struct {
+	// MonthlyReset resets the count at the start of each calendar month in UTC, to match a monthly quota.
+	MonthlyReset bool
+
+	mu    sync.Mutex
+	count uint64
+	month int
+
+	// now returns the current time, it defaults to time.Now
+	now func() time.Time
+}
+
+// RequestCount returns the number of successful requests since the tracker was created or reset, or since the start
+// of the month if MonthlyReset is set.
+func (q *QuotaTracker) RequestCount() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollover()
+	return q.count
+}
+
+// Reset sets the count back to zero.
+func (q *QuotaTracker) Reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.count = 0
+}
+
+// record counts a successful request
+func (q *QuotaTracker) record() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollover()
+	q.count++
+}
+
+// rollover resets the count if a new month started since the last request, it must be called with the lock held
+func (q *QuotaTracker) rollover() {
+	if !q.MonthlyReset {
+		return
+	}
+	now := time.Now
+	if q.now != nil {
+		now = q.now
+	}
+	year, month, _ := now().UTC().Date()
+	if current := year*12 + int(month); current != q.month {
+		q.month = current
+		q.count = 0
+	}
+}
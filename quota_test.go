@@ -0,0 +1,58 @@
+package gas
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithQuotaTracker(t *testing.T) {
+	// 1. successful requests are counted, failed attempts and cache hits are not
+	var requests int32
+	tracker := new(QuotaTracker)
+	c, stop := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 1, &requests),
+		WithRetry(1, time.Millisecond),
+		WithMaxResultAge(time.Minute),
+		WithQuotaTracker(tracker),
+	)
+	defer stop()
+
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityAverage)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, uint64(1), tracker.RequestCount())
+
+	// 2. the count can be reset
+	tracker.Reset()
+	assert.Zero(t, tracker.RequestCount())
+
+	// 3. the tracker must be set
+	_, err = NewClient(WithQuotaTracker(nil))
+	assert.Error(t, err)
+}
+
+func TestQuotaTrackerMonthlyReset(t *testing.T) {
+	now := time.Date(2021, time.January, 31, 23, 59, 0, 0, time.UTC)
+	tracker := &QuotaTracker{MonthlyReset: true, now: func() time.Time { return now }}
+
+	// 1. requests are counted within a month
+	tracker.record()
+	tracker.record()
+	assert.Equal(t, uint64(2), tracker.RequestCount())
+
+	// 2. the count starts over in a new month
+	now = now.Add(time.Minute)
+	assert.Zero(t, tracker.RequestCount())
+	tracker.record()
+	assert.Equal(t, uint64(1), tracker.RequestCount())
+
+	// 3. the same month of another year is a new month
+	now = now.AddDate(1, 0, 0)
+	assert.Zero(t, tracker.RequestCount())
+}
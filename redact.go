@@ -0,0 +1,83 @@
+package gas
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redactedQueryParameters are the query parameters that carry API keys, compared case-insensitively
+var redactedQueryParameters = map[string]bool{
+	"api-key": true,
+	"apikey":  true,
+	"api_key": true,
+	"key":     true,
+}
+
+// RedactURL returns rawURL with the values of query parameters that carry API keys, such as api-key and apikey,
+// replaced by ***, so the URL of a request can be logged without leaking secrets. A password in the user info is
+// replaced by xxxxx, as by url.URL.Redacted, and a URL that can't be parsed is replaced entirely.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "***"
+	}
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+
+	parameters := strings.Split(u.RawQuery, "&")
+	for i, parameter := range parameters {
+		rawName := parameter
+		if j := strings.IndexByte(parameter, '='); j >= 0 {
+			rawName = parameter[:j]
+		}
+		name, err := url.QueryUnescape(rawName)
+		if err != nil {
+			name = rawName
+		}
+		if redactedQueryParameters[strings.ToLower(name)] {
+			parameters[i] = rawName + "=***"
+		}
+	}
+	u.RawQuery = strings.Join(parameters, "&")
+	return u.Redacted()
+}
+
+// LogRequests returns a RoundTripper that calls log with the method and redacted URL of each request before sending it
+// with next, or http.DefaultTransport if next is nil. Use it in the HTTP client of any provider, or WithRequestLogger
+// for the default provider.
+func LogRequests(next http.RoundTripper, log func(method, redactedURL string)) http.RoundTripper {
+	return LogRequestsContext(next, func(_ context.Context, method, redactedURL string) {
+		log(method, redactedURL)
+	})
+}
+
+// LogRequestsContext is like LogRequests, but also passes log the context of each request.
+func LogRequestsContext(
+	next http.RoundTripper,
+	log func(ctx context.Context, method, redactedURL string),
+) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &loggingTransport{next: next, log: log}
+}
+
+type loggingTransport struct {
+	next http.RoundTripper
+	log  func(ctx context.Context, method, redactedURL string)
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.log(req.Context(), req.Method, RedactURL(req.URL.String()))
+	return t.next.RoundTrip(req)
+}
+
+// CloseIdleConnections closes idle connections of the underlying transport, if it supports it.
+func (t *loggingTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(idleConnectionCloser); ok {
+		closer.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,154 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Refresher keeps the prices of a client up to date in the background, so reads never wait for a request. A failed
+// refresh keeps the previous prices until the next attempt. Create one with Client.NewRefresher.
+type Refresher struct {
+	client   *Client
+	interval time.Duration
+
+	mu        sync.Mutex
+	prices    GasPrices
+	fetchedAt time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// RefresherOption configures a Refresher.
+type RefresherOption func(*refresherConfig)
+
+type refresherConfig struct {
+	warmOnStart bool
+}
+
+// WithWarmOnStart controls whether NewRefresher blocks until the first refresh succeeds, which guarantees the first read
+// is served fresh prices. By default NewRefresher returns immediately, and reads fail until the first refresh completes.
+func WithWarmOnStart(warm bool) RefresherOption {
+	return func(c *refresherConfig) {
+		c.warmOnStart = warm
+	}
+}
+
+// NewRefresher starts refreshing prices every interval in the background, until Stop is called or the client is closed.
+// The prices are loaded using the client's configuration, but bypass its cache.
+//
+// If the refresher is configured to warm on start, ctx bounds how long NewRefresher waits for the first refresh, and
+// the error is returned if it fails. The background refreshes are not bound to ctx, but carry its values, such as a
+// request ID for correlating their requests in logs.
+func (c *Client) NewRefresher(ctx context.Context, interval time.Duration, opts ...RefresherOption) (*Refresher, error) {
+	if interval <= 0 {
+		return nil, errors.New("eth: refresh interval must be positive")
+	}
+	var config refresherConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	r := &Refresher{
+		client:   c,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if config.warmOnStart {
+		if err := r.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	go r.run(detach(ctx), !config.warmOnStart)
+	return r, nil
+}
+
+// SuggestGasPrice returns the most recently refreshed gas price in wei for the given priority. Its method value can be
+// used as a GasPriceSuggester, and the returned value may be modified freely.
+//
+// If the client was configured with WithMaxServeAge, prices older than the max serve age are not served, so a refresher
+// whose refreshes keep failing returns an error wrapping ErrValueTooOld rather than old prices.
+func (r *Refresher) SuggestGasPrice(priority GasPriority) (*big.Int, error) {
+	prices, fetchedAt, ok := r.Prices()
+	if !ok {
+		return nil, errors.New("eth: no gas prices have been loaded yet")
+	}
+	if err := checkServeAge(r.client.clock().Sub(fetchedAt), r.client.maxServeAge); err != nil {
+		return nil, err
+	}
+	return prices.Price(priority)
+}
+
+// Prices returns a copy of the most recently refreshed prices and when they were fetched, and false if no refresh has
+// succeeded yet.
+func (r *Refresher) Prices() (prices GasPrices, fetchedAt time.Time, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fetchedAt.IsZero() {
+		return GasPrices{}, time.Time{}, false
+	}
+	return r.prices.copy(), r.fetchedAt, true
+}
+
+// Stop stops refreshing prices and waits for an in-flight refresh to return. The last prices are still served. Stop is
+// idempotent.
+func (r *Refresher) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+}
+
+func (r *Refresher) run(parent context.Context, refreshNow bool) {
+	defer close(r.done)
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	closed := r.client.closedChan()
+	go func() {
+		select {
+		case <-r.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if refreshNow && errors.Is(r.refresh(ctx), ErrClientClosed) {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-closed:
+			return
+		case <-ticker.C:
+			if errors.Is(r.refresh(ctx), ErrClientClosed) {
+				return
+			}
+		}
+	}
+}
+
+func (r *Refresher) refresh(ctx context.Context) error {
+	r.client.emit(Event{Type: EventRefresh})
+	prices, err := r.client.fetch(contextWithBackgroundRefresh(ctx))
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prices = prices
+	r.fetchedAt = r.client.clock()
+	return nil
+}
@@ -0,0 +1,152 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefresher(t *testing.T) {
+	var calls int32
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(int64(atomic.AddInt32(&calls, 1)))}, nil
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	// 1. prices are loaded when warming on start
+	r, err := c.NewRefresher(context.Background(), 20*time.Millisecond, WithWarmOnStart(true))
+	require.NoError(t, err)
+	price, err := r.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "1", price.String())
+
+	// 2. prices are refreshed in the background
+	time.Sleep(50 * time.Millisecond)
+	price, err = r.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.True(t, price.Int64() > 1)
+
+	// 3. refreshes stop once stopped
+	r.Stop()
+	r.Stop()
+	stopped := atomic.LoadInt32(&calls)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&calls))
+
+	// 4. invalid intervals are rejected
+	_, err = c.NewRefresher(context.Background(), 0)
+	assert.Error(t, err)
+}
+
+func TestRefresherContextValues(t *testing.T) {
+	requestIDs := make(chan interface{}, 1)
+	provider := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		select {
+		case requestIDs <- ctx.Value(requestIDKey{}):
+		default:
+		}
+		return GasPrices{Fast: big.NewInt(20e9)}, nil
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	// background refreshes carry the values of ctx, even once it is done
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestIDKey{}, "req-1"))
+	cancel()
+	r, err := c.NewRefresher(ctx, time.Hour)
+	require.NoError(t, err)
+	defer r.Stop()
+	assert.Equal(t, "req-1", <-requestIDs)
+	time.Sleep(10 * time.Millisecond)
+	_, _, ok := r.Prices()
+	assert.True(t, ok)
+}
+
+func TestRefresherWarmOnStart(t *testing.T) {
+	release := make(chan struct{})
+	provider := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		select {
+		case <-release:
+			return GasPrices{Fast: big.NewInt(20e9)}, nil
+		case <-ctx.Done():
+			return GasPrices{}, &FetchError{Err: ctx.Err()}
+		}
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	// 1. warming respects the deadline of the context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.NewRefresher(ctx, time.Minute, WithWarmOnStart(true))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// 2. without warming, reads fail until the first refresh completes
+	r, err := c.NewRefresher(context.Background(), time.Minute)
+	require.NoError(t, err)
+	defer r.Stop()
+	_, err = r.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+	price, err := r.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+
+	// 3. the refresher stops when the client is closed
+	require.NoError(t, c.Close())
+	<-r.done
+}
+
+func TestWithRefreshTimeout(t *testing.T) {
+	deadlines := make(chan time.Duration, 10)
+	provider := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		deadline, _ := ctx.Deadline()
+		deadlines <- time.Until(deadline)
+		return GasPrices{Fast: big.NewInt(20e9)}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithTimeout(time.Second), WithRefreshTimeout(time.Hour))
+	require.NoError(t, err)
+
+	// 1. calls use the per-call timeout
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.True(t, <-deadlines <= time.Second)
+
+	// 2. refreshes use the refresh timeout
+	r, err := c.NewRefresher(context.Background(), time.Hour, WithWarmOnStart(true))
+	require.NoError(t, err)
+	defer r.Stop()
+	assert.True(t, <-deadlines > time.Minute)
+
+	// 3. so do the background refreshes of a cache serving stale prices
+	now := time.Now()
+	c, err = NewClient(
+		WithProvider(provider),
+		WithTimeout(time.Second),
+		WithRefreshTimeout(time.Hour),
+		WithMaxResultAge(time.Minute),
+		WithMaxStaleness(time.Hour),
+		WithNowFunc(func() time.Time { return now }),
+	)
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.True(t, <-deadlines <= time.Second)
+	now = now.Add(2 * time.Minute)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.True(t, <-deadlines > time.Minute)
+
+	// 4. the refresh timeout must be positive
+	_, err = NewClient(WithRefreshTimeout(0))
+	assert.Error(t, err)
+}
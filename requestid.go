@@ -0,0 +1,40 @@
+package gas
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+)
+
+type requestIDContextKey struct{}
+
+// RequestIDFromContext returns the request ID of the fetch that made a request to the provider, as generated by the
+// function set with WithRequestIDGenerator, from the context of the request. It is shared by every retry of the
+// fetch, so it correlates the requests passed to the logger of WithRequestContextLogger, the responses passed to the
+// hook of WithResponseHook and the events of Client.Events that belong to the same fetch.
+func RequestIDFromContext(ctx context.Context) (id string, ok bool) {
+	id, ok = ctx.Value(requestIDContextKey{}).(string)
+	return id, ok
+}
+
+func contextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// requestID generates the request ID of a fetch, with a shared counter for a client not created by NewClient
+func (c *Client) requestID() string {
+	if c.requestIDs == nil {
+		return defaultRequestIDs()
+	}
+	return c.requestIDs()
+}
+
+var defaultRequestIDs = newRequestIDCounter()
+
+// newRequestIDCounter returns the default request ID generator of a client, numbering its fetches from 1
+func newRequestIDCounter() func() string {
+	var n uint64
+	return func() string {
+		return strconv.FormatUint(atomic.AddUint64(&n, 1), 10)
+	}
+}
@@ -0,0 +1,70 @@
+package gas
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestIDGenerator(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		logged []string
+	)
+	logger := func(ctx context.Context, _, _ string) {
+		id, _ := RequestIDFromContext(ctx)
+		mu.Lock()
+		defer mu.Unlock()
+		logged = append(logged, id)
+	}
+	var calls int32
+	failure := &FetchError{StatusCode: http.StatusServiceUnavailable}
+	c, err := NewClient(WithProvider(countingProvider(1, failure, &calls)), WithRetry(2, time.Millisecond),
+		WithEvents(10))
+	require.NoError(t, err)
+
+	// 1. by default fetches are numbered, and every attempt of a fetch shares its ID
+	for i := 0; i < 2; i++ {
+		_, err = c.SuggestGasPrice(GasPriorityFast)
+		require.NoError(t, err)
+	}
+	var ids []string
+	for _, event := range drainEvents(c.Events()) {
+		ids = append(ids, event.RequestID)
+	}
+	assert.Equal(t, []string{"1", "1", "1", "1", "2", "2"}, ids)
+
+	// 2. the generated ID is passed to the request logger of each request
+	next := 0
+	generate := func() string {
+		next++
+		return "fetch-" + strconv.Itoa(next)
+	}
+	status := http.StatusServiceUnavailable
+	c, stop := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			status = http.StatusOK
+			return
+		}
+		serveTestResponse(w, r)
+	}, WithRequestIDGenerator(generate), WithRequestContextLogger(logger), WithRetry(1, time.Millisecond))
+	defer stop()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fetch-1", "fetch-1", "fetch-2"}, logged)
+
+	// 3. a context without a fetch has no request ID, and the generator must not be nil
+	_, ok := RequestIDFromContext(context.Background())
+	assert.False(t, ok)
+	_, err = NewClient(WithRequestIDGenerator(nil))
+	assert.Error(t, err)
+}
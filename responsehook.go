@@ -0,0 +1,59 @@
+package gas
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// HookResponses returns a RoundTripper that calls hook with each response received by next, or http.DefaultTransport
+// if next is nil, before it is returned to the caller. Use it in the HTTP client of any provider, or WithResponseHook
+// for the default provider.
+//
+// The hook is given a copy of the response with its own headers and no body, so it can read headers such as
+// X-RateLimit-Remaining or Cache-Control without interfering with the body, which is still read and closed by the
+// caller. The request of the copy only has the method, the URL redacted by RedactURL and the context of the request,
+// from which RequestIDFromContext returns the request ID of the fetch, as the URL and headers may carry an API key.
+// The hook is called for every response, whatever its status code, but not for requests that fail without a response.
+func HookResponses(next http.RoundTripper, hook func(*http.Response)) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &hookTransport{next: next, hook: hook}
+}
+
+type hookTransport struct {
+	next http.RoundTripper
+	hook func(*http.Response)
+}
+
+func (t *hookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+	headers := *res
+	headers.Header = res.Header.Clone()
+	headers.Trailer = nil
+	headers.Body = http.NoBody
+	headers.Request = redactedRequest(req)
+	headers.TLS = nil
+	t.hook(&headers)
+	return res, nil
+}
+
+// redactedRequest returns a request with the method, redacted URL and context of req
+func redactedRequest(req *http.Request) *http.Request {
+	redacted := (&http.Request{Method: req.Method, Header: make(http.Header)}).WithContext(req.Context())
+	if u, err := url.Parse(RedactURL(req.URL.String())); err == nil {
+		redacted.URL = u
+		redacted.Host = u.Host
+	}
+	return redacted
+}
+
+// CloseIdleConnections closes idle connections of the underlying transport, if it supports it.
+func (t *hookTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(idleConnectionCloser); ok {
+		closer.CloseIdleConnections()
+	}
+}
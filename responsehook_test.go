@@ -0,0 +1,53 @@
+package gas
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResponseHook(t *testing.T) {
+	var responses []*http.Response
+	hook := func(res *http.Response) {
+		responses = append(responses, res)
+	}
+	status := http.StatusTooManyRequests
+	c, stop := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "41")
+		w.Header().Set("Cache-Control", "max-age=10")
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		serveTestResponse(w, r)
+	}, WithResponseHook(hook), WithAPIKey("secret"))
+	defer stop()
+
+	// 1. the hook sees the headers of responses with an error status
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+	require.Len(t, responses, 1)
+	assert.Equal(t, http.StatusTooManyRequests, responses[0].StatusCode)
+	assert.Equal(t, "41", responses[0].Header.Get("X-RateLimit-Remaining"))
+
+	// 2. the body is still decoded, and the hook's copy neither carries the body nor the key of the request, only its
+	// redacted URL and request ID
+	status = http.StatusOK
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	require.Len(t, responses, 2)
+	assert.Equal(t, "max-age=10", responses[1].Header.Get("Cache-Control"))
+	assert.Equal(t, http.NoBody, responses[1].Body)
+	assert.NotContains(t, responses[1].Request.URL.String(), "secret")
+	assert.Empty(t, responses[1].Request.Header)
+	id, ok := RequestIDFromContext(responses[1].Request.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "2", id)
+
+	// 3. a nil hook is rejected
+	_, err = NewClient(WithResponseHook(nil))
+	assert.Error(t, err)
+}
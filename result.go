@@ -0,0 +1,92 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"time"
+)
+
+const (
+	// ResultSourceCache is the Source of a Result served from the cache of the client.
+	ResultSourceCache = "cache"
+
+	// ResultSourceProvider is the Source of a Result fetched from the provider by the call that returned it.
+	ResultSourceProvider = "provider"
+
+	// ResultSourceLastResort is the Source of a Result computed by the function set with WithLastResort.
+	ResultSourceLastResort = "last_resort"
+)
+
+// Result is a suggested gas price along with how it was obtained, as returned by Client.SuggestDetailed.
+type Result struct {
+	// Price is the gas price in wei, it may be modified freely.
+	Price *big.Int
+
+	// Stale is set if the price was served from the cache after its max result age, while it is refreshed in the
+	// background, as configured with WithMaxStaleness or WithAsyncRefresh.
+	Stale bool
+
+	// FetchedAt is when the prices the price was taken from were fetched from the provider.
+	FetchedAt time.Time
+
+	// Source is ResultSourceCache, ResultSourceProvider or ResultSourceLastResort.
+	Source string
+
+	// Raw is the price as the number the provider returned, before conversion to wei and any transform or rounding
+	// configured on the client, such as "1205" for 120.5 gwei in tenths of gwei. It is empty if the provider does not
+	// report it, see GasPrices.Raw.
+	Raw string
+
+	// Spread and SpreadRatio are the spread between the fastest and safeLow prices the price was taken from, as
+	// returned by GasPrices.Spread. They are nil if the spread is not available.
+	Spread      *big.Int
+	SpreadRatio *big.Float
+}
+
+// SuggestDetailed is like SuggestGasPrice, but returns the price in a Result that tells whether it is stale, when it
+// was fetched and whether it was served from the cache. A client configured with WithPerPriorityCache serves it from
+// the whole-response cache.
+func (c *Client) SuggestDetailed(priority GasPriority) (Result, error) {
+	var read cacheRead
+	lastResort := false
+	if c.cache != nil {
+		var err error
+		if read, err = c.cache.read(context.Background()); err != nil {
+			if read.prices, err = c.lastResortOr(context.Background(), err); err != nil {
+				return Result{}, err
+			}
+			read.fetchedAt, lastResort = time.Now(), true
+		}
+	} else {
+		prices, err := c.fetch(context.Background())
+		if err != nil {
+			if prices, err = c.lastResortOr(context.Background(), err); err != nil {
+				return Result{}, err
+			}
+			lastResort = true
+		}
+		read = cacheRead{prices: prices, fetchedAt: time.Now()}
+	}
+
+	price, err := read.prices.Price(priority)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{
+		Price:     price,
+		Stale:     read.stale,
+		FetchedAt: read.fetchedAt,
+		Source:    ResultSourceProvider,
+		Raw:       read.prices.Raw[priority],
+	}
+	if spread, ratio, err := read.prices.Spread(); err == nil {
+		result.Spread, result.SpreadRatio = spread, ratio
+	}
+	switch {
+	case lastResort:
+		result.Source = ResultSourceLastResort
+	case read.cached:
+		result.Source = ResultSourceCache
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package gas
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestDetailed(t *testing.T) {
+	var calls int32
+	now := time.Now()
+	c, err := NewClient(
+		WithProvider(countingProvider(0, nil, &calls)),
+		WithMaxResultAge(time.Minute),
+		WithMaxStaleness(time.Hour),
+		WithNowFunc(func() time.Time { return now }),
+	)
+	require.NoError(t, err)
+
+	// 1. the first call fetches from the provider
+	result, err := c.SuggestDetailed(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", result.Price.String())
+	assert.Equal(t, ResultSourceProvider, result.Source)
+	assert.False(t, result.Stale)
+	assert.True(t, result.FetchedAt.Equal(now))
+
+	// 2. fresh prices are served from the cache
+	result, err = c.SuggestDetailed(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, ResultSourceCache, result.Source)
+	assert.False(t, result.Stale)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 3. prices past their max result age are reported stale
+	fetchedAt := now
+	now = now.Add(2 * time.Minute)
+	result, err = c.SuggestDetailed(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, ResultSourceCache, result.Source)
+	assert.True(t, result.Stale)
+	assert.True(t, result.FetchedAt.Equal(fetchedAt))
+
+	// 4. a client without a cache always fetches, and missing levels are errors
+	c, err = NewClient(WithProvider(countingProvider(0, nil, new(int32))))
+	require.NoError(t, err)
+	_, err = c.SuggestDetailed(GasPrioritySafeLow)
+	assert.Error(t, err)
+	result, err = c.SuggestDetailed(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, ResultSourceProvider, result.Source)
+	assert.False(t, result.FetchedAt.IsZero())
+}
+
+func TestSuggestDetailedRaw(t *testing.T) {
+	c, stop := newTestClient(t, serveTestResponse, WithRoundTo(3))
+	defer stop()
+
+	// 1. the raw value of the provider is reported along with the converted and rounded price
+	result, err := c.SuggestDetailed(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "200.0", result.Raw)
+	assert.Equal(t, "21000000000", result.Price.String())
+
+	// 2. providers that don't report raw values leave it empty
+	c, err = NewClient(WithProvider(countingProvider(0, nil, new(int32))))
+	require.NoError(t, err)
+	result, err = c.SuggestDetailed(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Empty(t, result.Raw)
+}
@@ -0,0 +1,184 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// RetryableStatusCodes returns a function for use with WithRetryableStatus that considers only the given status codes
+// retryable.
+func RetryableStatusCodes(codes ...int) func(status int) bool {
+	retryable := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		retryable[code] = true
+	}
+	return func(status int) bool {
+		return retryable[status]
+	}
+}
+
+// defaultRetryableStatus considers rate limiting and server errors transient
+func defaultRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// fetchWithRetry loads prices from the provider, retrying transient failures as configured on the client
+func (c *Client) fetchWithRetry(ctx context.Context) (GasPrices, error) {
+	fetch := c.source().Fetch
+	if c.freeFallback && c.customProvider() == nil && c.usesKey() {
+		fetch = c.withFreeEndpointFallback(fetch)
+	}
+	return c.retry(ctx, fetch)
+}
+
+// retry calls fetch with the client's timeout, retrying transient failures as configured on the client
+func (c *Client) retry(ctx context.Context, fetch func(context.Context) (GasPrices, error)) (GasPrices, error) {
+	if c.maxRetryElapsed > 0 {
+		// the budget applies on top of the deadline of ctx, whichever ends first
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.maxRetryElapsed)
+		defer cancel()
+	}
+	requestID := c.requestID()
+	ctx = contextWithRequestID(ctx, requestID)
+	attempts := 0
+	attempt := func(ctx context.Context) (GasPrices, error) {
+		release, err := c.acquireFetchSlot(ctx)
+		if err != nil {
+			return GasPrices{}, err
+		}
+		defer release()
+
+		attemptCtx, cancel := c.withTimeout(ctx)
+		defer cancel()
+		if c.trace != nil {
+			attemptCtx = httptrace.WithClientTrace(attemptCtx, c.trace)
+		}
+		attempts++
+		c.emit(Event{Type: EventFetchStarted, Attempt: attempts, RequestID: requestID})
+		start := time.Now()
+		prices, err := fetch(attemptCtx)
+		if err == nil && c.retryInvalid {
+			err = c.checkAttempt(prices)
+		}
+		if err != nil && c.errorTiming {
+			err = withTiming(err, attempts, time.Since(start))
+		}
+		if err != nil {
+			c.emit(Event{Type: EventFetchFailed, Attempt: attempts, RequestID: requestID, Err: err})
+			return prices, err
+		}
+		c.emit(Event{Type: EventFetchSucceeded, Attempt: attempts, RequestID: requestID, Prices: prices})
+		if c.quota != nil {
+			c.quota.record()
+		}
+		return prices, nil
+	}
+
+	retryableStatus := c.retryableStatus
+	if retryableStatus == nil {
+		retryableStatus = defaultRetryableStatus
+	}
+	backoff := c.backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(c.retryBackoff)
+	}
+	return fetchWithRetries(ctx, attempt, c.retries, backoff, retryableStatus)
+}
+
+// checkAttempt validates the prices of an attempt as they would be once loaded, returning a retryable *FetchError if
+// they are rejected
+func (c *Client) checkAttempt(prices GasPrices) error {
+	err := c.validate(prices)
+	if err == nil && c.rejectImplausible {
+		// prices that are only warned about are warned about once, when they are loaded
+		err = c.checkPlausible(prices)
+	}
+	if err != nil {
+		return &FetchError{Err: err}
+	}
+	return nil
+}
+
+// withTiming returns a copy of err with the attempt and how long it took set, if it is a *FetchError, which may be
+// shared by several calls
+func withTiming(err error, attempt int, duration time.Duration) error {
+	fetchErr, ok := err.(*FetchError)
+	if !ok {
+		return err
+	}
+	timed := *fetchErr
+	timed.Attempt, timed.Duration = attempt, duration
+	return &timed
+}
+
+// acquireFetchSlot waits until a request may be made under the limit set with WithMaxConcurrentFetches, or until ctx is
+// done. The returned function releases the slot once the request is done.
+func (c *Client) acquireFetchSlot(ctx context.Context) (func(), error) {
+	if c.fetchSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.fetchSlots <- struct{}{}:
+		return func() { <-c.fetchSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchWithRetries calls fetch until it succeeds, fails with an error that isn't retryable, or has been retried
+// retries times, waiting as determined by backoff between attempts. It makes a single attempt if ctx is in fail fast
+// mode, and returns the last error without waiting if ctx would be done before the next attempt.
+func fetchWithRetries(
+	ctx context.Context,
+	fetch func(context.Context) (GasPrices, error),
+	retries int,
+	backoff Backoff,
+	retryableStatus func(int) bool,
+) (GasPrices, error) {
+	if isFailFast(ctx) {
+		return fetch(ctx)
+	}
+	for attempt := 0; ; attempt++ {
+		prices, err := fetch(ctx)
+		if err == nil || attempt >= retries || !retryable(err, retryableStatus) {
+			return prices, err
+		}
+
+		wait := backoff.NextDelay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return prices, err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return prices, err
+		case <-timer.C:
+		}
+	}
+}
+
+func retryable(err error, retryableStatus func(int) bool) bool {
+	canceled := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		// a resolver that failed or timed out, such as with SERVFAIL, may answer on retry, while a host that doesn't
+		// exist won't, whether or not a custom provider wrapped the error in a *FetchError
+		return (dnsErr.IsTemporary || dnsErr.IsTimeout) && !canceled
+	}
+
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		return false
+	}
+	if fetchErr.StatusCode == 0 {
+		// the request failed without a response, which is only worth retrying if it wasn't canceled
+		return !canceled
+	}
+	return retryableStatus(fetchErr.StatusCode)
+}
@@ -0,0 +1,318 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingHandler responds with status for the first failures requests, and with a valid response afterwards
+func failingHandler(status int, failures int32, requests *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(requests, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		serveTestResponse(w, r)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	// 1. transient status codes are retried until the request succeeds
+	var requests int32
+	c, stop := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 2, &requests), WithRetry(2, time.Millisecond))
+	defer stop()
+
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// 2. the last error is returned once retries are exhausted
+	requests = 0
+	c, stop = newTestClient(t, failingHandler(http.StatusTooManyRequests, 5, &requests), WithRetry(1, time.Millisecond))
+	defer stop()
+
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusTooManyRequests, fetchErr.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// 3. other status codes are not retried by default
+	requests = 0
+	c, stop = newTestClient(t, failingHandler(http.StatusForbidden, 1, &requests), WithRetry(3, time.Millisecond))
+	defer stop()
+
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestWithRetryableStatus(t *testing.T) {
+	// 1. overridden status codes are retried
+	var requests int32
+	c, stop := newTestClient(t, failingHandler(http.StatusForbidden, 1, &requests),
+		WithRetry(3, time.Millisecond),
+		WithRetryableStatus(RetryableStatusCodes(http.StatusForbidden)),
+	)
+	defer stop()
+
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// 2. default retryable status codes are no longer retried
+	requests = 0
+	c, stop = newTestClient(t, failingHandler(http.StatusServiceUnavailable, 1, &requests),
+		WithRetry(3, time.Millisecond),
+		WithRetryableStatus(RetryableStatusCodes(http.StatusForbidden)),
+	)
+	defer stop()
+
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// 3. nil functions are rejected
+	_, err = NewClient(WithRetryableStatus(nil))
+	assert.Error(t, err)
+}
+
+func TestWithMaxRetryElapsed(t *testing.T) {
+	// 1. retries stop once the next backoff would exceed the budget, returning the last error
+	var requests int32
+	c, stop := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 10, &requests),
+		WithRetry(10, 20*time.Millisecond),
+		WithMaxRetryElapsed(100*time.Millisecond),
+	)
+	defer stop()
+
+	start := time.Now()
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, http.StatusServiceUnavailable, fetchErr.StatusCode)
+	assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	// attempts are made after waiting 0, 20 and 60ms, the next would be after 140ms
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// 2. calls that succeed within the budget are unaffected
+	requests = 0
+	c, stop = newTestClient(t, failingHandler(http.StatusServiceUnavailable, 1, &requests),
+		WithRetry(10, time.Millisecond),
+		WithMaxRetryElapsed(time.Second),
+	)
+	defer stop()
+
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// 3. negative budgets are rejected
+	_, err = NewClient(WithMaxRetryElapsed(-time.Second))
+	assert.Error(t, err)
+}
+
+func TestWithMaxConcurrentFetches(t *testing.T) {
+	var inFlight, peak int32
+	release := make(chan struct{})
+	provider := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			current := atomic.LoadInt32(&peak)
+			if n <= current || atomic.CompareAndSwapInt32(&peak, current, n) {
+				break
+			}
+		}
+		<-release
+		return GasPrices{Fast: big.NewInt(1)}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxConcurrentFetches(2))
+	require.NoError(t, err)
+
+	// 1. no more than n requests are in flight at once
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.SuggestGasPrice(GasPriorityFast)
+			assert.NoError(t, err)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&inFlight))
+
+	// 2. waiting calls give up once their context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.SuggestGasPriceContext(ctx, GasPriorityFast)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+
+	// 3. the limit must be positive
+	_, err = NewClient(WithMaxConcurrentFetches(0))
+	assert.Error(t, err)
+}
+
+func TestWithRetryInvalidPrices(t *testing.T) {
+	var calls int32
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return GasPrices{Fast: new(big.Int)}, nil
+		}
+		return GasPrices{Fast: big.NewInt(20e9)}, nil
+	})
+
+	// 1. invalid prices are retried like a failed request
+	c, err := NewClient(WithProvider(provider), WithRejectZeroPrices(), WithRetryInvalidPrices(),
+		WithRetry(1, time.Millisecond))
+	require.NoError(t, err)
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, int32(2), calls)
+
+	// 2. once retries are exhausted, the rejection is returned as a fetch error
+	atomic.StoreInt32(&calls, 0)
+	c, err = NewClient(WithProvider(provider), WithRejectZeroPrices(), WithRetryInvalidPrices())
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	var fetchErr *FetchError
+	assert.True(t, errors.As(err, &fetchErr))
+	assert.True(t, errors.Is(err, errZeroPrice))
+
+	// 3. without the option, invalid prices are not retried
+	atomic.StoreInt32(&calls, 0)
+	c, err = NewClient(WithProvider(provider), WithRejectZeroPrices(), WithRetry(1, time.Millisecond))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, errZeroPrice))
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestWithErrorTiming(t *testing.T) {
+	shared := &FetchError{StatusCode: http.StatusServiceUnavailable}
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		time.Sleep(10 * time.Millisecond)
+		return GasPrices{}, shared
+	})
+
+	// 1. the failed attempt and how long it took are set on the error, and added to its message
+	c, err := NewClient(WithProvider(provider), WithRetry(1, time.Millisecond), WithErrorTiming())
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Equal(t, 2, fetchErr.Attempt)
+	assert.GreaterOrEqual(t, int64(fetchErr.Duration), int64(10*time.Millisecond))
+	assert.Contains(t, err.Error(), "(attempt 2 failed after ")
+	assert.Equal(t, 0, shared.Attempt, "errors shared by calls are copied")
+
+	// 2. without the option, errors are unchanged
+	c, err = NewClient(WithProvider(provider))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Equal(t, shared, err)
+	assert.Equal(t, "eth: unexpected response status: 503 Service Unavailable", err.Error())
+}
+
+func TestTruncatedResponseRetried(t *testing.T) {
+	var requests int32
+	c, stop := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			_, _ = w.Write([]byte(testResponse[:len(testResponse)/2]))
+			return
+		}
+		serveTestResponse(w, r)
+	}, WithRetry(1, time.Millisecond))
+	defer stop()
+
+	// 1. a truncated body is a retryable fetch error
+	_, err := c.source().Fetch(context.Background())
+	var fetchErr *FetchError
+	require.True(t, errors.As(err, &fetchErr))
+	assert.Zero(t, fetchErr.StatusCode)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.True(t, retryable(err, defaultRetryableStatus))
+
+	// 2. the client retries it
+	requests = 0
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// 3. a complete body that isn't valid is not retryable
+	assert.False(t, errors.As(decodeResponse(strings.NewReader(`{"fast": "foo"}`), &ethGasStationResponse{}), &fetchErr))
+	assert.False(t, errors.As(decodeResponse(strings.NewReader(`{"fast": ]`), &ethGasStationResponse{}), &fetchErr))
+}
+
+func TestDNSErrorsRetried(t *testing.T) {
+	var lookups int32
+	dnsErr := &net.DNSError{Err: "server misbehaving", Name: "gas.example", IsTemporary: true}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if atomic.AddInt32(&lookups, 1) == 1 {
+			return nil, dnsErr
+		}
+		return dial(ctx, network, addr)
+	}
+	server := httptest.NewServer(http.HandlerFunc(serveTestResponse))
+	defer server.Close()
+	c, err := NewClient(WithURL(server.URL), WithRetry(1, time.Millisecond),
+		WithHTTPClient(&http.Client{Transport: transport}))
+	require.NoError(t, err)
+
+	// 1. a temporary resolver failure is retried
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+
+	// 2. so are resolver timeouts, even if a custom provider didn't wrap them in a *FetchError
+	assert.True(t, retryable(&net.DNSError{Err: "i/o timeout", IsTimeout: true}, defaultRetryableStatus))
+
+	// 3. a host that doesn't exist is not retried
+	assert.False(t, retryable(&FetchError{Err: &net.DNSError{Err: "no such host", IsNotFound: true}},
+		defaultRetryableStatus))
+}
+
+func TestWithClientTrace(t *testing.T) {
+	// 1. every attempt, including retries, is traced
+	var requests, traced int32
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) { atomic.AddInt32(&traced, 1) },
+	}
+	c, stop := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 1, &requests),
+		WithRetry(1, time.Millisecond), WithClientTrace(trace))
+	defer stop()
+
+	_, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&traced))
+
+	// 2. a nil trace is rejected
+	_, err = NewClient(WithClientTrace(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package gas
+
+import "math/big"
+
+// RoundingMode determines how prices are rounded to the grid set with WithRoundTo.
+type RoundingMode int
+
+const (
+	// RoundNearest rounds to the nearest multiple of the grid, rounding halfway values up. It is the default.
+	RoundNearest RoundingMode = iota
+
+	// RoundUp rounds up to the next multiple of the grid.
+	RoundUp
+
+	// RoundDown rounds down to the previous multiple of the grid.
+	RoundDown
+)
+
+func (m RoundingMode) valid() bool {
+	return m == RoundNearest || m == RoundUp || m == RoundDown
+}
+
+// roundPrices rounds every price and fee to a multiple of step using mode, the base fee is left as reported
+func roundPrices(prices GasPrices, step *big.Int, mode RoundingMode) GasPrices {
+	prices.Fast = roundTo(prices.Fast, step, mode)
+	prices.Fastest = roundTo(prices.Fastest, step, mode)
+	prices.SafeLow = roundTo(prices.SafeLow, step, mode)
+	prices.Average = roundTo(prices.Average, step, mode)
+
+	if prices.Predictions != nil {
+		predictions := make([]PricePrediction, len(prices.Predictions))
+		for i, prediction := range prices.Predictions {
+			predictions[i] = PricePrediction{Price: roundTo(prediction.Price, step, mode), Wait: prediction.Wait}
+		}
+		prices.Predictions = predictions
+	}
+	if prices.Fees != nil {
+		fees := make(map[GasPriority]FeeSuggestion, len(prices.Fees))
+		for priority, fee := range prices.Fees {
+			fees[priority] = FeeSuggestion{
+				MaxFeePerGas:         roundTo(fee.MaxFeePerGas, step, mode),
+				MaxPriorityFeePerGas: roundTo(fee.MaxPriorityFeePerGas, step, mode),
+			}
+		}
+		prices.Fees = fees
+	}
+	return prices
+}
+
+// roundTo returns price rounded to a multiple of step, a positive price is never rounded down to zero
+func roundTo(price, step *big.Int, mode RoundingMode) *big.Int {
+	if price == nil {
+		return nil
+	}
+
+	quotient, remainder := new(big.Int).DivMod(price, step, new(big.Int))
+	if remainder.Sign() != 0 {
+		switch mode {
+		case RoundUp:
+			quotient.Add(quotient, big.NewInt(1))
+		case RoundNearest:
+			if new(big.Int).Lsh(remainder, 1).Cmp(step) >= 0 {
+				quotient.Add(quotient, big.NewInt(1))
+			}
+		}
+	}
+	if quotient.Sign() == 0 && price.Sign() > 0 {
+		quotient.SetInt64(1)
+	}
+	return quotient.Mul(quotient, step)
+}
@@ -0,0 +1,69 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTo(t *testing.T) {
+	step := big.NewInt(5)
+	cases := []struct {
+		price    int64
+		mode     RoundingMode
+		expected int64
+	}{
+		{12, RoundNearest, 10},
+		{13, RoundNearest, 15},
+		{12, RoundUp, 15},
+		{14, RoundDown, 10},
+		{15, RoundUp, 15},
+		{15, RoundDown, 15},
+		{1, RoundDown, 5},
+		{2, RoundNearest, 5},
+		{0, RoundUp, 0},
+	}
+
+	for _, c := range cases {
+		rounded := roundTo(big.NewInt(c.price), step, c.mode)
+		assert.Equal(t, c.expected, rounded.Int64(), "price %d with mode %d", c.price, c.mode)
+	}
+	assert.Nil(t, roundTo(nil, step, RoundNearest))
+}
+
+func TestWithRoundTo(t *testing.T) {
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{
+			Fast:        big.NewInt(21300000000),
+			SafeLow:     big.NewInt(10500000000),
+			Predictions: []PricePrediction{{Price: big.NewInt(11200000000)}},
+		}, nil
+	})
+
+	// 1. prices are rounded to the nearest gwei by default
+	c, err := NewClient(WithProvider(provider), WithRoundTo(1))
+	require.NoError(t, err)
+
+	prices, err := c.fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "21000000000", prices.Fast.String())
+	assert.Equal(t, "11000000000", prices.SafeLow.String())
+	assert.Equal(t, "11000000000", prices.Predictions[0].Price.String())
+
+	// 2. the rounding mode is configurable
+	c, err = NewClient(WithProvider(provider), WithRoundTo(5), WithRoundingMode(RoundUp))
+	require.NoError(t, err)
+
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "25000000000", price.String())
+
+	// 3. invalid options are rejected
+	_, err = NewClient(WithRoundTo(0))
+	assert.Error(t, err)
+	_, err = NewClient(WithRoundingMode(RoundingMode(42)))
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package gas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// callRPC calls a JSON-RPC method of the node at endpoint, and decodes its result into result
+func callRPC(
+	ctx context.Context,
+	client *http.Client,
+	endpoint, method string,
+	params []interface{},
+	result interface{},
+) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return &FetchError{Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return &FetchError{StatusCode: res.StatusCode}
+	}
+
+	var response rpcResponse
+	if err := decodeResponse(res.Body, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return errors.New("eth: rpc error: " + response.Error.Message)
+	}
+	if len(response.Result) == 0 || string(response.Result) == "null" {
+		return errors.New("eth: no result in rpc response")
+	}
+	return json.Unmarshal(response.Result, result)
+}
@@ -0,0 +1,74 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"time"
+)
+
+// Savings is the difference in cost and wait time of a transaction between two priority levels.
+type Savings struct {
+	// Wei is how much less the transaction costs at the cheaper level, in wei. It is negative if the level chosen
+	// instead is more expensive.
+	Wei *big.Int
+
+	// ExtraWait is how much longer the transaction is expected to take at the cheaper level. It is zero if the provider
+	// does not report wait times for both levels.
+	ExtraWait time.Duration
+
+	// tokenDecimals is the number of decimals of the native token Wei is in, if it isn't 18
+	tokenDecimals *int
+}
+
+// Fiat returns the savings in a fiat currency, given the price of one ether in that currency, or of one whole native
+// token on chains that pay gas in another token. The savings of Client.EstimateSavings are converted with the decimals
+// of the native token of the client.
+func (s Savings) Fiat(etherPrice *big.Rat) *big.Rat {
+	decimals := DefaultNativeTokenDecimals
+	if s.tokenDecimals != nil {
+		decimals = *s.tokenDecimals
+	}
+	ether := scaleDecimals(s.Wei, decimals)
+	return ether.Mul(ether, etherPrice)
+}
+
+// EstimateSavings returns how much a transaction using gasLimit gas saves by being priced at priority to instead of
+// priority from, and how much longer it is expected to wait, for prompts such as "save 0.003 ETH by waiting 4 more
+// minutes".
+func (p GasPrices) EstimateSavings(from, to GasPriority, gasLimit uint64) (Savings, error) {
+	fromPrice, err := p.price(from)
+	if err != nil {
+		return Savings{}, err
+	}
+	toPrice, err := p.price(to)
+	if err != nil {
+		return Savings{}, err
+	}
+
+	savings := Savings{Wei: new(big.Int).Sub(fromPrice, toPrice)}
+	savings.Wei.Mul(savings.Wei, new(big.Int).SetUint64(gasLimit))
+
+	fromWait, fromOK := p.Waits[from]
+	toWait, toOK := p.Waits[to]
+	if fromOK && toOK {
+		savings.ExtraWait = toWait - fromWait
+	}
+	return savings, nil
+}
+
+// EstimateSavings is like GasPrices.EstimateSavings, using the prices of a single response. Unless the client was
+// configured with WithMaxResultAge, it always makes a new call to the provider.
+func (c *Client) EstimateSavings(from, to GasPriority, gasLimit uint64) (Savings, error) {
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return Savings{}, err
+	}
+	savings, err := prices.EstimateSavings(from, to, gasLimit)
+	if err != nil {
+		return Savings{}, err
+	}
+	if decimals := c.NativeTokenDecimals(); decimals != DefaultNativeTokenDecimals {
+		savings.tokenDecimals = &decimals
+	}
+	return savings, nil
+}
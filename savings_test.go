@@ -0,0 +1,50 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasPricesEstimateSavings(t *testing.T) {
+	prices := GasPrices{
+		Fast:    big.NewInt(30e9),
+		SafeLow: big.NewInt(20e9),
+		Average: big.NewInt(25e9),
+		Waits: map[GasPriority]time.Duration{
+			GasPriorityFast:    time.Minute,
+			GasPrioritySafeLow: 5 * time.Minute,
+		},
+	}
+
+	// 1. the saving is the price difference times the gas limit, with the extra wait
+	savings, err := prices.EstimateSavings(GasPriorityFast, GasPrioritySafeLow, 21000)
+	require.NoError(t, err)
+	assert.Equal(t, "210000000000000", savings.Wei.String())
+	assert.Equal(t, 4*time.Minute, savings.ExtraWait)
+
+	// 2. the saving can be converted to a fiat currency
+	assert.Equal(t, "21/50", savings.Fiat(big.NewRat(2000, 1)).String())
+
+	// 3. a more expensive level is a negative saving, and unknown waits are zero
+	savings, err = prices.EstimateSavings(GasPrioritySafeLow, GasPriorityAverage, 21000)
+	require.NoError(t, err)
+	assert.Equal(t, "-105000000000000", savings.Wei.String())
+	assert.Zero(t, savings.ExtraWait)
+
+	// 4. missing prices are an error
+	_, err = prices.EstimateSavings(GasPriorityFastest, GasPrioritySafeLow, 21000)
+	assert.Error(t, err)
+}
+
+func TestClientEstimateSavings(t *testing.T) {
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+
+	savings, err := c.EstimateSavings(GasPriorityFastest, GasPrioritySafeLow, 100000)
+	require.NoError(t, err)
+	assert.Equal(t, "1500000000000000", savings.Wei.String())
+}
@@ -0,0 +1,119 @@
+package gas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrSchemaDrift is wrapped by the error of a provider with StrictSchema set when a response has unknown or missing
+// fields.
+var ErrSchemaDrift = errors.New("eth: response schema drift")
+
+// SchemaDrift describes how the gas object of an ETH Gas Station response differs from the fields the
+// ETHGasStationProvider decodes, as reported to the handler set with WithSchemaDriftHandler. A change of the upstream
+// API shows up here before it causes wrong prices, such as a renamed field whose level no longer has a price.
+type SchemaDrift struct {
+	// Unknown are the fields of the response the provider doesn't decode, and Missing the fields the provider decodes
+	// that the response doesn't have, both sorted. A field that is null is not missing.
+	Unknown []string
+	Missing []string
+}
+
+// String describes the drift, for logging it.
+func (d SchemaDrift) String() string {
+	var parts []string
+	if len(d.Unknown) > 0 {
+		parts = append(parts, "unknown fields "+strings.Join(d.Unknown, ", "))
+	}
+	if len(d.Missing) > 0 {
+		parts = append(parts, "missing fields "+strings.Join(d.Missing, ", "))
+	}
+	if len(parts) == 0 {
+		return "no drift"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// expectedFields are the fields of the gas object decoded by ethGasStationResponse, by priority level for the prices
+var expectedFields = []struct {
+	priority GasPriority
+	name     string
+}{
+	{GasPriorityFast, "fast"},
+	{GasPriorityFastest, "fastest"},
+	{GasPrioritySafeLow, "safeLow"},
+	{GasPriorityAverage, "average"},
+	{"", "fastWait"},
+	{"", "fastestWait"},
+	{"", "safeLowWait"},
+	{"", "avgWait"},
+	{"", "gasPriceRange"},
+	{"", "block_time"},
+}
+
+// documentedFields are fields of the ETH Gas Station API the provider ignores, which are neither expected nor unknown
+var documentedFields = []string{"blockNum", "speed"}
+
+// DetectSchemaDrift compares the gas object of a response, in the format of the ETH Gas Station API, with the fields
+// the ETHGasStationProvider decodes, with the prices of the priority levels of fieldMapping decoded from the fields
+// they are mapped to, as for WithFieldMapping. Fields in allowed, such as fields a gateway adds, are not unknown.
+//
+// An error is returned if gasObject isn't a JSON object.
+func DetectSchemaDrift(gasObject []byte, fieldMapping map[GasPriority]string, allowed ...string) (SchemaDrift, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(gasObject, &fields); err != nil || fields == nil {
+		return SchemaDrift{}, errors.New("eth: gas object is not a JSON object")
+	}
+
+	known := make(map[string]bool, len(expectedFields)+len(documentedFields)+len(allowed))
+	var drift SchemaDrift
+	for _, field := range expectedFields {
+		name := field.name
+		if mapped, ok := fieldMapping[field.priority]; ok && field.priority != "" {
+			name = mapped
+		}
+		known[name] = true
+		if _, ok := fields[name]; !ok {
+			drift.Missing = append(drift.Missing, name)
+		}
+	}
+	for _, name := range documentedFields {
+		known[name] = true
+	}
+	for _, name := range allowed {
+		known[name] = true
+	}
+	for name := range fields {
+		if !known[name] {
+			drift.Unknown = append(drift.Unknown, name)
+		}
+	}
+	sort.Strings(drift.Unknown)
+	sort.Strings(drift.Missing)
+	return drift, nil
+}
+
+// checkSchema returns the function checking the gas object of each response for drift, or nil if the provider
+// neither reports nor rejects drift
+func (p *ETHGasStationProvider) checkSchema() func(gasObject json.RawMessage) error {
+	if p.OnSchemaDrift == nil && !p.StrictSchema {
+		return nil
+	}
+	return func(gasObject json.RawMessage) error {
+		drift, err := DetectSchemaDrift(gasObject, p.FieldMapping, p.AllowedFields...)
+		if err != nil || (len(drift.Unknown) == 0 && len(drift.Missing) == 0) {
+			// a gas object that isn't an object fails to decode with a clearer error
+			return nil
+		}
+		if p.OnSchemaDrift != nil {
+			p.OnSchemaDrift(drift)
+		}
+		if p.StrictSchema {
+			return fmt.Errorf("%w: %s", ErrSchemaDrift, drift)
+		}
+		return nil
+	}
+}
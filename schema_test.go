@@ -0,0 +1,82 @@
+package gas
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSchemaDrift(t *testing.T) {
+	complete := `{"fast": 200, "fastest": 250, "safeLow": 100, "average": 150, "fastWait": 1, "fastestWait": 0.5, ` +
+		`"safeLowWait": 10, "avgWait": 3, "gasPriceRange": null, "block_time": 13, "blockNum": 1, "speed": 0.9}`
+
+	// 1. the documented response has no drift, and null fields are not missing
+	drift, err := DetectSchemaDrift([]byte(complete), nil)
+	require.NoError(t, err)
+	assert.Empty(t, drift.Unknown)
+	assert.Empty(t, drift.Missing)
+	assert.Equal(t, "no drift", drift.String())
+
+	// 2. unknown and missing fields are reported, sorted
+	drift, err = DetectSchemaDrift([]byte(`{"fast": 200, "standard": 150, "fastest": 250, "low": 100}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"low", "standard"}, drift.Unknown)
+	assert.Equal(t, []string{"average", "avgWait", "block_time", "fastWait", "fastestWait", "gasPriceRange",
+		"safeLow", "safeLowWait"}, drift.Missing)
+
+	// 3. mapped fields and allowed fields are known
+	drift, err = DetectSchemaDrift([]byte(`{"fast": 200, "standard": 150, "fastest": 250, "low": 100}`),
+		map[GasPriority]string{GasPriorityAverage: "standard"}, "low")
+	require.NoError(t, err)
+	assert.Empty(t, drift.Unknown)
+	assert.NotContains(t, drift.Missing, "standard")
+	assert.Contains(t, drift.Missing, "safeLow")
+
+	// 4. a gas object that isn't an object is an error
+	_, err = DetectSchemaDrift([]byte(`[1, 2]`), nil)
+	assert.Error(t, err)
+}
+
+func TestWithSchemaDriftHandler(t *testing.T) {
+	var drifts []SchemaDrift
+	handler := func(drift SchemaDrift) {
+		drifts = append(drifts, drift)
+	}
+	serve := func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"fast": 200.0, "fastest": 250.0, "safeLow": 100.0, "average": 150.0, "extra": 1}`))
+	}
+
+	// 1. drift is reported without failing the fetch
+	c, stop := newTestClient(t, serve, WithSchemaDriftHandler(handler))
+	defer stop()
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	require.Len(t, drifts, 1)
+	assert.Equal(t, []string{"extra"}, drifts[0].Unknown)
+	assert.Contains(t, drifts[0].Missing, "fastWait")
+
+	// 2. a strict client fails the fetch
+	c, stop = newTestClient(t, serve, WithStrictSchema())
+	defer stop()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrSchemaDrift), err)
+
+	// 3. allowed fields aren't drift
+	c, stop = newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"fast": 200, "fastest": 250, "safeLow": 100, "average": 150, "fastWait": 1, ` +
+			`"fastestWait": 0.5, "safeLowWait": 10, "avgWait": 3, "gasPriceRange": {}, "block_time": 13, "extra": 1}`))
+	}, WithStrictSchema(), WithAllowedFields("extra"))
+	defer stop()
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+
+	// 4. invalid options are rejected
+	_, err = NewClient(WithSchemaDriftHandler(nil))
+	assert.Error(t, err)
+	_, err = NewClient(WithAllowedFields(""))
+	assert.Error(t, err)
+}
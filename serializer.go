@@ -0,0 +1,82 @@
+package gas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Serializer encodes the prices persisted by a client, as exported by Client.ExportState and stored in a SharedCache by
+// NewSharedCacheProvider, for formats such as gob or protobuf that are more compact or readable from other languages
+// than the default JSON. Implementations must be safe for concurrent use.
+type Serializer interface {
+	// Name identifies the format. Data written by a serializer other than JSONSerializer starts with a header holding
+	// its name, and is only decoded by a serializer of the same name, so changing the serializer can't silently
+	// corrupt existing data. Change the name when the encoding changes incompatibly, such as "proto/v2".
+	Name() string
+
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONSerializer is the default Serializer, which encodes prices as JSON without a header, in the format written
+// before serializers were configurable.
+type JSONSerializer struct{}
+
+// Name returns "json".
+func (JSONSerializer) Name() string {
+	return "json"
+}
+
+// Marshal encodes v with json.Marshal.
+func (JSONSerializer) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes data with json.Unmarshal.
+func (JSONSerializer) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// serializerHeaderPrefix starts the header of data written by a serializer other than JSONSerializer, which is
+// followed by the name of the serializer and a newline
+const serializerHeaderPrefix = "gas-serializer:"
+
+// marshalWith encodes v with serializer, which defaults to JSONSerializer, behind the header of its name
+func marshalWith(serializer Serializer, v interface{}) ([]byte, error) {
+	if serializer == nil {
+		serializer = JSONSerializer{}
+	}
+	data, err := serializer.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := serializer.(JSONSerializer); ok {
+		return data, nil
+	}
+	header := serializerHeaderPrefix + serializer.Name() + "\n"
+	return append([]byte(header), data...), nil
+}
+
+// unmarshalWith decodes data written by marshalWith with serializer, which defaults to JSONSerializer. An error is
+// returned if the data was written by another serializer.
+func unmarshalWith(serializer Serializer, data []byte, v interface{}) error {
+	if serializer == nil {
+		serializer = JSONSerializer{}
+	}
+	// data without a header was written by JSONSerializer
+	name := JSONSerializer{}.Name()
+	if bytes.HasPrefix(data, []byte(serializerHeaderPrefix)) {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			return errors.New("eth: serialized data has an incomplete header")
+		}
+		name = string(data[len(serializerHeaderPrefix):end])
+		data = data[end+1:]
+	}
+	if name != serializer.Name() {
+		return fmt.Errorf("eth: data was written by the %q serializer, not %q", name, serializer.Name())
+	}
+	return serializer.Unmarshal(data, v)
+}
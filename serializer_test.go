@@ -0,0 +1,115 @@
+package gas
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gobSerializer encodes values with encoding/gob
+type gobSerializer struct{}
+
+func (gobSerializer) Name() string {
+	return "gob"
+}
+
+func (gobSerializer) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobSerializer) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func TestSerializer(t *testing.T) {
+	prices := GasPrices{
+		Fast:  big.NewInt(20e9),
+		Waits: map[GasPriority]time.Duration{GasPriorityFast: time.Minute},
+	}
+
+	// 1. JSON is written without a header
+	data, err := marshalWith(nil, prices)
+	require.NoError(t, err)
+	assert.Equal(t, byte('{'), data[0])
+
+	// 2. other serializers write a header with their name, and read back their own data
+	data, err = marshalWith(gobSerializer{}, prices)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("gas-serializer:gob\n")))
+	var decoded GasPrices
+	require.NoError(t, unmarshalWith(gobSerializer{}, data, &decoded))
+	assert.Equal(t, "20000000000", decoded.Fast.String())
+	assert.Equal(t, time.Minute, decoded.Waits[GasPriorityFast])
+
+	// 3. data written by another serializer is rejected
+	assert.Error(t, unmarshalWith(JSONSerializer{}, data, &decoded))
+	plain, err := marshalWith(JSONSerializer{}, prices)
+	require.NoError(t, err)
+	assert.Error(t, unmarshalWith(gobSerializer{}, plain, &decoded))
+	assert.Error(t, unmarshalWith(gobSerializer{}, []byte("gas-serializer:gob"), &decoded))
+}
+
+func TestWithStateSerializer(t *testing.T) {
+	var calls int32
+	old, err := NewClient(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(time.Minute),
+		WithStateSerializer(gobSerializer{}))
+	require.NoError(t, err)
+	_, err = old.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	state, err := old.ExportState()
+	require.NoError(t, err)
+
+	// 1. a client with the same serializer imports the state
+	c, err := NewClient(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(time.Minute),
+		WithStateSerializer(gobSerializer{}))
+	require.NoError(t, err)
+	require.NoError(t, c.ImportState(state))
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, int32(1), calls)
+
+	// 2. a client with the default serializer rejects it
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(time.Minute))
+	require.NoError(t, err)
+	assert.Error(t, c.ImportState(state))
+
+	// 3. the serializer must not be nil
+	_, err = NewClient(WithStateSerializer(nil))
+	assert.Error(t, err)
+}
+
+func TestWithSharedCacheSerializer(t *testing.T) {
+	cache := newTestSharedCache()
+	var calls int32
+	provider := countingProvider(0, nil, &calls)
+
+	// 1. prices are stored and served with the serializer
+	serializer := WithSharedCacheSerializer(gobSerializer{})
+	gobCached, err := NewSharedCacheProvider(provider, cache, "mainnet", time.Minute, serializer)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		prices, err := gobCached.Fetch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "20000000000", prices.Fast.String())
+	}
+	assert.Equal(t, int32(1), calls)
+
+	// 2. values of another serializer are replaced rather than misread
+	jsonCached, err := NewSharedCacheProvider(provider, cache, "mainnet", time.Minute)
+	require.NoError(t, err)
+	_, err = jsonCached.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls)
+	_, err = gobCached.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+}
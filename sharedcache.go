@@ -0,0 +1,98 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SharedCache is a cache shared by multiple processes, such as Redis or memcached. Implementations are provided by the
+// caller and must be safe for concurrent use.
+type SharedCache interface {
+	// GetCached returns the value stored for key, and false if there is no value or it has expired.
+	GetCached(key string) ([]byte, bool)
+
+	// SetCached stores value for key, expiring it after ttl.
+	SetCached(key string, value []byte, ttl time.Duration)
+}
+
+// NewSharedCacheProvider returns a Provider that serves prices stored under key in cache, and otherwise fetches prices
+// from provider and stores them in cache for ttl. When every instance of a service uses the same cache and key, the
+// prices fetched by one instance are served to all of them until they expire, which reduces API usage across a
+// deployment. The cache is not locked: instances that miss it at the same time each fetch from provider, and the last
+// one to finish populates it.
+//
+// An error is returned if provider or cache is nil, or ttl is not positive.
+//
+// Cached values that can't be decoded, including values written by another serializer than the one configured with
+// WithSharedCacheSerializer, are ignored and replaced with fresh prices.
+func NewSharedCacheProvider(
+	provider Provider,
+	cache SharedCache,
+	key string,
+	ttl time.Duration,
+	opts ...SharedCacheOption,
+) (Provider, error) {
+	if provider == nil || cache == nil {
+		return nil, errors.New("eth: shared cache provider needs a provider and a cache")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("eth: shared cache ttl must be positive")
+	}
+	p := &sharedCacheProvider{
+		provider: provider,
+		cache:    cache,
+		key:      key,
+		ttl:      ttl,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// SharedCacheOption configures a provider returned by NewSharedCacheProvider.
+type SharedCacheOption func(*sharedCacheProvider)
+
+// WithSharedCacheSerializer replaces JSON as the format of the prices stored in the shared cache. Every instance
+// sharing a key must use the same serializer, the values of another serializer are replaced. A nil serializer is
+// ignored.
+func WithSharedCacheSerializer(serializer Serializer) SharedCacheOption {
+	return func(p *sharedCacheProvider) {
+		if serializer != nil {
+			p.serializer = serializer
+		}
+	}
+}
+
+type sharedCacheProvider struct {
+	provider   Provider
+	cache      SharedCache
+	key        string
+	ttl        time.Duration
+	serializer Serializer
+}
+
+func (p *sharedCacheProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	if value, ok := p.cache.GetCached(p.key); ok {
+		var prices GasPrices
+		if err := unmarshalWith(p.serializer, value, &prices); err == nil {
+			return prices, nil
+		}
+	}
+
+	prices, err := p.provider.Fetch(ctx)
+	if err != nil {
+		return GasPrices{}, err
+	}
+
+	// failing to populate the cache only costs other instances a fetch, so the prices are still returned
+	if value, err := marshalWith(p.serializer, prices); err == nil {
+		p.cache.SetCached(p.key, value, p.ttl)
+	}
+	return prices, nil
+}
+
+func (p *sharedCacheProvider) CloseIdleConnections() {
+	closeIdleConnections(p.provider)
+}
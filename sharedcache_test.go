@@ -0,0 +1,88 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSharedCache struct {
+	sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newTestSharedCache() *testSharedCache {
+	return &testSharedCache{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (c *testSharedCache) GetCached(key string) ([]byte, bool) {
+	c.Lock()
+	defer c.Unlock()
+	value, ok := c.values[key]
+	return value, ok
+}
+
+func (c *testSharedCache) SetCached(key string, value []byte, ttl time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.values[key] = value
+	c.ttls[key] = ttl
+}
+
+func TestSharedCacheProvider(t *testing.T) {
+	cache := newTestSharedCache()
+	fetches := 0
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		fetches++
+		return GasPrices{
+			Fast:        big.NewInt(20e9),
+			Predictions: []PricePrediction{{Price: big.NewInt(10e9), Wait: time.Minute}},
+		}, nil
+	})
+
+	// 1. a cache miss fetches from the provider and populates the cache
+	first, err := NewSharedCacheProvider(provider, cache, "mainnet", time.Minute)
+	require.NoError(t, err)
+	prices, err := first.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", prices.Fast.String())
+	assert.Equal(t, 1, fetches)
+	assert.Equal(t, time.Minute, cache.ttls["mainnet"])
+
+	// 2. another instance sharing the cache doesn't fetch
+	second, err := NewSharedCacheProvider(provider, cache, "mainnet", time.Minute)
+	require.NoError(t, err)
+	prices, err = second.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", prices.Fast.String())
+	require.Len(t, prices.Predictions, 1)
+	assert.Equal(t, time.Minute, prices.Predictions[0].Wait)
+	assert.Equal(t, 1, fetches)
+
+	// 3. values that can't be decoded are replaced
+	cache.SetCached("mainnet", []byte("not json"), time.Minute)
+	_, err = second.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	// 4. different keys are isolated
+	third, err := NewSharedCacheProvider(provider, cache, "ropsten", time.Minute)
+	require.NoError(t, err)
+	_, err = third.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetches)
+
+	// 5. a missing provider or cache and non-positive ttls are rejected
+	_, err = NewSharedCacheProvider(nil, cache, "mainnet", time.Minute)
+	assert.Error(t, err)
+	_, err = NewSharedCacheProvider(provider, nil, "mainnet", time.Minute)
+	assert.Error(t, err)
+	_, err = NewSharedCacheProvider(provider, cache, "mainnet", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+// Spread returns the difference between the fastest and safeLow prices in wei, and the ratio of the fastest price to
+// the safeLow price. A widening spread signals congestion. An error is returned if either price is missing, or if the
+// safeLow price is zero.
+func (p GasPrices) Spread() (abs *big.Int, ratio *big.Float, err error) {
+	fastest, err := p.price(GasPriorityFastest)
+	if err != nil {
+		return nil, nil, err
+	}
+	safeLow, err := p.price(GasPrioritySafeLow)
+	if err != nil {
+		return nil, nil, err
+	}
+	if safeLow.Sign() == 0 {
+		return nil, nil, errors.New("eth: spread ratio is undefined for a zero safeLow price")
+	}
+	abs = new(big.Int).Sub(fastest, safeLow)
+	ratio = new(big.Float).Quo(new(big.Float).SetInt(fastest), new(big.Float).SetInt(safeLow))
+	return abs, ratio, nil
+}
+
+// Spread is like GasPrices.Spread, using the prices of a single response. Unless the client was configured with
+// WithMaxResultAge, it always makes a new call to the provider.
+func (c *Client) Spread() (abs *big.Int, ratio *big.Float, err error) {
+	prices, err := c.load(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+	return prices.Spread()
+}
@@ -0,0 +1,37 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpread(t *testing.T) {
+	prices := GasPrices{SafeLow: big.NewInt(10e9), Fastest: big.NewInt(25e9)}
+
+	// 1. the spread is the difference and ratio of the fastest and safeLow prices
+	abs, ratio, err := prices.Spread()
+	require.NoError(t, err)
+	assert.Equal(t, "15000000000", abs.String())
+	assert.Equal(t, "2.5", ratio.Text('f', -1))
+
+	// 2. a missing price or a zero safeLow price is an error
+	_, _, err = GasPrices{SafeLow: big.NewInt(10e9)}.Spread()
+	assert.Error(t, err)
+	_, _, err = GasPrices{SafeLow: new(big.Int), Fastest: big.NewInt(25e9)}.Spread()
+	assert.Error(t, err)
+
+	// 3. the client computes it from a single response, and includes it in detailed results
+	c, stop := newTestClient(t, serveTestResponse)
+	defer stop()
+	abs, ratio, err = c.Spread()
+	require.NoError(t, err)
+	assert.Equal(t, "15000000000", abs.String())
+	assert.Equal(t, "2.5", ratio.Text('f', -1))
+	result, err := c.SuggestDetailed(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "15000000000", result.Spread.String())
+	assert.Equal(t, "2.5", result.SpreadRatio.Text('f', -1))
+}
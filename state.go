@@ -0,0 +1,103 @@
+package gas
+
+import (
+	"errors"
+	"time"
+)
+
+// stateVersion is the version of the format written by ExportState, it must be incremented by incompatible changes
+const stateVersion = 1
+
+type exportedState struct {
+	Version   int       `json:"version"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	Prices    GasPrices `json:"prices"`
+}
+
+// ExportState serializes the prices cached by the client and when they were fetched, so another process can start
+// warm by passing the result to ImportState. The serialized format is versioned and may gain fields over time. It is
+// JSON unless the client was configured with WithStateSerializer.
+//
+// An error is returned if the client isn't caching or nothing is cached yet.
+func (c *Client) ExportState() ([]byte, error) {
+	if c.cache == nil {
+		return nil, errors.New("eth: client is not caching")
+	}
+
+	c.cache.Lock()
+	state := exportedState{
+		Version:   stateVersion,
+		FetchedAt: c.cache.fetchedAt,
+		Prices:    c.cache.latestPrices.copy(),
+	}
+	c.cache.Unlock()
+
+	if state.FetchedAt.IsZero() {
+		return nil, errors.New("eth: no cached prices to export")
+	}
+	return marshalWith(c.stateSerializer, state)
+}
+
+// ImportState replaces the prices cached by the client with prices exported by ExportState. The prices keep the age
+// they had when exported, so they are refreshed as if they were fetched by this client, which relies on the clocks of
+// both processes agreeing. A fetch time in the future is treated as just fetched. Imported prices replace prices
+// expired by Invalidate, and are not overwritten by a refresh that was in flight.
+//
+// An error is returned if the client isn't caching, or the state can't be decoded, was written by an unsupported
+// version or by another serializer than that of the client. The prices are checked like those loaded from the provider,
+// and an error is returned if they have no price for any priority level or fail the checks configured on the client.
+func (c *Client) ImportState(data []byte) error {
+	if c.cache == nil {
+		return errors.New("eth: client is not caching")
+	}
+
+	var state exportedState
+	if err := unmarshalWith(c.stateSerializer, data, &state); err != nil {
+		return err
+	}
+	if state.Version != stateVersion {
+		return errors.New("eth: unsupported state version")
+	}
+	if state.FetchedAt.IsZero() {
+		return errors.New("eth: state has no fetch time")
+	}
+	if err := c.validateImported(state.Prices); err != nil {
+		return err
+	}
+
+	c.cache.Lock()
+	defer c.cache.Unlock()
+
+	// the serialized time only has a wall clock reading, so it is converted to an age once and rebased on the monotonic
+	// clock, which keeps later expiry checks robust to wall clock jumps
+	now := c.cache.clock()
+	age := now.Sub(state.FetchedAt)
+	if age < 0 {
+		age = 0
+	}
+
+	c.cache.previousPrices = c.cache.latestPrices
+	c.cache.latestPrices = state.Prices
+	c.cache.fetchedAt = now.Add(-age)
+	c.cache.invalidated = false
+	c.cache.generation++
+	return nil
+}
+
+// validateImported applies the checks of prices loaded from the provider to imported prices, other than their change
+// from the previous prices
+func (c *Client) validateImported(prices GasPrices) error {
+	missing := true
+	for _, priority := range priorityOrder {
+		if _, err := prices.price(priority); err == nil {
+			missing = false
+		}
+	}
+	if missing {
+		return errors.New("eth: state has no gas prices")
+	}
+	if err := c.validate(prices); err != nil {
+		return err
+	}
+	return c.checkPlausible(prices)
+}
@@ -0,0 +1,95 @@
+package gas
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientExportState(t *testing.T) {
+	var calls int32
+	old, err := NewClient(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(time.Minute))
+	require.NoError(t, err)
+
+	// 1. nothing can be exported before prices are cached
+	_, err = old.ExportState()
+	assert.Error(t, err)
+
+	_, err = old.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	state, err := old.ExportState()
+	require.NoError(t, err)
+
+	// 2. an imported state is served without fetching
+	var newCalls int32
+	c, err := NewClient(WithProvider(countingProvider(0, nil, &newCalls)), WithMaxResultAge(time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, c.ImportState(state))
+
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&newCalls))
+
+	// 3. imported prices keep their age
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &newCalls)), WithMaxResultAge(time.Nanosecond))
+	require.NoError(t, err)
+	require.NoError(t, c.ImportState(state))
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&newCalls))
+
+	// 4. invalid states are rejected
+	assert.Error(t, c.ImportState([]byte(`{"version": 2, "fetchedAt": "2019-10-01T12:00:00Z"}`)))
+	assert.Error(t, c.ImportState([]byte(`{"version": 1}`)))
+	assert.Error(t, c.ImportState([]byte(`not json`)))
+
+	// 5. imported prices replace invalidated prices, and refreshes in flight don't overwrite them
+	c, err = NewClient(WithProvider(countingProvider(0, nil, &newCalls)), WithMaxResultAge(time.Minute))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	c.Invalidate()
+	generation := c.cache.generation
+	require.NoError(t, c.ImportState(state))
+	assert.Equal(t, generation+1, c.cache.generation)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&newCalls))
+
+	// 6. prices that fail the checks of the client, or that are missing, are rejected
+	assert.Error(t, c.ImportState([]byte(`{"version": 1, "fetchedAt": "2019-10-01T12:00:00Z", "prices": {"Fast": -1}}`)))
+	assert.Error(t, c.ImportState([]byte(`{"version": 1, "fetchedAt": "2019-10-01T12:00:00Z", "prices": {}}`)))
+	strict, err := NewClient(WithProvider(countingProvider(0, nil, &newCalls)), WithMaxResultAge(time.Minute),
+		WithRejectZeroPrices())
+	require.NoError(t, err)
+	zero := []byte(`{"version": 1, "fetchedAt": "2019-10-01T12:00:00Z", "prices": {"Fast": 0}}`)
+	assert.Error(t, strict.ImportState(zero))
+
+	// 7. clients that don't cache can't export or import
+	uncached, err := NewClient()
+	require.NoError(t, err)
+	_, err = uncached.ExportState()
+	assert.Error(t, err)
+	assert.Error(t, uncached.ImportState(state))
+}
+
+func TestClientImportStateClockSkew(t *testing.T) {
+	var calls int32
+	c, err := NewClient(WithProvider(countingProvider(0, nil, &calls)), WithMaxResultAge(50*time.Millisecond))
+	require.NoError(t, err)
+
+	// 1. a state exported by a process with a clock ahead of ours expires after the max age
+	fetchedAt := time.Now().Add(time.Hour).Format(time.RFC3339Nano)
+	state := []byte(`{"version": 1, "fetchedAt": "` + fetchedAt + `", "prices": {"Fast": 20000000000}}`)
+	require.NoError(t, c.ImportState(state))
+	assert.True(t, time.Since(c.cache.fetchedAt) >= 0)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,105 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"sync"
+)
+
+// defaultSyntheticStart is the fast price a SyntheticProvider starts from if none is set, 20 gwei
+const defaultSyntheticStart = 20e9
+
+// SyntheticProvider is a Provider that generates prices for testing code that reacts to price changes, such as alerts,
+// smoothing or thresholds. Each call to Fetch advances the fast price, either to the next value of Script or by a step
+// of a random walk, and the other levels are derived from it at fixed ratios: half of it for safeLow, three quarters
+// for average and one and a quarter for fastest.
+//
+// The random walk multiplies the price by e^(Volatility * N) on each call, where N is drawn from a standard normal
+// distribution, so the price never becomes negative. Seed Rand for a reproducible sequence.
+type SyntheticProvider struct {
+	// Start is the fast price in wei of the first call, it defaults to 20 gwei.
+	Start *big.Int
+
+	// Volatility is the standard deviation of the log change of the price between calls, such as 0.05 for moves of
+	// about 5%. A zero volatility keeps the price at Start.
+	Volatility float64
+
+	// Script lists the fast prices in wei to return in order, instead of the random walk. The last price is repeated
+	// once the script is exhausted.
+	Script []*big.Int
+
+	// Rand is the source of the random walk, it defaults to a source with a fixed seed.
+	Rand *rand.Rand
+
+	mu    sync.Mutex
+	calls int
+	price float64
+}
+
+// Fetch returns the next synthetic prices.
+func (p *SyntheticProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	if err := ctx.Err(); err != nil {
+		return GasPrices{}, err
+	}
+	if !(p.Volatility >= 0) || math.IsInf(p.Volatility, 1) {
+		return GasPrices{}, errors.New("eth: volatility must be a non-negative number")
+	}
+	if p.Start != nil && p.Start.Sign() < 0 {
+		return GasPrices{}, errors.New("eth: start price must not be negative")
+	}
+
+	fast, err := p.next()
+	if err != nil {
+		return GasPrices{}, err
+	}
+	return GasPrices{
+		Fast:    fast,
+		Fastest: scalePrice(fast, 5, 4),
+		SafeLow: scalePrice(fast, 1, 2),
+		Average: scalePrice(fast, 3, 4),
+	}, nil
+}
+
+// next advances the fast price
+func (p *SyntheticProvider) next() (*big.Int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	defer func() { p.calls++ }()
+	if len(p.Script) > 0 {
+		i := p.calls
+		if i >= len(p.Script) {
+			i = len(p.Script) - 1
+		}
+		if p.Script[i] == nil || p.Script[i].Sign() < 0 {
+			return nil, errors.New("eth: scripted prices must not be nil or negative")
+		}
+		return new(big.Int).Set(p.Script[i]), nil
+	}
+
+	if p.calls == 0 {
+		p.price = defaultSyntheticStart
+		if p.Start != nil {
+			p.price, _ = new(big.Float).SetInt(p.Start).Float64()
+		}
+	} else {
+		if p.Rand == nil {
+			p.Rand = rand.New(rand.NewSource(1))
+		}
+		p.price *= math.Exp(p.Volatility * p.Rand.NormFloat64())
+	}
+	if math.IsInf(p.price, 0) || math.IsNaN(p.price) {
+		return nil, errors.New("eth: synthetic price out of range")
+	}
+	price, _ := big.NewFloat(math.Round(p.price)).Int(nil)
+	return price, nil
+}
+
+// scalePrice returns price * numerator / denominator, rounded down
+func scalePrice(price *big.Int, numerator, denominator int64) *big.Int {
+	scaled := new(big.Int).Mul(price, big.NewInt(numerator))
+	return scaled.Quo(scaled, big.NewInt(denominator))
+}
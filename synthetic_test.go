@@ -0,0 +1,71 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyntheticProviderScript(t *testing.T) {
+	provider := &SyntheticProvider{Script: []*big.Int{big.NewInt(100), big.NewInt(200)}}
+
+	// 1. scripted prices are returned in order, with the other levels derived from them
+	prices, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "100", prices.Fast.String())
+	assert.Equal(t, "125", prices.Fastest.String())
+	assert.Equal(t, "50", prices.SafeLow.String())
+	assert.Equal(t, "75", prices.Average.String())
+
+	// 2. the last price is repeated once the script is exhausted
+	for i := 0; i < 2; i++ {
+		prices, err = provider.Fetch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "200", prices.Fast.String())
+	}
+
+	// 3. invalid scripted prices are rejected
+	_, err = (&SyntheticProvider{Script: []*big.Int{big.NewInt(-1)}}).Fetch(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSyntheticProviderRandomWalk(t *testing.T) {
+	walk := func(seed int64) []string {
+		provider := &SyntheticProvider{Start: big.NewInt(30e9), Volatility: 0.1, Rand: rand.New(rand.NewSource(seed))}
+		var fast []string
+		for i := 0; i < 5; i++ {
+			prices, err := provider.Fetch(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, prices.SafeLow.Sign())
+			fast = append(fast, prices.Fast.String())
+		}
+		return fast
+	}
+
+	// 1. the walk starts at the start price and moves on each call
+	first := walk(7)
+	assert.Equal(t, "30000000000", first[0])
+	assert.NotEqual(t, first[1], first[2])
+
+	// 2. the same seed gives the same walk
+	assert.Equal(t, first, walk(7))
+	assert.NotEqual(t, first, walk(8))
+
+	// 3. without volatility the price stays at the default start
+	provider := &SyntheticProvider{}
+	for i := 0; i < 3; i++ {
+		prices, err := provider.Fetch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "20000000000", prices.Fast.String())
+	}
+
+	// 4. invalid configurations are rejected
+	_, err := (&SyntheticProvider{Volatility: -1}).Fetch(context.Background())
+	assert.Error(t, err)
+	_, err = (&SyntheticProvider{Start: big.NewInt(-1)}).Fetch(context.Background())
+	assert.Error(t, err)
+}
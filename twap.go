@@ -0,0 +1,216 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// maxTWAPSamples bounds the samples a TWAPProvider retains, however short the interval between them
+const maxTWAPSamples = 4096
+
+// TWAPProvider is a Provider that records the prices loaded by the wrapped provider with the time they were loaded,
+// to compute their time-weighted average price over a trailing window with TWAP. Wrapped in a client configured with
+// WithMaxResultAge, it records every refresh of the cache.
+//
+// Samples older than the retention given to NewTWAPProvider are discarded, and at most 4096 samples are retained.
+type TWAPProvider struct {
+	next      Provider
+	retention time.Duration
+
+	// now returns the current time, it defaults to time.Now
+	now func() time.Time
+
+	// trendSamples and trendThreshold configure Trend
+	trendSamples   int
+	trendThreshold float64
+
+	mu      sync.Mutex
+	samples []twapSample
+}
+
+type twapSample struct {
+	prices GasPrices
+	at     time.Time
+}
+
+// Defaults of the trend computed by TWAPProvider.Trend.
+const (
+	DefaultTrendSamples   = 5
+	DefaultTrendThreshold = 5.0
+)
+
+// NewTWAPProvider returns a TWAPProvider that loads prices from next, retaining samples for retention, the longest
+// window TWAP can average over.
+func NewTWAPProvider(next Provider, retention time.Duration, opts ...TWAPOption) *TWAPProvider {
+	p := &TWAPProvider{
+		next:           next,
+		retention:      retention,
+		trendSamples:   DefaultTrendSamples,
+		trendThreshold: DefaultTrendThreshold,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// TWAPOption configures a TWAPProvider.
+type TWAPOption func(*TWAPProvider)
+
+// WithTrend configures the trend computed by Trend: over the most recent samples recorded, at least 2, a price moving
+// more than threshold percent is rising or falling. It defaults to 5 samples and a threshold of 5 percent. Invalid
+// values are replaced by the defaults.
+func WithTrend(samples int, threshold float64) TWAPOption {
+	return func(p *TWAPProvider) {
+		if samples >= 2 {
+			p.trendSamples = samples
+		}
+		if threshold >= 0 && !math.IsInf(threshold, 0) {
+			p.trendThreshold = threshold
+		}
+	}
+}
+
+// Trend is the direction gas prices are moving in, as returned by TWAPProvider.Trend.
+type Trend string
+
+const (
+	// TrendRising is the trend of prices that rose more than the threshold.
+	TrendRising = Trend("rising")
+
+	// TrendFalling is the trend of prices that fell more than the threshold.
+	TrendFalling = Trend("falling")
+
+	// TrendStable is the trend of prices that moved by at most the threshold.
+	TrendStable = Trend("stable")
+)
+
+// Fetch loads prices from the wrapped provider, recording them if the call succeeds.
+func (p *TWAPProvider) Fetch(ctx context.Context) (GasPrices, error) {
+	prices, err := p.next.Fetch(ctx)
+	if err != nil {
+		return prices, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.clock()
+	p.samples = append(p.samples, twapSample{prices: prices.copy(), at: now})
+	p.prune(now)
+	return prices, nil
+}
+
+// TWAP returns the time-weighted average price in wei of priority over the window ending now. Each recorded price is
+// weighted by how long it was the latest price within the window, the most recent one until now, so a momentary spike
+// weighs in proportion to how long it lasted rather than to the number of samples. If no price was recorded before
+// the window started, the average is taken over the part of the window since the first price.
+//
+// An error is returned if no price of priority has been recorded, or window is not positive.
+func (p *TWAPProvider) TWAP(priority GasPriority, window time.Duration) (*big.Int, error) {
+	if window <= 0 {
+		return nil, errors.New("eth: twap window must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.clock()
+	start := now.Add(-window)
+
+	// the price in effect at each time is that of the most recent sample up to then
+	weighted, total := new(big.Int), new(big.Int)
+	var last *big.Int
+	var lastAt time.Time
+	add := func(until time.Time) {
+		if last == nil || !until.After(lastAt) {
+			return
+		}
+		from := lastAt
+		if from.Before(start) {
+			from = start
+		}
+		if elapsed := until.Sub(from); elapsed > 0 {
+			weighted.Add(weighted, new(big.Int).Mul(last, big.NewInt(int64(elapsed))))
+			total.Add(total, big.NewInt(int64(elapsed)))
+		}
+	}
+	for _, sample := range p.samples {
+		price, err := sample.prices.price(priority)
+		if err != nil {
+			continue
+		}
+		add(sample.at)
+		last, lastAt = price, sample.at
+	}
+	if last == nil {
+		return nil, errors.New("eth: no gas price recorded for priority")
+	}
+	add(now)
+	if total.Sign() == 0 {
+		// the only price in effect was recorded now
+		return new(big.Int).Set(last), nil
+	}
+	return weighted.Quo(weighted, total), nil
+}
+
+// Trend returns whether the price of priority is rising, falling or stable over the most recent samples recorded, 5
+// unless configured otherwise with WithTrend, for simple bidding logic such as submitting before prices rise. The
+// latest price is compared to the oldest of these samples, and a change of more than the threshold, 5 percent by
+// default, is a trend. Samples within the retention without a price of priority are skipped.
+//
+// An error is returned if fewer than 2 prices of priority have been recorded.
+func (p *TWAPProvider) Trend(priority GasPriority) (Trend, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var recent []*big.Int
+	for i := len(p.samples) - 1; i >= 0 && len(recent) < p.trendSamples; i-- {
+		if price, err := p.samples[i].prices.price(priority); err == nil {
+			recent = append(recent, price)
+		}
+	}
+	if len(recent) < 2 {
+		return "", errors.New("eth: not enough gas prices recorded for a trend")
+	}
+	latest, oldest := recent[0], recent[len(recent)-1]
+
+	// the change is compared in wei, as a percentage of the oldest price
+	change := new(big.Rat).SetInt(new(big.Int).Sub(latest, oldest))
+	limit := new(big.Rat).Mul(new(big.Rat).SetFloat64(p.trendThreshold/100), new(big.Rat).SetInt(oldest))
+	switch {
+	case change.Cmp(limit) > 0:
+		return TrendRising, nil
+	case change.Cmp(limit.Neg(limit)) < 0:
+		return TrendFalling, nil
+	}
+	return TrendStable, nil
+}
+
+// CloseIdleConnections closes idle connections of the wrapped provider.
+func (p *TWAPProvider) CloseIdleConnections() {
+	closeIdleConnections(p.next)
+}
+
+// prune discards samples that can't affect an average within the retention, it must be called with the lock held. The
+// most recent sample before the retention is kept, since it is the price in effect at its start.
+func (p *TWAPProvider) prune(now time.Time) {
+	cutoff := now.Add(-p.retention)
+	drop := 0
+	for drop+1 < len(p.samples) && !p.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	if len(p.samples)-drop > maxTWAPSamples {
+		drop = len(p.samples) - maxTWAPSamples
+	}
+	p.samples = append(p.samples[:0], p.samples[drop:]...)
+}
+
+func (p *TWAPProvider) clock() time.Time {
+	if p.now == nil {
+		return time.Now()
+	}
+	return p.now()
+}
@@ -0,0 +1,113 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTWAPProvider(t *testing.T) {
+	var price int64
+	provider := NewTWAPProvider(ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(atomic.LoadInt64(&price))}, nil
+	}), 10*time.Minute)
+	start := time.Now()
+	now := start
+	provider.now = func() time.Time {
+		return now
+	}
+	record := func(at time.Duration, value int64) {
+		now = start.Add(at)
+		atomic.StoreInt64(&price, value)
+		_, err := provider.Fetch(context.Background())
+		require.NoError(t, err)
+	}
+
+	// 1. nothing is recorded yet
+	_, err := provider.TWAP(GasPriorityFast, time.Minute)
+	assert.Error(t, err)
+
+	// 2. a single price is its own average
+	record(0, 100)
+	twap, err := provider.TWAP(GasPriorityFast, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "100", twap.String())
+
+	// 3. prices are weighted by how long they lasted, not by the number of samples
+	record(50*time.Second, 400)
+	record(51*time.Second, 100)
+	now = start.Add(60 * time.Second)
+	twap, err = provider.TWAP(GasPriorityFast, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "105", twap.String())
+
+	// 4. the price in effect at the start of the window counts from the start
+	twap, err = provider.TWAP(GasPriorityFast, 20*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "115", twap.String())
+
+	// 5. a window before the first price is averaged since that price
+	twap, err = provider.TWAP(GasPriorityFast, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "105", twap.String())
+
+	// 6. samples older than the retention are discarded, except the price in effect at its start
+	record(20*time.Minute, 200)
+	assert.Len(t, provider.samples, 2)
+	twap, err = provider.TWAP(GasPriorityFast, 20*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "100", twap.String(), "the price in effect was 100 until now")
+
+	// 7. levels that weren't recorded and windows that aren't positive are rejected
+	_, err = provider.TWAP(GasPrioritySafeLow, time.Minute)
+	assert.Error(t, err)
+	_, err = provider.TWAP(GasPriorityFast, 0)
+	assert.Error(t, err)
+}
+
+func TestTWAPProviderTrend(t *testing.T) {
+	var price int64
+	provider := NewTWAPProvider(ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(atomic.LoadInt64(&price))}, nil
+	}), time.Hour, WithTrend(3, 10))
+	record := func(values ...int64) {
+		for _, value := range values {
+			atomic.StoreInt64(&price, value)
+			_, err := provider.Fetch(context.Background())
+			require.NoError(t, err)
+		}
+	}
+
+	// 1. a trend needs at least 2 prices
+	record(100)
+	_, err := provider.Trend(GasPriorityFast)
+	assert.Error(t, err)
+
+	// 2. changes up to the threshold are stable
+	record(110)
+	trend, err := provider.Trend(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, TrendStable, trend)
+
+	// 3. the latest price is compared to the oldest of the configured number of samples
+	record(120)
+	trend, err = provider.Trend(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, TrendRising, trend)
+	record(100, 90)
+	trend, err = provider.Trend(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, TrendFalling, trend, "90 is 25% below 120")
+
+	// 4. levels that weren't recorded have no trend, and invalid settings fall back to the defaults
+	_, err = provider.Trend(GasPrioritySafeLow)
+	assert.Error(t, err)
+	provider = NewTWAPProvider(provider, time.Hour, WithTrend(1, -1))
+	assert.Equal(t, DefaultTrendSamples, provider.trendSamples)
+	assert.Equal(t, DefaultTrendThreshold, provider.trendThreshold)
+}
@@ -0,0 +1,98 @@
+package gas
+
+import (
+	"errors"
+	"math/big"
+)
+
+// UnitConverter is implemented by providers whose responses hold raw prices in a unit other than wei, so the unit and
+// the conversion they apply can be inspected, and reused on raw values fetched separately.
+type UnitConverter interface {
+	// Unit returns the unit of the raw prices in the responses of the provider.
+	Unit() InputScale
+
+	// ConvertToWei converts a raw price in the unit of the provider to wei, as the provider does.
+	ConvertToWei(raw float64) (*big.Int, error)
+}
+
+// String returns the name of the unit.
+func (s InputScale) String() string {
+	switch s {
+	case InputScaleTenthsOfGwei:
+		return "tenths of gwei"
+	case InputScaleGwei:
+		return "gwei"
+	case InputScaleWei:
+		return "wei"
+	default:
+		return "unknown input scale"
+	}
+}
+
+// WeiPerUnit returns the number of wei in one unit of the scale, 1e8 for tenths of gwei, 1e9 for gwei and 1 for wei.
+// It returns nil for an unknown scale.
+func (s InputScale) WeiPerUnit() *big.Int {
+	if !s.valid() {
+		return nil
+	}
+	return new(big.Int).Set(s.conversionFactor().Num())
+}
+
+// ConvertToWei converts a raw price in the unit of the scale to wei exactly. It returns an error if the price is
+// negative, not a finite number, or has a fractional number of wei.
+func (s InputScale) ConvertToWei(raw float64) (*big.Int, error) {
+	if !s.valid() {
+		return nil, errors.New("eth: unknown/unsupported input scale")
+	}
+	return parseScaledGasPriceToWei(raw, s)
+}
+
+// Unit returns the configured InputScale of the provider.
+func (p *ETHGasStationProvider) Unit() InputScale {
+	return p.InputScale
+}
+
+// ConvertToWei converts a raw price as it appears in an ETH Gas Station response to wei.
+func (p *ETHGasStationProvider) ConvertToWei(raw float64) (*big.Int, error) {
+	return p.InputScale.ConvertToWei(raw)
+}
+
+// Unit returns InputScaleGwei, the unit of Blocknative prices.
+func (p *BlocknativeProvider) Unit() InputScale {
+	return InputScaleGwei
+}
+
+// ConvertToWei converts a price in gwei to wei, rounding to the nearest wei.
+func (p *BlocknativeProvider) ConvertToWei(raw float64) (*big.Int, error) {
+	return parseGweiToWei(raw)
+}
+
+// Unit returns InputScaleGwei, the unit of Polygon Gas Station prices.
+func (p *PolygonGasStationProvider) Unit() InputScale {
+	return InputScaleGwei
+}
+
+// ConvertToWei converts a price in gwei to wei, rounding to the nearest wei.
+func (p *PolygonGasStationProvider) ConvertToWei(raw float64) (*big.Int, error) {
+	return parseGweiToWei(raw)
+}
+
+// Unit returns InputScaleGwei, the unit of Etherscan prices.
+func (p *EtherscanProvider) Unit() InputScale {
+	return InputScaleGwei
+}
+
+// ConvertToWei converts a price in gwei to wei exactly, as the provider does.
+func (p *EtherscanProvider) ConvertToWei(raw float64) (*big.Int, error) {
+	return InputScaleGwei.ConvertToWei(raw)
+}
+
+// Unit returns InputScaleGwei, the unit of Owlracle prices.
+func (p *OwlracleProvider) Unit() InputScale {
+	return InputScaleGwei
+}
+
+// ConvertToWei converts a price in gwei to wei, rounding to the nearest wei.
+func (p *OwlracleProvider) ConvertToWei(raw float64) (*big.Int, error) {
+	return parseGweiToWei(raw)
+}
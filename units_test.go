@@ -0,0 +1,58 @@
+package gas
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputScaleConvertToWei(t *testing.T) {
+	// 1. each scale reports its unit and conversion factor
+	assert.Equal(t, "tenths of gwei", InputScaleTenthsOfGwei.String())
+	assert.Equal(t, "100000000", InputScaleTenthsOfGwei.WeiPerUnit().String())
+	assert.Equal(t, "gwei", InputScaleGwei.String())
+	assert.Equal(t, "1000000000", InputScaleGwei.WeiPerUnit().String())
+	assert.Equal(t, "wei", InputScaleWei.String())
+	assert.Equal(t, "1", InputScaleWei.WeiPerUnit().String())
+
+	// 2. raw prices are converted exactly
+	wei, err := InputScaleTenthsOfGwei.ConvertToWei(1205)
+	require.NoError(t, err)
+	assert.Equal(t, "120500000000", wei.String())
+	wei, err = InputScaleGwei.ConvertToWei(0.3)
+	require.NoError(t, err)
+	assert.Equal(t, "300000000", wei.String())
+
+	// 3. the conversion factor is a copy
+	InputScaleGwei.WeiPerUnit().SetInt64(1)
+	assert.Equal(t, "1000000000", InputScaleGwei.WeiPerUnit().String())
+
+	// 4. invalid prices and unknown scales are rejected
+	_, err = InputScaleGwei.ConvertToWei(math.NaN())
+	assert.Error(t, err)
+	_, err = InputScale(42).ConvertToWei(1)
+	assert.Error(t, err)
+	assert.Nil(t, InputScale(42).WeiPerUnit())
+}
+
+func TestProviderUnits(t *testing.T) {
+	// 1. the ETH Gas Station provider converts with its configured scale
+	var converter UnitConverter = &ETHGasStationProvider{}
+	assert.Equal(t, InputScaleTenthsOfGwei, converter.Unit())
+	wei, err := converter.ConvertToWei(200)
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", wei.String())
+
+	converter = &ETHGasStationProvider{InputScale: InputScaleGwei}
+	assert.Equal(t, InputScaleGwei, converter.Unit())
+
+	// 2. providers reporting gwei round to the nearest wei
+	for _, converter := range []UnitConverter{&BlocknativeProvider{}, &PolygonGasStationProvider{}, &OwlracleProvider{}} {
+		assert.Equal(t, InputScaleGwei, converter.Unit())
+		wei, err := converter.ConvertToWei(1.0000000006)
+		require.NoError(t, err)
+		assert.Equal(t, "1000000001", wei.String())
+	}
+}
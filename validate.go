@@ -0,0 +1,158 @@
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrUnexpectedPriceChange is returned when a refresh is rejected because a price moved more than the percentage
+// configured with WithMaxPriceChange.
+var ErrUnexpectedPriceChange = errors.New("eth: gas price changed more than the allowed percentage")
+
+// ErrInvertedWaitTimes is returned by ValidateWaitTimes when a priority level has a longer wait time than a cheaper
+// level, and for responses rejected by a client configured with WithRejectInvertedWaitTimes.
+var ErrInvertedWaitTimes = errors.New("eth: wait times are not ordered by priority")
+
+var (
+	errNegativePrice = errors.New("eth: gas price must not be negative")
+	errZeroPrice     = errors.New("eth: gas price must not be zero")
+)
+
+// ValidateGasPrices applies the strictest sanity checks of the package to prices obtained from any source. It returns an
+// error if any price or fee is negative, a priority level has a zero price, or the prices of the priority levels are
+// not ordered from safeLow to fastest. Prices that are missing are not checked.
+//
+// Prices loaded by a Client are always checked for negative values, and for zero prices if it was configured with
+// WithRejectZeroPrices. Their ordering is not checked, since providers may briefly report out of order tiers.
+func ValidateGasPrices(prices GasPrices) error {
+	if err := validatePrices(prices, true); err != nil {
+		return err
+	}
+
+	var previous *big.Int
+	for _, priority := range priorityOrder {
+		price, err := prices.price(priority)
+		if err != nil {
+			continue
+		}
+		if previous != nil && price.Cmp(previous) < 0 {
+			return errors.New("eth: gas prices are not ordered by priority")
+		}
+		previous = price
+	}
+	return nil
+}
+
+// ValidateWaitTimes returns an error wrapping ErrInvertedWaitTimes if the wait times of the priority levels don't
+// decrease from safeLow to fastest, which indicates bad data from the provider that would mislead PriorityForTargetWait
+// and FeeParamsForWait. Levels with the same wait time, and levels without one, are accepted. It can be passed to the
+// Validate middleware to check the prices of any provider.
+func ValidateWaitTimes(prices GasPrices) error {
+	var (
+		previous         time.Duration
+		previousPriority GasPriority
+	)
+	for _, priority := range priorityOrder {
+		wait, ok := prices.Waits[priority]
+		if !ok {
+			continue
+		}
+		if previousPriority != "" && wait > previous {
+			return fmt.Errorf("%w: %s waits %s, longer than %s at %s", ErrInvertedWaitTimes, priority, wait,
+				previousPriority, previous)
+		}
+		previous, previousPriority = wait, priority
+	}
+	return nil
+}
+
+// validate applies the checks configured on the client to prices loaded from the provider, other than their
+// plausibility and change from the previous prices
+func (c *Client) validate(prices GasPrices) error {
+	if err := validatePrices(prices, c.rejectZero); err != nil {
+		return err
+	}
+	if c.rejectInvertedWaits {
+		return ValidateWaitTimes(prices)
+	}
+	return nil
+}
+
+// validatePrices rejects negative prices and fees from any provider, and zero prices of priority levels if rejectZero
+// is set
+func validatePrices(prices GasPrices, rejectZero bool) error {
+	values := []*big.Int{prices.Fast, prices.Fastest, prices.SafeLow, prices.Average}
+	for _, price := range values {
+		if price == nil {
+			continue
+		}
+		if price.Sign() < 0 {
+			return errNegativePrice
+		}
+		if rejectZero && price.Sign() == 0 {
+			return errZeroPrice
+		}
+	}
+
+	values = append(values[:0], prices.BaseFee)
+	for _, prediction := range prices.Predictions {
+		values = append(values, prediction.Price)
+	}
+	for _, fee := range prices.Fees {
+		values = append(values, fee.MaxFeePerGas, fee.MaxPriorityFeePerGas)
+	}
+	for _, value := range values {
+		if value != nil && value.Sign() < 0 {
+			return errNegativePrice
+		}
+	}
+	return nil
+}
+
+// checkPriceChange returns ErrUnexpectedPriceChange if any price in next moved more than percent from the same price in
+// previous. Prices that are missing from either are not compared.
+func checkPriceChange(previous, next GasPrices, percent *big.Rat) error {
+	pairs := [][2]*big.Int{
+		{previous.Fast, next.Fast},
+		{previous.Fastest, next.Fastest},
+		{previous.SafeLow, next.SafeLow},
+		{previous.Average, next.Average},
+	}
+	for _, pair := range pairs {
+		before, after := pair[0], pair[1]
+		if before == nil || after == nil || before.Sign() <= 0 {
+			continue
+		}
+
+		// the price moved too much if |after - before| * 100 > percent * before
+		change := new(big.Rat).SetInt(new(big.Int).Abs(new(big.Int).Sub(after, before)))
+		change.Mul(change, big.NewRat(100, 1))
+		limit := new(big.Rat).Mul(percent, new(big.Rat).SetInt(before))
+		if change.Cmp(limit) > 0 {
+			return ErrUnexpectedPriceChange
+		}
+	}
+	return nil
+}
+
+// checkBaseline validates prices against the last prices accepted by the client, and makes them the new baseline if
+// they are accepted. The first prices are always accepted, since there is nothing to compare them to.
+func (c *Client) checkBaseline(prices GasPrices) error {
+	if c.maxPriceChange == nil {
+		return nil
+	}
+
+	c.baselineMu.Lock()
+	defer c.baselineMu.Unlock()
+
+	if c.hasBaseline {
+		if err := checkPriceChange(c.baseline, prices, c.maxPriceChange); err != nil {
+			return err
+		}
+	}
+	c.baseline = prices
+	c.hasBaseline = true
+	return nil
+}
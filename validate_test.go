@@ -0,0 +1,181 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPriceChange(t *testing.T) {
+	previous := GasPrices{Fast: big.NewInt(100), SafeLow: big.NewInt(50)}
+	percent := big.NewRat(50, 1)
+
+	// 1. changes within the threshold are accepted, in either direction
+	assert.NoError(t, checkPriceChange(previous, GasPrices{Fast: big.NewInt(150), SafeLow: big.NewInt(25)}, percent))
+
+	// 2. changes beyond the threshold are rejected
+	assert.Equal(t, ErrUnexpectedPriceChange, checkPriceChange(previous, GasPrices{Fast: big.NewInt(151)}, percent))
+	assert.Equal(t, ErrUnexpectedPriceChange, checkPriceChange(previous, GasPrices{SafeLow: big.NewInt(24)}, percent))
+
+	// 3. missing prices are not compared
+	assert.NoError(t, checkPriceChange(GasPrices{}, GasPrices{Fast: big.NewInt(1000)}, percent))
+}
+
+func TestWithMaxPriceChange(t *testing.T) {
+	fast := []int64{100e9, 500e9, 150e9}
+	var calls int
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		price := fast[calls]
+		calls++
+		return GasPrices{Fast: big.NewInt(price)}, nil
+	})
+	c, err := NewClient(WithProvider(provider), WithMaxPriceChange(100), WithMaxResultAge(0))
+	require.NoError(t, err)
+
+	// 1. the first refresh is accepted without a baseline
+	price, err := c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "100000000000", price.String())
+
+	// 2. a spike is rejected and the cached prices are kept
+	time.Sleep(time.Millisecond)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrUnexpectedPriceChange))
+	current, _, ok := c.CachedPrices()
+	require.True(t, ok)
+	assert.Equal(t, "100000000000", current.Fast.String())
+
+	// 3. later refreshes are compared to the last accepted prices
+	time.Sleep(time.Millisecond)
+	price, err = c.SuggestGasPrice(GasPriorityFast)
+	require.NoError(t, err)
+	assert.Equal(t, "150000000000", price.String())
+
+	// 4. invalid thresholds are rejected
+	for _, percent := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		_, err = NewClient(WithMaxPriceChange(percent))
+		assert.Error(t, err)
+	}
+}
+
+func TestValidatePrices(t *testing.T) {
+	// 1. negative prices and fees are rejected from any provider
+	assert.Equal(t, errNegativePrice, validatePrices(GasPrices{Fast: big.NewInt(-1)}, false))
+	assert.Equal(t, errNegativePrice, validatePrices(GasPrices{BaseFee: big.NewInt(-1)}, false))
+	assert.Equal(t, errNegativePrice, validatePrices(GasPrices{
+		Predictions: []PricePrediction{{Price: big.NewInt(-1)}},
+	}, false))
+	assert.Equal(t, errNegativePrice, validatePrices(GasPrices{
+		Fees: map[GasPriority]FeeSuggestion{GasPriorityFast: {MaxPriorityFeePerGas: big.NewInt(-1)}},
+	}, false))
+
+	// 2. zero prices are only rejected if configured
+	zero := GasPrices{Fast: big.NewInt(0), SafeLow: big.NewInt(1)}
+	assert.NoError(t, validatePrices(zero, false))
+	assert.Equal(t, errZeroPrice, validatePrices(zero, true))
+}
+
+func TestWithRejectZeroPrices(t *testing.T) {
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{Fast: big.NewInt(0)}, nil
+	})
+
+	// 1. custom providers are validated too
+	c, err := NewClient(WithProvider(provider), WithRejectZeroPrices())
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.Equal(t, errZeroPrice, err)
+
+	c, err = NewClient(WithProvider(provider))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+}
+
+func TestValidateGasPrices(t *testing.T) {
+	valid := GasPrices{
+		SafeLow: big.NewInt(10e9),
+		Average: big.NewInt(15e9),
+		Fast:    big.NewInt(20e9),
+		Fastest: big.NewInt(20e9),
+	}
+
+	// 1. ordered, positive prices are valid
+	assert.NoError(t, ValidateGasPrices(valid))
+	assert.NoError(t, ValidateGasPrices(GasPrices{}))
+
+	// 2. negative and zero prices are invalid
+	assert.Error(t, ValidateGasPrices(GasPrices{Fast: big.NewInt(-1)}))
+	assert.Error(t, ValidateGasPrices(GasPrices{Fast: big.NewInt(0)}))
+
+	// 3. prices must be ordered by priority, skipping missing prices
+	assert.Error(t, ValidateGasPrices(GasPrices{SafeLow: big.NewInt(20e9), Average: big.NewInt(15e9)}))
+	assert.Error(t, ValidateGasPrices(GasPrices{SafeLow: big.NewInt(20e9), Fastest: big.NewInt(15e9)}))
+	assert.NoError(t, ValidateGasPrices(GasPrices{SafeLow: big.NewInt(10e9), Fastest: big.NewInt(15e9)}))
+}
+
+func TestValidateWaitTimes(t *testing.T) {
+	waits := func(safeLow, average, fast, fastest time.Duration) GasPrices {
+		prices := GasPrices{Waits: map[GasPriority]time.Duration{}}
+		for priority, wait := range map[GasPriority]time.Duration{
+			GasPrioritySafeLow: safeLow, GasPriorityAverage: average, GasPriorityFast: fast, GasPriorityFastest: fastest,
+		} {
+			if wait != 0 {
+				prices.Waits[priority] = wait
+			}
+		}
+		return prices
+	}
+
+	// 1. wait times decreasing or equal from safeLow to fastest are valid, as are missing wait times
+	assert.NoError(t, ValidateWaitTimes(waits(10*time.Minute, 5*time.Minute, 2*time.Minute, 2*time.Minute)))
+	assert.NoError(t, ValidateWaitTimes(waits(10*time.Minute, 0, 0, time.Minute)))
+	assert.NoError(t, ValidateWaitTimes(GasPrices{}))
+
+	// 2. inverted wait times are invalid, skipping missing wait times
+	err := ValidateWaitTimes(waits(2*time.Minute, 5*time.Minute, time.Minute, 30*time.Second))
+	assert.True(t, errors.Is(err, ErrInvertedWaitTimes))
+	assert.Contains(t, err.Error(), "average")
+	assert.Error(t, ValidateWaitTimes(waits(time.Minute, 0, 0, 2*time.Minute)))
+}
+
+func TestWithRejectInvertedWaitTimes(t *testing.T) {
+	var calls int32
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		prices := GasPrices{
+			SafeLow: big.NewInt(10e9),
+			Fast:    big.NewInt(20e9),
+			Waits:   map[GasPriority]time.Duration{GasPrioritySafeLow: time.Minute, GasPriorityFast: 5 * time.Minute},
+		}
+		if calls++; calls > 1 {
+			prices.Waits[GasPriorityFast] = 30 * time.Second
+		}
+		return prices, nil
+	})
+
+	// 1. inverted wait times are only rejected with the option
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+	calls = 0
+	c, err = NewClient(WithProvider(provider), WithRejectInvertedWaitTimes())
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.True(t, errors.Is(err, ErrInvertedWaitTimes))
+
+	// 2. and retried like other invalid prices
+	calls = 0
+	c, err = NewClient(WithProvider(provider), WithRejectInvertedWaitTimes(), WithRetryInvalidPrices(),
+		WithRetry(1, time.Millisecond))
+	require.NoError(t, err)
+	_, err = c.SuggestGasPrice(GasPriorityFast)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls)
+}
@@ -0,0 +1,164 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// GasPriceUpdate is an update sent by a Watcher, either with new prices or with the error of a failed fetch.
+type GasPriceUpdate struct {
+	// Prices are the prices of every priority level, as configured on the client. They are empty if Err is set, and are
+	// a copy that may be modified freely.
+	Prices GasPrices
+
+	// FetchedAt is when the prices were fetched, or when the fetch failed.
+	FetchedAt time.Time
+
+	// Err is the error of a failed fetch.
+	Err error
+}
+
+// Watcher polls the prices of a client on a schedule and sends them on a channel, so request paths read the latest
+// prices without ever waiting for a request. Create one with Client.WatchGasPrices.
+type Watcher struct {
+	client   *Client
+	interval time.Duration
+	updates  chan GasPriceUpdate
+
+	mu     sync.Mutex
+	latest GasPriceUpdate
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// WatchGasPrices starts fetching prices every interval, beginning immediately, until ctx is done, Stop is called or the
+// client is closed. The prices are loaded using the client's configuration, but bypass its cache.
+//
+// Prices are sent on the channel returned by Updates only when they changed since the last update, as compared by
+// WithResultComparator or otherwise by the price, base fee and EIP-1559 fees of every priority level. A failed fetch
+// sends its error instead, and the next successful fetch is sent even if its prices are unchanged, so the receiver can
+// tell when the previous prices became stale and when they are current again.
+func (c *Client) WatchGasPrices(ctx context.Context, interval time.Duration) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, errors.New("eth: watch interval must be positive")
+	}
+	w := &Watcher{
+		client:   c,
+		interval: interval,
+		updates:  make(chan GasPriceUpdate, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run(ctx)
+	return w, nil
+}
+
+// Updates returns the channel on which the watcher sends updates. It is closed once the watcher stops.
+//
+// Sending never blocks the watcher: the channel holds a single update, and an update that was not received yet is
+// replaced by the next one, so a slow receiver only misses updates that are already outdated.
+func (w *Watcher) Updates() <-chan GasPriceUpdate {
+	return w.updates
+}
+
+// Latest returns the most recently fetched prices without blocking, including unchanged prices that were not sent on
+// the channel, and false if no fetch has succeeded yet. The prices are kept when a later fetch fails, check FetchedAt
+// for their age.
+func (w *Watcher) Latest() (GasPriceUpdate, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.latest.FetchedAt.IsZero() {
+		return GasPriceUpdate{}, false
+	}
+	update := w.latest
+	update.Prices = update.Prices.copy()
+	return update, true
+}
+
+// Stop stops fetching prices and waits for an in-flight fetch to return. The latest prices are still returned by
+// Latest. Stop is idempotent.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+func (w *Watcher) run(parent context.Context) {
+	defer close(w.done)
+	defer close(w.updates)
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	closed := w.client.closedChan()
+	go func() {
+		select {
+		case <-w.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	var sent GasPriceUpdate
+	for {
+		update, ok := w.poll(ctx)
+		if !ok {
+			return
+		}
+		equal := samePrices
+		if w.client.resultComparator != nil {
+			equal = w.client.resultComparator
+		}
+		if update.Err != nil || sent.FetchedAt.IsZero() || sent.Err != nil || !equal(sent.Prices, update.Prices) {
+			w.send(update)
+			sent = update
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the prices, and returns false if the watcher is stopped or the client is closed
+func (w *Watcher) poll(ctx context.Context) (GasPriceUpdate, bool) {
+	w.client.emit(Event{Type: EventRefresh})
+	prices, err := w.client.fetch(contextWithBackgroundRefresh(ctx))
+	if ctx.Err() != nil || errors.Is(err, ErrClientClosed) {
+		return GasPriceUpdate{}, false
+	}
+	update := GasPriceUpdate{Prices: prices, FetchedAt: time.Now(), Err: err}
+	if err == nil {
+		w.mu.Lock()
+		w.latest = update
+		w.mu.Unlock()
+	}
+	return update, true
+}
+
+// send sends update on the updates channel, replacing an update that was not received yet
+func (w *Watcher) send(update GasPriceUpdate) {
+	update.Prices = update.Prices.copy()
+	for {
+		select {
+		case w.updates <- update:
+			return
+		default:
+		}
+		select {
+		case <-w.updates:
+		default:
+		}
+	}
+}
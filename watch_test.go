@@ -0,0 +1,100 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchGasPrices(t *testing.T) {
+	fetchErr := errors.New("eth: unavailable")
+	results := make(chan int64)
+	provider := ProviderFunc(func(ctx context.Context) (GasPrices, error) {
+		select {
+		case gwei := <-results:
+			if gwei == 0 {
+				return GasPrices{}, fetchErr
+			}
+			return GasPrices{Fast: big.NewInt(gwei * 1e9)}, nil
+		case <-ctx.Done():
+			return GasPrices{}, ctx.Err()
+		}
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := c.WatchGasPrices(ctx, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	// 1. the first prices are sent immediately
+	results <- 20
+	update := <-w.Updates()
+	require.NoError(t, update.Err)
+	assert.Equal(t, "20000000000", update.Prices.Fast.String())
+	assert.False(t, update.FetchedAt.IsZero())
+
+	// 2. unchanged prices are not sent, a failure is, and so are the prices after it even if unchanged
+	results <- 20
+	results <- 0
+	update = <-w.Updates()
+	assert.Equal(t, fetchErr, update.Err)
+	results <- 20
+	update = <-w.Updates()
+	require.NoError(t, update.Err)
+	assert.Equal(t, "20000000000", update.Prices.Fast.String())
+	results <- 30
+	update = <-w.Updates()
+	assert.Equal(t, "30000000000", update.Prices.Fast.String())
+
+	// 3. the latest prices are returned without blocking
+	latest, ok := w.Latest()
+	require.True(t, ok)
+	assert.Equal(t, "30000000000", latest.Prices.Fast.String())
+
+	// 4. the channel is closed once ctx is done, and the latest prices are kept
+	cancel()
+	for range w.Updates() {
+	}
+	w.Stop()
+	_, ok = w.Latest()
+	assert.True(t, ok)
+
+	// 5. invalid intervals are rejected
+	_, err = c.WatchGasPrices(context.Background(), 0)
+	assert.Error(t, err)
+}
+
+func TestWatcherStop(t *testing.T) {
+	provider := ProviderFunc(func(context.Context) (GasPrices, error) {
+		return GasPrices{}, errors.New("eth: unavailable")
+	})
+	c, err := NewClient(WithProvider(provider))
+	require.NoError(t, err)
+
+	// 1. no prices are returned before a fetch succeeds
+	w, err := c.WatchGasPrices(context.Background(), time.Hour)
+	require.NoError(t, err)
+	assert.Error(t, (<-w.Updates()).Err)
+	_, ok := w.Latest()
+	assert.False(t, ok)
+
+	// 2. stopping closes the channel, and is idempotent
+	w.Stop()
+	w.Stop()
+	_, open := <-w.Updates()
+	assert.False(t, open)
+
+	// 3. closing the client stops the watcher
+	w, err = c.WatchGasPrices(context.Background(), time.Hour)
+	require.NoError(t, err)
+	<-w.Updates()
+	require.NoError(t, c.Close())
+	_, open = <-w.Updates()
+	assert.False(t, open)
+}
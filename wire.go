@@ -0,0 +1,64 @@
+package gas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ToETHGasStationJSON encodes the prices as an ETH Gas Station API response, with prices in tenths of gwei, wait times
+// in tenths of a minute and predictions in minutes, for serving arbitrary prices from a mock or mirror server in the
+// real wire format. Decoding the response with the ETHGasStationProvider returns the same prices, wait times,
+// predictions and block time.
+//
+// Fields that the ETH Gas Station API doesn't report, such as the EIP-1559 fees and confidence, are not encoded. An
+// error is returned if a priority level has no price, or if any price is negative.
+func (p GasPrices) ToETHGasStationJSON() ([]byte, error) {
+	var response ethGasStationResponse
+	levels := []struct {
+		priority GasPriority
+		price    *big.Int
+		raw      *priceNumber
+		wait     *float64
+	}{
+		{GasPriorityFast, p.Fast, &response.Fast, &response.FastWait},
+		{GasPriorityFastest, p.Fastest, &response.Fastest, &response.FastestWait},
+		{GasPrioritySafeLow, p.SafeLow, &response.SafeLow, &response.SafeLowWait},
+		{GasPriorityAverage, p.Average, &response.Average, &response.AvgWait},
+	}
+	for _, level := range levels {
+		if level.price == nil {
+			return nil, fmt.Errorf("eth: no price for priority %q", level.priority)
+		}
+		raw, err := formatTenthsOfGwei(level.price)
+		if err != nil {
+			return nil, err
+		}
+		*level.raw = priceNumber(raw)
+		*level.wait = p.Waits[level.priority].Minutes() * 10
+	}
+
+	if len(p.Predictions) > 0 {
+		response.GasPriceRange = make(map[string]float64, len(p.Predictions))
+		for _, prediction := range p.Predictions {
+			raw, err := formatTenthsOfGwei(prediction.Price)
+			if err != nil {
+				return nil, err
+			}
+			response.GasPriceRange[raw.String()] = prediction.Wait.Minutes()
+		}
+	}
+	response.BlockTime = p.BlockTime.Seconds()
+	return json.Marshal(response)
+}
+
+// formatTenthsOfGwei converts a price in wei to the exact decimal number of tenths of gwei, the inverse of
+// parseScaledDecimalToWei with InputScaleTenthsOfGwei
+func formatTenthsOfGwei(wei *big.Int) (json.Number, error) {
+	if wei == nil || wei.Sign() < 0 {
+		return "", errors.New("eth: gas price must not be nil or negative")
+	}
+	// one wei is 1e-8 tenths of gwei
+	return json.Number(formatDecimal(wei, 8)), nil
+}
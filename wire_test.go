@@ -0,0 +1,65 @@
+package gas
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToETHGasStationJSON(t *testing.T) {
+	prices := GasPrices{
+		Fast:    big.NewInt(20e9),
+		Fastest: big.NewInt(25e9),
+		SafeLow: big.NewInt(10e9),
+		Average: big.NewInt(12050000001),
+		Waits: map[GasPriority]time.Duration{
+			GasPriorityFast:    90 * time.Second,
+			GasPriorityFastest: 30 * time.Second,
+			GasPrioritySafeLow: 30 * time.Minute,
+			GasPriorityAverage: 5 * time.Minute,
+		},
+		Predictions: []PricePrediction{
+			{Price: big.NewInt(10e9), Wait: 30 * time.Minute},
+			{Price: big.NewInt(205e8), Wait: 90 * time.Second},
+		},
+		BlockTime: 13500 * time.Millisecond,
+	}
+
+	// 1. prices are encoded exactly in tenths of gwei, and wait times in tenths of a minute
+	encoded, err := prices.ToETHGasStationJSON()
+	require.NoError(t, err)
+	var response ethGasStationResponse
+	require.NoError(t, json.Unmarshal(encoded, &response))
+	assert.Equal(t, priceNumber("200"), response.Fast)
+	assert.Equal(t, priceNumber("120.50000001"), response.Average)
+	assert.Equal(t, 15.0, response.FastWait)
+	assert.Equal(t, map[string]float64{"100": 30, "205": 1.5}, response.GasPriceRange)
+
+	// 2. decoding the encoded response returns the same prices
+	decoded, err := newGasPrices(response, InputScaleTenthsOfGwei)
+	require.NoError(t, err)
+	decoded.Raw = nil
+	assert.Equal(t, prices, decoded)
+
+	// 3. a client loading the encoded response from a server reports the same prices
+	c, stop := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(encoded)
+	})
+	defer stop()
+	fetched, err := c.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, "12050000001", fetched.Average.String())
+
+	// 4. missing and negative prices are rejected
+	_, err = GasPrices{Fast: big.NewInt(1)}.ToETHGasStationJSON()
+	assert.Error(t, err)
+	negative := prices.copy()
+	negative.Fast = big.NewInt(-1)
+	_, err = negative.ToETHGasStationJSON()
+	assert.Error(t, err)
+}